@@ -5,10 +5,9 @@ import (
 	"fmt"
 	"strconv"
 	"strings"
+	"sync"
 	"time"
 
-	jsoniter "github.com/json-iterator/go"
-
 	"github.com/pkg/errors"
 )
 
@@ -27,6 +26,7 @@ type LogQueueValue struct {
 	Before    map[string]interface{}
 	Changes   map[string]interface{}
 	Updated   time.Time
+	Sink      string
 }
 
 type dirtyQueueValue struct {
@@ -34,10 +34,35 @@ type dirtyQueueValue struct {
 	Streams []string
 }
 
+var redisGarbageCollectorScript = newRedisScript("orm-stream-garbage-collector", `
+local count = 0
+local all = 0
+while(true)
+do
+	local T = redis.call('XRANGE', KEYS[1], "-", ARGV[1], "COUNT", 1000)
+	local ids = {}
+	for _, v in pairs(T) do
+		table.insert(ids, v[1])
+		count = count + 1
+	end
+	if table.getn(ids) > 0 then
+		redis.call('XDEL', KEYS[1], unpack(ids))
+	end
+	if table.getn(ids) < 1000 then
+		all = 1
+		break
+	end
+	if count >= 100000 then
+		break
+	end
+end
+return all
+`)
+
 type BackgroundConsumer struct {
 	eventConsumerBase
 	redisFlusher         *redisFlusher
-	garbageCollectorSha1 string
+	logRetentionLastTick int64
 	consumer             *eventsConsumer
 }
 
@@ -52,7 +77,16 @@ func NewBackgroundConsumer(engine *Engine) *BackgroundConsumer {
 func (r *BackgroundConsumer) Digest(ctx context.Context) bool {
 	r.consumer = r.engine.GetEventBroker().Consumer(asyncConsumerGroupName).(*eventsConsumer)
 	r.consumer.eventConsumerBase = r.eventConsumerBase
-	return r.consumer.Consume(ctx, 100, func(events []Event) {
+	r.handleLogRetention()
+	consumeCtx, cancel := context.WithCancel(ctx)
+	retentionPanic := make(chan interface{}, 1)
+	wg := sync.WaitGroup{}
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		r.logRetentionLoop(consumeCtx, cancel, retentionPanic)
+	}()
+	finished := r.consumer.Consume(consumeCtx, 100, func(events []Event) {
 		for _, event := range events {
 			switch event.Stream() {
 			case lazyChannelName:
@@ -63,9 +97,19 @@ func (r *BackgroundConsumer) Digest(ctx context.Context) bool {
 				r.handleRedisIndexerEvent(event)
 			case redisStreamGarbageCollectorChannelName:
 				r.handleRedisChannelGarbageCollector(event)
+			case cacheWarmUpChannelName:
+				r.handleCacheWarmUp(event)
 			}
 		}
 	})
+	cancel()
+	wg.Wait()
+	select {
+	case rec := <-retentionPanic:
+		panic(rec)
+	default:
+	}
+	return finished
 }
 
 func (r *BackgroundConsumer) handleLogEvent(event Event) {
@@ -76,20 +120,11 @@ func (r *BackgroundConsumer) handleLogEvent(event Event) {
 }
 
 func (r *BackgroundConsumer) handleLog(value *LogQueueValue) {
-	poolDB := r.engine.GetMysql(value.PoolName)
-	/* #nosec */
-	query := "INSERT INTO `" + value.TableName + "`(`entity_id`, `added_at`, `meta`, `before`, `changes`) VALUES(?, ?, ?, ?, ?)"
-	var meta, before, changes interface{}
-	if value.Meta != nil {
-		meta, _ = jsoniter.ConfigFastest.Marshal(value.Meta)
-	}
-	if value.Before != nil {
-		before, _ = jsoniter.ConfigFastest.Marshal(value.Before)
-	}
-	if value.Changes != nil {
-		changes, _ = jsoniter.ConfigFastest.Marshal(value.Changes)
+	if value.Sink == "" {
+		(&mySQLLogSink{pool: value.PoolName}).Write(r.engine, value)
+		return
 	}
-	poolDB.Exec(query, value.ID, value.Updated.Format(timeFormat), meta, before, changes)
+	getLogSink(r.engine, value.Sink).Write(r.engine, value)
 }
 
 func (r *BackgroundConsumer) handleLazy(event Event) {
@@ -142,6 +177,9 @@ func (r *BackgroundConsumer) handleQueries(engine *Engine, validMap map[string]i
 			logEvent.ID, _ = strconv.ParseUint(fmt.Sprintf("%v", asMap["ID"]), 10, 64)
 			logEvent.PoolName = asMap["PoolName"].(string)
 			logEvent.TableName = asMap["TableName"].(string)
+			if asMap["Sink"] != nil {
+				logEvent.Sink = asMap["Sink"].(string)
+			}
 			logEvent.Updated = time.Now()
 			if asMap["Meta"] != nil {
 				logEvent.Meta = r.convertMap(asMap["Meta"].(map[interface{}]interface{}))
@@ -210,6 +248,7 @@ func (r *BackgroundConsumer) handleCache(validMap map[string]interface{}, ids []
 				stringKeys[i] = v.(string)
 			}
 			r.engine.GetLocalCache(cacheCode.(string)).Remove(stringKeys...)
+			r.engine.publishLocalCacheInvalidation(cacheCode.(string), stringKeys...)
 		}
 	}
 }
@@ -324,36 +363,8 @@ func (r *BackgroundConsumer) handleRedisChannelGarbageCollector(event Event) {
 			end = strconv.FormatInt(minID[0], 10) + "-" + strconv.FormatInt(minID[1], 10)
 		}
 
-		if r.garbageCollectorSha1 == "" {
-			script := `
-						local count = 0
-						local all = 0
-						while(true)
-						do
-							local T = redis.call('XRANGE', KEYS[1], "-", ARGV[1], "COUNT", 1000)
-							local ids = {}
-							for _, v in pairs(T) do
-								table.insert(ids, v[1])
-								count = count + 1
-							end
-							if table.getn(ids) > 0 then
-								redis.call('XDEL', KEYS[1], unpack(ids))
-							end
-							if table.getn(ids) < 1000 then
-								all = 1
-								break
-							end
-							if count >= 100000 then
-								break
-							end
-						end
-						return all
-						`
-			r.garbageCollectorSha1 = redisGarbage.ScriptLoad(script)
-		}
-
 		for {
-			res := redisGarbage.EvalSha(r.garbageCollectorSha1, []string{redisGarbage.addNamespacePrefix(stream)}, end)
+			res := redisGarbage.runScript(redisGarbageCollectorScript, []string{redisGarbage.addStreamPrefix(stream)}, end)
 			if res == int64(1) {
 				break
 			}
@@ -0,0 +1,175 @@
+package beeorm
+
+import (
+	"encoding/binary"
+	"fmt"
+
+	"github.com/segmentio/fasthash/fnv1a"
+)
+
+const versionedCacheHeader = byte(3)
+
+const (
+	cacheKindRef byte = iota + 1
+	cacheKindUint
+	cacheKindInt
+	cacheKindBool
+	cacheKindFloat
+	cacheKindTime
+	cacheKindString
+	cacheKindNullUint
+	cacheKindNullInt
+	cacheKindEnum
+	cacheKindBytes
+	cacheKindSet
+	cacheKindNullBool
+	cacheKindNullFloat
+	cacheKindNullTime
+	cacheKindJSON
+	cacheKindRefMany
+)
+
+type cacheLayoutField struct {
+	id   uint32
+	kind byte
+}
+
+func (fields *tableFields) buildCacheLayout() []cacheLayoutField {
+	layout := make([]cacheLayoutField, 0)
+	add := func(ids []int, kind byte) {
+		for _, i := range ids {
+			layout = append(layout, cacheLayoutField{id: fnv1a.HashString32(fields.prefix + fields.fields[i].Name), kind: kind})
+		}
+	}
+	add(fields.refs, cacheKindRef)
+	add(fields.uintegers, cacheKindUint)
+	add(fields.integers, cacheKindInt)
+	add(fields.booleans, cacheKindBool)
+	add(fields.floats, cacheKindFloat)
+	add(fields.times, cacheKindTime)
+	add(fields.dates, cacheKindTime)
+	if fields.fakeDelete > 0 {
+		add([]int{fields.fakeDelete}, cacheKindBool)
+	}
+	add(fields.strings, cacheKindString)
+	add(fields.uintegersNullable, cacheKindNullUint)
+	add(fields.integersNullable, cacheKindNullInt)
+	add(fields.stringsEnums, cacheKindEnum)
+	add(fields.bytes, cacheKindBytes)
+	add(fields.sliceStringsSets, cacheKindSet)
+	add(fields.booleansNullable, cacheKindNullBool)
+	add(fields.floatsNullable, cacheKindNullFloat)
+	add(fields.timesNullable, cacheKindNullTime)
+	add(fields.datesNullable, cacheKindNullTime)
+	add(fields.jsons, cacheKindJSON)
+	add(fields.refsMany, cacheKindRefMany)
+	for _, subFields := range fields.structsFields {
+		layout = append(layout, subFields.buildCacheLayout()...)
+	}
+	return layout
+}
+
+func (tableSchema *tableSchema) encodeRedisValue(binary []byte) []byte {
+	if tableSchema.versionedCache {
+		binary = tableSchema.encodeVersionedCacheValue(binary)
+	}
+	return tableSchema.compressRedisValue(binary)
+}
+
+func (tableSchema *tableSchema) decodeRedisValue(value []byte) []byte {
+	value = tableSchema.decompressRedisValue(value)
+	if len(value) > 0 && value[0] == versionedCacheHeader && value[0] != tableSchema.structureHashPrefix {
+		return tableSchema.decodeVersionedCacheValue(value)
+	}
+	return value
+}
+
+func (tableSchema *tableSchema) encodeVersionedCacheValue(value []byte) []byte {
+	_, pos := binary.Uvarint(value)
+	scratch := make([]byte, binary.MaxVarintLen64)
+	encoded := make([]byte, 0, len(value)+len(tableSchema.cacheLayout)*4)
+	encoded = append(encoded, versionedCacheHeader)
+	encoded = appendUvarint(encoded, scratch, uint64(len(tableSchema.cacheLayout)))
+	for _, field := range tableSchema.cacheLayout {
+		end := skipCacheSegment(value, pos, field.kind)
+		encoded = appendUvarint(encoded, scratch, uint64(field.id))
+		encoded = append(encoded, field.kind)
+		encoded = appendUvarint(encoded, scratch, uint64(end-pos))
+		encoded = append(encoded, value[pos:end]...)
+		pos = end
+	}
+	return encoded
+}
+
+func (tableSchema *tableSchema) decodeVersionedCacheValue(value []byte) []byte {
+	pos := 1
+	count, n := binary.Uvarint(value[pos:])
+	pos += n
+	segments := make(map[uint32][]byte, count)
+	kinds := make(map[uint32]byte, count)
+	for i := uint64(0); i < count; i++ {
+		id, n := binary.Uvarint(value[pos:])
+		pos += n
+		kind := value[pos]
+		pos++
+		length, n := binary.Uvarint(value[pos:])
+		pos += n
+		if pos+int(length) > len(value) {
+			panic(fmt.Errorf("%s entity cache data is corrupted", tableSchema.t.String()))
+		}
+		segments[uint32(id)] = value[pos : pos+int(length)]
+		kinds[uint32(id)] = kind
+		pos += int(length)
+	}
+	scratch := make([]byte, binary.MaxVarintLen64)
+	decoded := make([]byte, 0, len(value))
+	decoded = appendUvarint(decoded, scratch, tableSchema.structureHash)
+	for _, field := range tableSchema.cacheLayout {
+		segment, has := segments[field.id]
+		if has && kinds[field.id] == field.kind {
+			decoded = append(decoded, segment...)
+		} else {
+			decoded = append(decoded, 0)
+		}
+	}
+	return decoded
+}
+
+func appendUvarint(target, scratch []byte, value uint64) []byte {
+	n := binary.PutUvarint(scratch, value)
+	return append(target, scratch[:n]...)
+}
+
+func skipUvarint(value []byte, pos int) int {
+	_, n := binary.Uvarint(value[pos:])
+	return pos + n
+}
+
+func skipCacheSegment(value []byte, pos int, kind byte) int {
+	switch kind {
+	case cacheKindBool:
+		return pos + 1
+	case cacheKindString, cacheKindBytes, cacheKindJSON:
+		length, n := binary.Uvarint(value[pos:])
+		return pos + n + int(length)
+	case cacheKindNullUint, cacheKindNullInt, cacheKindNullFloat, cacheKindNullTime:
+		if value[pos] == 0 {
+			return pos + 1
+		}
+		return skipUvarint(value, pos+1)
+	case cacheKindNullBool:
+		if value[pos] == 0 {
+			return pos + 1
+		}
+		return pos + 2
+	case cacheKindSet, cacheKindRefMany:
+		count, n := binary.Uvarint(value[pos:])
+		pos += n
+		for i := uint64(0); i < count; i++ {
+			pos = skipUvarint(value, pos)
+		}
+		return pos
+	default:
+		return skipUvarint(value, pos)
+	}
+}
@@ -0,0 +1,67 @@
+package beeorm
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type cacheFormatEntity struct {
+	ORM      `orm:"redisCache;versionedCache"`
+	ID       uint
+	Name     string
+	Age      uint8
+	Nickname *string
+	Tags     []string `orm:"set=beeorm.TestEnum"`
+}
+
+type cacheFormatEntityNew struct {
+	ORM   `orm:"redisCache;versionedCache;table=cacheFormatEntity"`
+	ID    uint
+	Name  string
+	Email string
+	Tags  []string `orm:"set=beeorm.TestEnum"`
+}
+
+func TestVersionedCacheFormat(t *testing.T) {
+	var entity *cacheFormatEntity
+	registry := &Registry{}
+	registry.RegisterEnumStruct("beeorm.TestEnum", TestEnum)
+	engine, def := prepareTables(t, registry, 5, "", "2.0", entity)
+	defer def()
+
+	nickname := "Johnny"
+	engine.Flush(&cacheFormatEntity{Name: "John", Age: 18, Nickname: &nickname, Tags: []string{"a", "c"}})
+	entity = &cacheFormatEntity{}
+	assert.True(t, engine.LoadByID(1, entity))
+	schema := engine.GetRegistry().GetTableSchemaForEntity(entity).(*tableSchema)
+	raw, has := engine.GetRedis().Get(schema.getCacheKey(1))
+	assert.True(t, has)
+	assert.Equal(t, versionedCacheHeader, raw[0])
+
+	entity = &cacheFormatEntity{}
+	assert.True(t, engine.LoadByID(1, entity))
+	assert.Equal(t, "John", entity.Name)
+	assert.Equal(t, uint8(18), entity.Age)
+	assert.Equal(t, "Johnny", *entity.Nickname)
+	assert.Equal(t, []string{"a", "c"}, entity.Tags)
+
+	registry = &Registry{}
+	registry.RegisterMySQLPool("root:root@tcp(localhost:3311)/test")
+	registry.RegisterRedis("localhost:6382", "", 15)
+	registry.RegisterEnumStruct("beeorm.TestEnum", TestEnum)
+	registry.RegisterEntity(&cacheFormatEntityNew{})
+	vRegistry, def2, err := registry.Validate()
+	assert.NoError(t, err)
+	defer def2()
+	newEntity := &cacheFormatEntityNew{}
+	newSchema := vRegistry.GetTableSchemaForEntity(newEntity).(*tableSchema)
+	assert.Equal(t, schema.getCacheKey(1), newSchema.getCacheKey(1))
+	assert.NotEqual(t, schema.structureHash, newSchema.structureHash)
+
+	fillFromBinary(newSerializer(nil), vRegistry.(*validatedRegistry), newSchema.decodeRedisValue([]byte(raw)), newEntity)
+	assert.Equal(t, uint(1), newEntity.ID)
+	assert.Equal(t, "John", newEntity.Name)
+	assert.Equal(t, "", newEntity.Email)
+	assert.Equal(t, []string{"a", "c"}, newEntity.Tags)
+}
@@ -0,0 +1,188 @@
+package beeorm
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	"sort"
+	"sync/atomic"
+)
+
+type entityCacheCounters struct {
+	localHits     uint64
+	localMisses   uint64
+	requestHits   uint64
+	requestMisses uint64
+	redisHits     uint64
+	redisMisses   uint64
+	searchHits    uint64
+	searchMisses  uint64
+	dbLoads       uint64
+}
+
+func (c *entityCacheCounters) local(cache *LocalCache, hits, misses int) {
+	if cache.config.code == requestCacheKey {
+		atomic.AddUint64(&c.requestHits, uint64(hits))
+		atomic.AddUint64(&c.requestMisses, uint64(misses))
+		return
+	}
+	atomic.AddUint64(&c.localHits, uint64(hits))
+	atomic.AddUint64(&c.localMisses, uint64(misses))
+}
+
+func (c *entityCacheCounters) localLookup(cache *LocalCache, hit bool) {
+	if hit {
+		c.local(cache, 1, 0)
+	} else {
+		c.local(cache, 0, 1)
+	}
+}
+
+func (c *entityCacheCounters) redisLookup(hit bool) {
+	if hit {
+		c.redis(1, 0)
+	} else {
+		c.redis(0, 1)
+	}
+}
+
+func (c *entityCacheCounters) redis(hits, misses int) {
+	atomic.AddUint64(&c.redisHits, uint64(hits))
+	atomic.AddUint64(&c.redisMisses, uint64(misses))
+}
+
+func (c *entityCacheCounters) search(hits, misses int) {
+	atomic.AddUint64(&c.searchHits, uint64(hits))
+	atomic.AddUint64(&c.searchMisses, uint64(misses))
+}
+
+func (c *entityCacheCounters) db(loads int) {
+	atomic.AddUint64(&c.dbLoads, uint64(loads))
+}
+
+type EntityCacheStats struct {
+	LocalCachePool     string
+	RedisCachePool     string
+	LocalHits          uint64
+	LocalMisses        uint64
+	RequestCacheHits   uint64
+	RequestCacheMisses uint64
+	RedisHits          uint64
+	RedisMisses        uint64
+	SearchHits         uint64
+	SearchMisses       uint64
+	DBLoads            uint64
+}
+
+type CacheStats struct {
+	Entities   map[string]*EntityCacheStats
+	LocalPools map[string]LocalCacheStats
+}
+
+func (e *Engine) GetCacheStats() *CacheStats {
+	stats := &CacheStats{Entities: make(map[string]*EntityCacheStats), LocalPools: make(map[string]LocalCacheStats)}
+	for _, schema := range e.registry.tableSchemas {
+		c := schema.cacheCounters
+		stats.Entities[schema.t.String()] = &EntityCacheStats{
+			LocalCachePool:     schema.localCacheName,
+			RedisCachePool:     schema.redisCacheName,
+			LocalHits:          atomic.LoadUint64(&c.localHits),
+			LocalMisses:        atomic.LoadUint64(&c.localMisses),
+			RequestCacheHits:   atomic.LoadUint64(&c.requestHits),
+			RequestCacheMisses: atomic.LoadUint64(&c.requestMisses),
+			RedisHits:          atomic.LoadUint64(&c.redisHits),
+			RedisMisses:        atomic.LoadUint64(&c.redisMisses),
+			SearchHits:         atomic.LoadUint64(&c.searchHits),
+			SearchMisses:       atomic.LoadUint64(&c.searchMisses),
+			DBLoads:            atomic.LoadUint64(&c.dbLoads),
+		}
+	}
+	for code := range e.registry.localCacheServers {
+		stats.LocalPools[code] = e.GetLocalCache(code).GetStats()
+	}
+	return stats
+}
+
+func (s *CacheStats) WritePrometheus(w io.Writer) error {
+	entities := make([]string, 0, len(s.Entities))
+	for name := range s.Entities {
+		entities = append(entities, name)
+	}
+	sort.Strings(entities)
+	metrics := []struct {
+		layer  string
+		result string
+		value  func(stats *EntityCacheStats) uint64
+	}{
+		{"local", "hit", func(s *EntityCacheStats) uint64 { return s.LocalHits }},
+		{"local", "miss", func(s *EntityCacheStats) uint64 { return s.LocalMisses }},
+		{"request", "hit", func(s *EntityCacheStats) uint64 { return s.RequestCacheHits }},
+		{"request", "miss", func(s *EntityCacheStats) uint64 { return s.RequestCacheMisses }},
+		{"redis", "hit", func(s *EntityCacheStats) uint64 { return s.RedisHits }},
+		{"redis", "miss", func(s *EntityCacheStats) uint64 { return s.RedisMisses }},
+		{"search", "hit", func(s *EntityCacheStats) uint64 { return s.SearchHits }},
+		{"search", "miss", func(s *EntityCacheStats) uint64 { return s.SearchMisses }},
+	}
+	_, err := io.WriteString(w, "# HELP beeorm_entity_cache_requests_total Entity cache lookups by layer and result.\n"+
+		"# TYPE beeorm_entity_cache_requests_total counter\n")
+	if err != nil {
+		return err
+	}
+	for _, entity := range entities {
+		for _, metric := range metrics {
+			_, err = fmt.Fprintf(w, "beeorm_entity_cache_requests_total{entity=%q,layer=%q,result=%q} %d\n", entity, metric.layer, metric.result,
+				metric.value(s.Entities[entity]))
+			if err != nil {
+				return err
+			}
+		}
+	}
+	_, err = io.WriteString(w, "# HELP beeorm_entity_db_loads_total Entities loaded from MySQL after cache misses.\n"+
+		"# TYPE beeorm_entity_db_loads_total counter\n")
+	if err != nil {
+		return err
+	}
+	for _, entity := range entities {
+		_, err = fmt.Fprintf(w, "beeorm_entity_db_loads_total{entity=%q} %d\n", entity, s.Entities[entity].DBLoads)
+		if err != nil {
+			return err
+		}
+	}
+	pools := make([]string, 0, len(s.LocalPools))
+	for code := range s.LocalPools {
+		pools = append(pools, code)
+	}
+	sort.Strings(pools)
+	poolMetrics := []struct {
+		name       string
+		metricType string
+		help       string
+		value      func(stats LocalCacheStats) int64
+	}{
+		{"beeorm_local_cache_hits_total", "counter", "Local cache pool hits.", func(s LocalCacheStats) int64 { return int64(s.Hits) }},
+		{"beeorm_local_cache_misses_total", "counter", "Local cache pool misses.", func(s LocalCacheStats) int64 { return int64(s.Misses) }},
+		{"beeorm_local_cache_evictions_total", "counter", "Local cache pool evictions.", func(s LocalCacheStats) int64 { return int64(s.Evictions) }},
+		{"beeorm_local_cache_entries", "gauge", "Local cache pool entries.", func(s LocalCacheStats) int64 { return int64(s.Entries) }},
+		{"beeorm_local_cache_bytes", "gauge", "Local cache pool approximate size in bytes.", func(s LocalCacheStats) int64 { return s.Bytes }},
+	}
+	for _, metric := range poolMetrics {
+		_, err = fmt.Fprintf(w, "# HELP %s %s\n# TYPE %s %s\n", metric.name, metric.help, metric.name, metric.metricType)
+		if err != nil {
+			return err
+		}
+		for _, code := range pools {
+			_, err = fmt.Fprintf(w, "%s{pool=%q} %d\n", metric.name, code, metric.value(s.LocalPools[code]))
+			if err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+func NewCacheStatsHandler(registry ValidatedRegistry) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.Header().Set("Content-Type", "text/plain; version=0.0.4")
+		_ = registry.CreateEngine().GetCacheStats().WritePrometheus(w)
+	})
+}
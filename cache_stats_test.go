@@ -0,0 +1,60 @@
+package beeorm
+
+import (
+	"bytes"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type cacheStatsEntity struct {
+	ORM       `orm:"localCache;redisCache"`
+	ID        uint
+	Name      string       `orm:"index=NameIndex"`
+	IndexName *CachedQuery `query:":Name = ?"`
+}
+
+func TestCacheStats(t *testing.T) {
+	var entity *cacheStatsEntity
+	engine, def := prepareTables(t, &Registry{}, 5, "", "2.0", entity)
+	defer def()
+
+	engine.FlushMany(&cacheStatsEntity{Name: "a"}, &cacheStatsEntity{Name: "b"})
+	engine.GetLocalCache().Clear()
+	engine.GetRedis().FlushDB()
+	stats := engine.GetCacheStats().Entities["beeorm.cacheStatsEntity"]
+	assert.NotNil(t, stats)
+	assert.Equal(t, "default", stats.LocalCachePool)
+	assert.Equal(t, "default", stats.RedisCachePool)
+	localMisses := stats.LocalMisses
+	dbLoads := stats.DBLoads
+
+	entity = &cacheStatsEntity{}
+	engine.LoadByID(1, entity)
+	engine.LoadByID(1, entity)
+	engine.GetLocalCache().Clear()
+	engine.LoadByID(1, entity)
+	var rows []*cacheStatsEntity
+	engine.LoadByIDs([]uint64{1, 2}, &rows)
+	engine.CachedSearch(&rows, "IndexName", nil, "a")
+	engine.CachedSearch(&rows, "IndexName", nil, "a")
+
+	stats = engine.GetCacheStats().Entities["beeorm.cacheStatsEntity"]
+	assert.Equal(t, localMisses+3, stats.LocalMisses)
+	assert.Equal(t, uint64(4), stats.LocalHits)
+	assert.Equal(t, uint64(1), stats.RedisHits)
+	assert.Equal(t, uint64(2), stats.RedisMisses)
+	assert.Equal(t, dbLoads+2, stats.DBLoads)
+	assert.Equal(t, uint64(1), stats.SearchHits)
+	assert.Equal(t, uint64(1), stats.SearchMisses)
+
+	buffer := &bytes.Buffer{}
+	assert.Nil(t, engine.GetCacheStats().WritePrometheus(buffer))
+	assert.Contains(t, buffer.String(), `beeorm_entity_cache_requests_total{entity="beeorm.cacheStatsEntity",layer="redis",result="hit"} 1`)
+	assert.Contains(t, buffer.String(), `beeorm_local_cache_entries{pool="default"}`)
+
+	recorder := httptest.NewRecorder()
+	NewCacheStatsHandler(engine.GetRegistry()).ServeHTTP(recorder, httptest.NewRequest("GET", "/metrics", nil))
+	assert.Contains(t, recorder.Body.String(), "beeorm_entity_db_loads_total{entity=\"beeorm.cacheStatsEntity\"}")
+}
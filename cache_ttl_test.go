@@ -0,0 +1,70 @@
+package beeorm
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type cacheTTLEntity struct {
+	ORM       `orm:"localCache;redisCache;localTTL=1;redisTTL=30"`
+	ID        uint
+	Name      string       `orm:"index=NameIndex"`
+	IndexName *CachedQuery `query:":Name = ?"`
+}
+
+type cacheTTLInvalidEntity struct {
+	ORM `orm:"localCache;localTTL=abc"`
+	ID  uint
+}
+
+func TestCacheTTL(t *testing.T) {
+	var entity *cacheTTLEntity
+	engine, def := prepareTables(t, &Registry{}, 5, "", "2.0", entity)
+	defer def()
+	schema := engine.GetRegistry().GetTableSchemaForEntity(entity).(*tableSchema)
+	assert.Equal(t, time.Second, schema.localCacheTTL)
+	assert.Equal(t, 30, schema.redisCacheTTL)
+
+	engine.FlushMany(&cacheTTLEntity{Name: "a"}, &cacheTTLEntity{Name: "b"})
+	engine.GetLocalCache().Clear()
+	engine.GetRedis().FlushDB()
+
+	entity = &cacheTTLEntity{}
+	assert.True(t, engine.LoadByID(1, entity))
+	assert.False(t, engine.LoadByID(10, &cacheTTLEntity{}))
+	redisClient := engine.GetRedis().client
+	ttl := redisClient.TTL(context.Background(), schema.getCacheKey(1)).Val()
+	assert.True(t, ttl > 0 && ttl <= 30*time.Second)
+	ttl = redisClient.TTL(context.Background(), schema.getCacheKey(10)).Val()
+	assert.True(t, ttl > 0 && ttl <= 30*time.Second)
+
+	var rows []*cacheTTLEntity
+	engine.LoadByIDs([]uint64{2}, &rows)
+	ttl = redisClient.TTL(context.Background(), schema.getCacheKey(2)).Val()
+	assert.True(t, ttl > 0 && ttl <= 30*time.Second)
+
+	assert.Equal(t, 1, engine.CachedSearch(&rows, "IndexName", nil, "a"))
+	cacheKey := getCacheKeySearch(schema, "IndexName", "a")
+	ttl = redisClient.TTL(context.Background(), cacheKey).Val()
+	assert.True(t, ttl > 0 && ttl <= 30*time.Second)
+
+	_, has := engine.GetLocalCache().Get(schema.getCacheKey(1))
+	assert.True(t, has)
+	_, has = engine.GetLocalCache().Get(cacheKey)
+	assert.True(t, has)
+	time.Sleep(time.Millisecond * 1100)
+	_, has = engine.GetLocalCache().Get(schema.getCacheKey(1))
+	assert.False(t, has)
+	_, has = engine.GetLocalCache().Get(cacheKey)
+	assert.False(t, has)
+
+	registry := &Registry{}
+	registry.RegisterMySQLPool("root:root@tcp(localhost:3311)/test")
+	registry.RegisterLocalCache(100)
+	registry.RegisterEntity(&cacheTTLInvalidEntity{})
+	_, _, err := registry.Validate()
+	assert.EqualError(t, err, "invalid localTTL 'abc' in beeorm.cacheTTLInvalidEntity")
+}
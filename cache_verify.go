@@ -0,0 +1,383 @@
+package beeorm
+
+import (
+	"encoding/binary"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	CacheLayerLocal       = "local"
+	CacheLayerRedis       = "redis"
+	CacheLayerRedisSearch = "redis_search"
+)
+
+const (
+	CacheDifferenceField             = "field"
+	CacheDifferenceMissingInMySQL    = "missing_in_mysql"
+	CacheDifferenceMissingInCache    = "missing_in_cache"
+	CacheDifferenceOutdatedStructure = "outdated_structure"
+)
+
+const cacheVerifyBatchSize = 1000
+
+type CacheVerifyOptions struct {
+	IDs    []uint64
+	FromID uint64
+	ToID   uint64
+	Repair bool
+}
+
+type CacheDifference struct {
+	ID     uint64
+	Layer  string
+	Reason string
+	Field  string
+	MySQL  interface{}
+	Cache  interface{}
+}
+
+type CacheVerifyReport struct {
+	Checked     int
+	Repaired    int
+	Differences []CacheDifference
+}
+
+func (e *Engine) VerifyCache(entity Entity, options *CacheVerifyOptions) *CacheVerifyReport {
+	schema := initIfNeeded(e.registry, entity).tableSchema
+	if options == nil {
+		options = &CacheVerifyOptions{}
+	}
+	if !schema.hasLocalCache && !schema.hasRedisCache && !schema.hasSearchCache {
+		panic(fmt.Errorf("cache verification not allowed for entity without cache: '%s'", schema.t.String()))
+	}
+	verifier := &cacheVerifier{engine: e, schema: schema, serializer: newSerializer(nil), repair: options.Repair,
+		report: &CacheVerifyReport{Differences: make([]CacheDifference, 0)}}
+	if len(options.IDs) > 0 {
+		for i := 0; i < len(options.IDs); i += cacheVerifyBatchSize {
+			end := i + cacheVerifyBatchSize
+			if end > len(options.IDs) {
+				end = len(options.IDs)
+			}
+			ids := options.IDs[i:end]
+			verifier.verify(ids, verifier.loadFromDB("`ID` IN ("+joinIDs(ids)+")"))
+		}
+		return verifier.report
+	}
+	lastID := options.FromID
+	if lastID > 0 {
+		lastID--
+	}
+	for {
+		where := "`ID` > " + strconv.FormatUint(lastID, 10)
+		if options.ToID > 0 {
+			where += " AND `ID` <= " + strconv.FormatUint(options.ToID, 10)
+		}
+		rows := verifier.loadFromDB(where + " ORDER BY `ID` LIMIT " + strconv.Itoa(cacheVerifyBatchSize))
+		ids := make([]uint64, 0, len(rows))
+		for id := range rows {
+			ids = append(ids, id)
+		}
+		sort.Slice(ids, func(i, j int) bool {
+			return ids[i] < ids[j]
+		})
+		verifier.verify(ids, rows)
+		if len(ids) < cacheVerifyBatchSize {
+			break
+		}
+		lastID = ids[len(ids)-1]
+	}
+	// rows deleted in MySQL can be found only in cache keys
+	cachedIDs := verifier.cachedIDs(options.FromID, options.ToID)
+	for i := 0; i < len(cachedIDs); i += cacheVerifyBatchSize {
+		end := i + cacheVerifyBatchSize
+		if end > len(cachedIDs) {
+			end = len(cachedIDs)
+		}
+		rows := verifier.loadFromDB("`ID` IN (" + joinIDs(cachedIDs[i:end]) + ")")
+		missing := make([]uint64, 0)
+		for _, id := range cachedIDs[i:end] {
+			if rows[id] == nil {
+				missing = append(missing, id)
+			}
+		}
+		verifier.verify(missing, rows)
+	}
+	return verifier.report
+}
+
+type cacheVerifier struct {
+	engine     *Engine
+	schema     *tableSchema
+	serializer *serializer
+	repair     bool
+	report     *CacheVerifyReport
+}
+
+func (v *cacheVerifier) loadFromDB(where string) map[uint64]Entity {
+	rows := make(map[uint64]Entity)
+	results, def := v.schema.GetMysql(v.engine).Query("SELECT " + v.schema.fieldsQuery + " FROM `" + v.schema.tableName + "` WHERE " + where)
+	defer def()
+	for results.Next() {
+		pointers := prepareScan(v.schema)
+		results.Scan(pointers...)
+		id := *pointers[v.schema.idIndex].(*uint64)
+		e := v.schema.NewEntity()
+		fillFromDBRow(v.serializer, id, v.engine.registry, pointers, e)
+		rows[id] = e
+	}
+	def()
+	return rows
+}
+
+func (v *cacheVerifier) cachedIDs(fromID, toID uint64) []uint64 {
+	found := make(map[uint64]bool)
+	add := func(keys []string, prefix string) {
+		for _, key := range keys {
+			id, err := strconv.ParseUint(strings.TrimPrefix(key, prefix), 10, 64)
+			if err == nil && id >= fromID && (toID == 0 || id <= toID) {
+				found[id] = true
+			}
+		}
+	}
+	cachePrefix := v.schema.cachePrefix + ":"
+	if localCache, has := v.schema.GetLocalCache(v.engine); has {
+		add(localCache.config.keys(cachePrefix), cachePrefix)
+	}
+	if redisCache, has := v.schema.GetRedisCache(v.engine); has {
+		redisCache.scanKeys(cachePrefix+"*", func(keys []string) {
+			add(keys, cachePrefix)
+		})
+	}
+	if v.schema.hasSearchCache {
+		v.engine.GetRedis(v.schema.searchCacheName).scanKeys(v.schema.redisSearchPrefix+"*", func(keys []string) {
+			add(keys, v.schema.redisSearchPrefix)
+		})
+	}
+	ids := make([]uint64, 0, len(found))
+	for id := range found {
+		ids = append(ids, id)
+	}
+	sort.Slice(ids, func(i, j int) bool {
+		return ids[i] < ids[j]
+	})
+	return ids
+}
+
+func (v *cacheVerifier) verify(ids []uint64, rows map[uint64]Entity) {
+	v.report.Checked += len(ids)
+	repaired := make(map[uint64]bool)
+	var invalidations localCacheInvalidations
+	if localCache, has := v.schema.GetLocalCache(v.engine); has {
+		invalid := make([]string, 0)
+		for _, id := range ids {
+			cacheKey := v.schema.getCacheKey(id)
+			value, has := localCache.Get(cacheKey)
+			if !has {
+				continue
+			}
+			var differences []CacheDifference
+			if value == cacheNilValue {
+				differences = v.compareNil(id, CacheLayerLocal, rows[id])
+			} else {
+				differences = v.compareBinary(id, CacheLayerLocal, rows[id], value.([]byte))
+			}
+			if len(differences) > 0 {
+				v.report.Differences = append(v.report.Differences, differences...)
+				invalid = append(invalid, cacheKey)
+				repaired[id] = true
+			}
+		}
+		if v.repair && len(invalid) > 0 {
+			localCache.Remove(invalid...)
+			invalidations.add(localCache.config.GetCode(), invalid...)
+		}
+	}
+	if redisCache, has := v.schema.GetRedisCache(v.engine); has && len(ids) > 0 {
+		keys := make([]string, len(ids))
+		for i, id := range ids {
+			keys[i] = v.schema.getCacheKey(id)
+		}
+		toSet := make([]interface{}, 0)
+		toDelete := make([]string, 0)
+		for i, value := range redisCache.MGet(keys...) {
+			if value == nil {
+				continue
+			}
+			id := ids[i]
+			row := rows[id]
+			var differences []CacheDifference
+			if value == cacheNilValue {
+				differences = v.compareNil(id, CacheLayerRedis, row)
+			} else {
+				differences = v.compareBinary(id, CacheLayerRedis, row, v.schema.decodeRedisValue([]byte(value.(string))))
+			}
+			if len(differences) == 0 {
+				continue
+			}
+			v.report.Differences = append(v.report.Differences, differences...)
+			repaired[id] = true
+			if row != nil {
+				toSet = append(toSet, keys[i], v.schema.encodeRedisValue(row.getORM().binary))
+			} else {
+				toDelete = append(toDelete, keys[i])
+			}
+		}
+		if v.repair && len(toSet) > 0 {
+			redisCache.mSetWithTTL(v.schema.redisCacheTTL, toSet...)
+		}
+		if v.repair && len(toDelete) > 0 {
+			redisCache.Del(toDelete...)
+		}
+	}
+	v.engine.publishLocalCacheInvalidations(invalidations)
+	if v.schema.hasSearchCache {
+		redisSearch := v.engine.GetRedis(v.schema.searchCacheName)
+		for _, id := range ids {
+			key := v.schema.redisSearchPrefix + strconv.FormatUint(id, 10)
+			expected := v.expectedSearchDocument(id, rows[id])
+			differences := v.compareSearchDocument(id, expected, redisSearch.HGetAll(key))
+			if len(differences) == 0 {
+				continue
+			}
+			v.report.Differences = append(v.report.Differences, differences...)
+			repaired[id] = true
+			if v.repair {
+				redisSearch.Del(key)
+				if expected != nil {
+					values := make([]interface{}, 0, len(expected)*2)
+					for field, value := range expected {
+						values = append(values, field, value)
+					}
+					redisSearch.HSet(key, values...)
+				}
+			}
+		}
+	}
+	if v.repair {
+		v.report.Repaired += len(repaired)
+	}
+}
+
+func (v *cacheVerifier) compareNil(id uint64, layer string, row Entity) []CacheDifference {
+	if row == nil {
+		return nil
+	}
+	return []CacheDifference{{ID: id, Layer: layer, Reason: CacheDifferenceMissingInCache}}
+}
+
+func (v *cacheVerifier) compareBinary(id uint64, layer string, row Entity, cached []byte) []CacheDifference {
+	if row == nil {
+		return []CacheDifference{{ID: id, Layer: layer, Reason: CacheDifferenceMissingInMySQL}}
+	}
+	hash, _ := binary.Uvarint(cached)
+	if hash != v.schema.structureHash {
+		return []CacheDifference{{ID: id, Layer: layer, Reason: CacheDifferenceOutdatedStructure}}
+	}
+	fromDB, fromCache := v.buildBinds(row, cached)
+	differences := make([]CacheDifference, 0, len(fromDB))
+	for field, value := range fromDB {
+		differences = append(differences, CacheDifference{ID: id, Layer: layer, Reason: CacheDifferenceField, Field: field,
+			MySQL: value, Cache: fromCache[field]})
+	}
+	sort.Slice(differences, func(i, j int) bool {
+		return differences[i].Field < differences[j].Field
+	})
+	return differences
+}
+
+func (v *cacheVerifier) buildBinds(row Entity, cached []byte) (fromDB, fromCache Bind) {
+	orm := row.getORM()
+	v.serializer.Reset(cached)
+	bindBuilder := newBindBuilder(orm.GetID(), orm)
+	bindBuilder.hasCurrent = true
+	bindBuilder.current = Bind{}
+	bindBuilder.build(v.serializer, v.schema.fields, orm.elem, true)
+	return bindBuilder.bind, bindBuilder.current
+}
+
+func (v *cacheVerifier) expectedSearchDocument(id uint64, row Entity) map[string]interface{} {
+	if row == nil {
+		return nil
+	}
+	_, current := v.buildBinds(row, row.getORM().binary)
+	if v.schema.hasFakeDelete && !v.schema.hasSearchableFakeDelete && v.schema.isFakeDeletedValue(current[v.schema.fakeDeleteColumn]) {
+		return nil
+	}
+	document := make(map[string]interface{})
+	for field, mapper := range v.schema.mapBindToRedisSearch {
+		if field == "ID" {
+			document[field] = mapper(id)
+			continue
+		}
+		value, has := current[field]
+		if has {
+			document[field] = mapper(value)
+		}
+	}
+	return document
+}
+
+func (v *cacheVerifier) compareSearchDocument(id uint64, expected map[string]interface{}, document map[string]string) []CacheDifference {
+	if expected == nil {
+		if len(document) > 0 {
+			return []CacheDifference{{ID: id, Layer: CacheLayerRedisSearch, Reason: CacheDifferenceMissingInMySQL}}
+		}
+		return nil
+	}
+	if len(document) == 0 {
+		return []CacheDifference{{ID: id, Layer: CacheLayerRedisSearch, Reason: CacheDifferenceMissingInCache}}
+	}
+	differences := make([]CacheDifference, 0)
+	for field, value := range expected {
+		formatted := formatRedisSearchValue(value)
+		inCache, has := document[field]
+		if has && inCache == formatted {
+			continue
+		}
+		difference := CacheDifference{ID: id, Layer: CacheLayerRedisSearch, Reason: CacheDifferenceField, Field: field, MySQL: formatted}
+		if has {
+			difference.Cache = inCache
+		}
+		differences = append(differences, difference)
+	}
+	sort.Slice(differences, func(i, j int) bool {
+		return differences[i].Field < differences[j].Field
+	})
+	return differences
+}
+
+func formatRedisSearchValue(value interface{}) string {
+	switch v := value.(type) {
+	case nil:
+		return ""
+	case string:
+		return v
+	case []byte:
+		return string(v)
+	case bool:
+		if v {
+			return "1"
+		}
+		return "0"
+	case float32:
+		return strconv.FormatFloat(float64(v), 'f', -1, 32)
+	case float64:
+		return strconv.FormatFloat(v, 'f', -1, 64)
+	case time.Time:
+		return v.Format(time.RFC3339Nano)
+	default:
+		return fmt.Sprint(v)
+	}
+}
+
+func joinIDs(ids []uint64) string {
+	values := make([]string, len(ids))
+	for i, id := range ids {
+		values[i] = strconv.FormatUint(id, 10)
+	}
+	return strings.Join(values, ",")
+}
@@ -0,0 +1,98 @@
+package beeorm
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type cacheVerifyEntity struct {
+	ORM  `orm:"localCache;redisCache"`
+	ID   uint
+	Name string
+	Age  uint8
+}
+
+type cacheVerifySearchEntity struct {
+	ORM  `orm:"redisSearch=search"`
+	ID   uint   `orm:"searchable;sortable"`
+	Name string `orm:"searchable"`
+	Age  uint8  `orm:"searchable;sortable"`
+}
+
+func TestVerifyCache(t *testing.T) {
+	var entity *cacheVerifyEntity
+	registry := &Registry{}
+	engine, def := prepareTables(t, registry, 5, "", "2.0", entity)
+	defer def()
+
+	engine.FlushMany(&cacheVerifyEntity{Name: "a", Age: 10}, &cacheVerifyEntity{Name: "b", Age: 20}, &cacheVerifyEntity{Name: "c", Age: 30})
+	engine.GetLocalCache().Clear()
+	var rows []*cacheVerifyEntity
+	assert.True(t, engine.LoadByIDs([]uint64{1, 2, 3}, &rows))
+
+	report := engine.VerifyCache(&cacheVerifyEntity{}, &CacheVerifyOptions{FromID: 1})
+	assert.Equal(t, 3, report.Checked)
+	assert.Len(t, report.Differences, 0)
+
+	engine.GetMysql().Exec("UPDATE `cacheVerifyEntity` SET `Name` = 'fixed' WHERE `ID` = 1")
+	engine.GetMysql().Exec("DELETE FROM `cacheVerifyEntity` WHERE `ID` = 3")
+	report = engine.VerifyCache(&cacheVerifyEntity{}, &CacheVerifyOptions{IDs: []uint64{1, 2, 3}})
+	assert.Equal(t, 3, report.Checked)
+	assert.Equal(t, 0, report.Repaired)
+	assert.Len(t, report.Differences, 4)
+	assert.Equal(t, CacheDifference{ID: 1, Layer: CacheLayerLocal, Reason: CacheDifferenceField, Field: "Name", MySQL: "fixed", Cache: "a"}, report.Differences[0])
+	assert.Equal(t, CacheDifference{ID: 3, Layer: CacheLayerLocal, Reason: CacheDifferenceMissingInMySQL}, report.Differences[1])
+	assert.Equal(t, CacheDifference{ID: 1, Layer: CacheLayerRedis, Reason: CacheDifferenceField, Field: "Name", MySQL: "fixed", Cache: "a"}, report.Differences[2])
+	assert.Equal(t, CacheDifference{ID: 3, Layer: CacheLayerRedis, Reason: CacheDifferenceMissingInMySQL}, report.Differences[3])
+
+	report = engine.VerifyCache(&cacheVerifyEntity{}, &CacheVerifyOptions{FromID: 1})
+	assert.Equal(t, 3, report.Checked)
+	assert.Len(t, report.Differences, 4)
+	assert.Equal(t, CacheDifference{ID: 3, Layer: CacheLayerLocal, Reason: CacheDifferenceMissingInMySQL}, report.Differences[2])
+	assert.Equal(t, CacheDifference{ID: 3, Layer: CacheLayerRedis, Reason: CacheDifferenceMissingInMySQL}, report.Differences[3])
+
+	report = engine.VerifyCache(&cacheVerifyEntity{}, &CacheVerifyOptions{FromID: 1, ToID: 1})
+	assert.Equal(t, 1, report.Checked)
+	assert.Len(t, report.Differences, 2)
+
+	report = engine.VerifyCache(&cacheVerifyEntity{}, &CacheVerifyOptions{IDs: []uint64{1, 2, 3}, Repair: true})
+	assert.Equal(t, 2, report.Repaired)
+	report = engine.VerifyCache(&cacheVerifyEntity{}, &CacheVerifyOptions{IDs: []uint64{1, 2, 3}})
+	assert.Len(t, report.Differences, 0)
+	entity = &cacheVerifyEntity{}
+	assert.True(t, engine.LoadByID(1, entity))
+	assert.Equal(t, "fixed", entity.Name)
+	assert.False(t, engine.LoadByID(3, entity))
+}
+
+func TestVerifyCacheRedisSearch(t *testing.T) {
+	var entity *cacheVerifySearchEntity
+	registry := &Registry{}
+	engine, def := prepareTables(t, registry, 5, "", "2.0", entity)
+	defer def()
+
+	engine.FlushMany(&cacheVerifySearchEntity{Name: "a", Age: 10}, &cacheVerifySearchEntity{Name: "b", Age: 20})
+	report := engine.VerifyCache(&cacheVerifySearchEntity{}, &CacheVerifyOptions{FromID: 1})
+	assert.Equal(t, 2, report.Checked)
+	assert.Len(t, report.Differences, 0)
+
+	engine.GetMysql().Exec("UPDATE `cacheVerifySearchEntity` SET `Age` = 11 WHERE `ID` = 1")
+	engine.GetMysql().Exec("DELETE FROM `cacheVerifySearchEntity` WHERE `ID` = 2")
+	report = engine.VerifyCache(&cacheVerifySearchEntity{}, &CacheVerifyOptions{FromID: 1})
+	assert.Equal(t, 2, report.Checked)
+	assert.Len(t, report.Differences, 2)
+	assert.Equal(t, CacheDifference{ID: 1, Layer: CacheLayerRedisSearch, Reason: CacheDifferenceField, Field: "Age", MySQL: "11", Cache: "10"}, report.Differences[0])
+	assert.Equal(t, CacheDifference{ID: 2, Layer: CacheLayerRedisSearch, Reason: CacheDifferenceMissingInMySQL}, report.Differences[1])
+
+	report = engine.VerifyCache(&cacheVerifySearchEntity{}, &CacheVerifyOptions{FromID: 1, Repair: true})
+	assert.Equal(t, 2, report.Repaired)
+	report = engine.VerifyCache(&cacheVerifySearchEntity{}, &CacheVerifyOptions{FromID: 1})
+	assert.Equal(t, 1, report.Checked)
+	assert.Len(t, report.Differences, 0)
+	schema := engine.GetRegistry().GetTableSchemaForEntity(entity).(*tableSchema)
+	assert.Equal(t, "11", engine.GetRedis("search").HGetAll(schema.redisSearchPrefix + "1")["Age"])
+	query := NewRedisSearchQuery()
+	query.FilterInt("Age", 11)
+	assert.Equal(t, uint64(1), engine.RedisSearchCount(entity, query))
+}
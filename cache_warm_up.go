@@ -0,0 +1,231 @@
+package beeorm
+
+import (
+	"bytes"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const cacheWarmUpChannelName = "orm-cache-warm-up-channel"
+const cacheWarmUpLastIDKeyPrefix = "_orm_warm_up_id:"
+const defaultCacheWarmUpBatchSize = 1000
+
+type CacheWarmUpSearch struct {
+	Index     string
+	Arguments [][]interface{}
+}
+
+type CacheWarmUpOptions struct {
+	BatchSize           int
+	MaxQueriesPerSecond int
+	SkipEntities        bool
+	SkipLocalCache      bool
+	Searches            []CacheWarmUpSearch
+}
+
+type cacheWarmUpEvent struct {
+	Entity  string
+	Options CacheWarmUpOptions
+}
+
+type cacheWarmUpLimiter struct {
+	interval time.Duration
+	last     time.Time
+}
+
+func (l *cacheWarmUpLimiter) wait() {
+	if l.interval == 0 {
+		return
+	}
+	if !l.last.IsZero() {
+		sleep := l.interval - time.Since(l.last)
+		if sleep > 0 {
+			time.Sleep(sleep)
+		}
+	}
+	l.last = time.Now()
+}
+
+// EnableLazyCacheWarmUp registers stream used by WarmUpCacheLazy in default redis pool
+func (r *Registry) EnableLazyCacheWarmUp() {
+	r.RegisterRedisStream(cacheWarmUpChannelName, "default", []string{asyncConsumerGroupName})
+}
+
+func (e *Engine) WarmUpCache(entity Entity, options *CacheWarmUpOptions) (warmed int) {
+	return warmUpCache(e, initIfNeeded(e.registry, entity).tableSchema, options, 0, nil)
+}
+
+func (e *Engine) WarmUpCacheLazy(entity Entity, options *CacheWarmUpOptions) {
+	schema := initIfNeeded(e.registry, entity).tableSchema
+	if !schema.hasRedisCache {
+		panic(fmt.Errorf("lazy cache warm-up not allowed for entity without redis cache: '%s'", schema.t.String()))
+	}
+	_, has := e.registry.redisStreamPools[cacheWarmUpChannelName]
+	if !has {
+		panic(fmt.Errorf("lazy cache warm-up is not enabled in registry"))
+	}
+	event := cacheWarmUpEvent{Entity: schema.t.String()}
+	if options != nil {
+		event.Options = *options
+	}
+	e.GetEventBroker().Publish(cacheWarmUpChannelName, event)
+}
+
+func (r *BackgroundConsumer) handleCacheWarmUp(event Event) {
+	warmUpEvent := &cacheWarmUpEvent{}
+	event.Unserialize(warmUpEvent)
+	t, has := r.engine.registry.entities[warmUpEvent.Entity]
+	if !has {
+		event.Ack()
+		return
+	}
+	schema := r.engine.registry.tableSchemas[t]
+	redisCache, has := schema.GetRedisCache(r.engine)
+	if !has {
+		event.Ack()
+		return
+	}
+	lastID := uint64(0)
+	idRedisKey := cacheWarmUpLastIDKeyPrefix + warmUpEvent.Entity
+	idInRedis, has := redisCache.Get(idRedisKey)
+	if has {
+		lastID, _ = strconv.ParseUint(idInRedis, 10, 64)
+	}
+	// local cache of the consumer process is useless for other instances
+	options := warmUpEvent.Options
+	options.SkipLocalCache = true
+	warmUpCache(r.engine, schema, &options, lastID, func(id uint64) {
+		redisCache.Set(idRedisKey, strconv.FormatUint(id, 10), 86400)
+	})
+	redisCache.Del(idRedisKey)
+	event.Ack()
+}
+
+func warmUpCache(engine *Engine, schema *tableSchema, options *CacheWarmUpOptions, lastID uint64, progress func(lastID uint64)) int {
+	if options == nil {
+		options = &CacheWarmUpOptions{}
+	}
+	batchSize := options.BatchSize
+	if batchSize <= 0 {
+		batchSize = defaultCacheWarmUpBatchSize
+	}
+	limiter := &cacheWarmUpLimiter{}
+	if options.MaxQueriesPerSecond > 0 {
+		limiter.interval = time.Second / time.Duration(options.MaxQueriesPerSecond)
+	}
+	var localCache *LocalCache
+	hasLocalCache := false
+	if !options.SkipLocalCache {
+		localCache, hasLocalCache = schema.GetLocalCache(engine)
+	}
+	redisCache, hasRedis := schema.GetRedisCache(engine)
+	if !hasLocalCache && !hasRedis {
+		panic(fmt.Errorf("cache warm-up not allowed for entity without cache: '%s'", schema.t.String()))
+	}
+	serializer := newSerializer(nil)
+	warmed := 0
+	if !options.SkipEntities {
+		pool := schema.GetMysql(engine)
+		query := "SELECT " + schema.fieldsQuery + " FROM `" + schema.tableName + "` WHERE `ID` > ? ORDER BY `ID` LIMIT " + strconv.Itoa(batchSize)
+		redisTTL := time.Duration(schema.redisCacheTTL) * time.Second
+		for {
+			limiter.wait()
+			var pipeline *RedisPipeLine
+			if hasRedis {
+				pipeline = redisCache.PipeLine()
+			}
+			localCacheToSet := make([]interface{}, 0)
+			warmedUp := make(map[uint64][]byte)
+			rows := 0
+			results, def := pool.Query(query, lastID)
+			for results.Next() {
+				pointers := prepareScan(schema)
+				results.Scan(pointers...)
+				id := *pointers[schema.idIndex].(*uint64)
+				e := schema.NewEntity()
+				fillFromDBRow(serializer, id, engine.registry, pointers, e)
+				cacheKey := schema.getCacheKey(id)
+				if hasLocalCache {
+					localCacheToSet = append(localCacheToSet, cacheKey, newLocalCacheEntry(e.getORM().copyBinary(), schema.localCacheTTL))
+				}
+				if hasRedis {
+					pipeline.SetNX(cacheKey, schema.encodeRedisValue(e.getORM().binary), redisTTL)
+				}
+				warmedUp[id] = e.getORM().copyBinary()
+				lastID = id
+				rows++
+			}
+			def()
+			if len(localCacheToSet) > 0 {
+				localCache.MSet(localCacheToSet...)
+			}
+			if hasRedis && pipeline.commands > 0 {
+				pipeline.Exec()
+			}
+			if rows > 0 {
+				limiter.wait()
+				invalid := getChangedWarmedUpRows(engine, schema, serializer, warmedUp)
+				if len(invalid) > 0 {
+					if hasLocalCache {
+						localCache.Remove(invalid...)
+					}
+					if hasRedis {
+						redisCache.Del(invalid...)
+					}
+				}
+			}
+			warmed += rows
+			if rows > 0 && progress != nil {
+				progress(lastID)
+			}
+			if rows < batchSize {
+				break
+			}
+		}
+	}
+	for _, search := range options.Searches {
+		definition, isMany := schema.cachedIndexes[search.Index]
+		_, isOne := schema.cachedIndexesOne[search.Index]
+		if !isMany && !isOne {
+			panic(fmt.Errorf("index %s not found", search.Index))
+		}
+		for _, arguments := range search.Arguments {
+			limiter.wait()
+			if isMany {
+				cachedSearch(serializer, engine, schema.NewEntity(), search.Index, NewPager(1, definition.Max), arguments, false, nil)
+			} else {
+				cachedSearchOne(serializer, engine, schema.NewEntity(), search.Index, false, arguments, nil)
+			}
+		}
+	}
+	return warmed
+}
+
+// getChangedWarmedUpRows loads rows again, so cache keys of rows flushed during warm-up are not left stale
+func getChangedWarmedUpRows(engine *Engine, schema *tableSchema, serializer *serializer, warmedUp map[uint64][]byte) []string {
+	ids := make([]string, 0, len(warmedUp))
+	for id := range warmedUp {
+		ids = append(ids, strconv.FormatUint(id, 10))
+	}
+	/* #nosec */
+	query := "SELECT " + schema.fieldsQuery + " FROM `" + schema.tableName + "` WHERE `ID` IN (" + strings.Join(ids, ",") + ")"
+	results, def := schema.GetMysql(engine).Query(query)
+	defer def()
+	for results.Next() {
+		pointers := prepareScan(schema)
+		results.Scan(pointers...)
+		id := *pointers[schema.idIndex].(*uint64)
+		e := schema.NewEntity()
+		fillFromDBRow(serializer, id, engine.registry, pointers, e)
+		if bytes.Equal(warmedUp[id], e.getORM().binary) {
+			delete(warmedUp, id)
+		}
+	}
+	invalid := make([]string, 0, len(warmedUp))
+	for id := range warmedUp {
+		invalid = append(invalid, schema.getCacheKey(id))
+	}
+	return invalid
+}
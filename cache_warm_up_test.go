@@ -0,0 +1,130 @@
+package beeorm
+
+import (
+	"context"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type cacheWarmUpEntity struct {
+	ORM       `orm:"localCache;redisCache"`
+	ID        uint
+	Name      string
+	Age       uint8
+	IndexAge  *CachedQuery `query:":Age = ? ORDER BY :ID"`
+	IndexName *CachedQuery `queryOne:":Name = ?"`
+}
+
+func TestWarmUpCache(t *testing.T) {
+	var entity *cacheWarmUpEntity
+	registry := &Registry{}
+	registry.EnableLazyCacheWarmUp()
+	engine, def := prepareTables(t, registry, 5, "", "2.0", entity)
+	defer def()
+
+	flusher := engine.NewFlusher()
+	for i := 1; i <= 25; i++ {
+		flusher.Track(&cacheWarmUpEntity{Name: "Name " + strconv.Itoa(i), Age: uint8(i % 2)})
+	}
+	flusher.Flush()
+	ids := make([]uint64, 25)
+	for i := range ids {
+		ids[i] = uint64(i + 1)
+	}
+	engine.ClearCacheByIDs(&cacheWarmUpEntity{}, ids...)
+
+	options := &CacheWarmUpOptions{BatchSize: 10, MaxQueriesPerSecond: 100}
+	options.Searches = []CacheWarmUpSearch{
+		{Index: "IndexAge", Arguments: [][]interface{}{{0}, {1}}},
+		{Index: "IndexName", Arguments: [][]interface{}{{"Name 3"}}},
+	}
+	assert.Equal(t, 25, engine.WarmUpCache(&cacheWarmUpEntity{}, options))
+	schema := engine.GetRegistry().GetTableSchemaForEntity(&cacheWarmUpEntity{}).(*tableSchema)
+	_, has := engine.GetRedis().Get(schema.getCacheKey(25))
+	assert.True(t, has)
+	_, has = engine.GetLocalCache().Get(schema.getCacheKey(1))
+	assert.True(t, has)
+
+	dbLogger := &testLogHandler{}
+	engine.RegisterQueryLogger(dbLogger, true, false, false)
+	var rows []*cacheWarmUpEntity
+	assert.Equal(t, 12, engine.CachedSearch(&rows, "IndexAge", nil, 0))
+	assert.Len(t, rows, 12)
+	entity = &cacheWarmUpEntity{}
+	assert.True(t, engine.CachedSearchOne(entity, "IndexName", "Name 3"))
+	assert.Equal(t, uint(3), entity.ID)
+	assert.Len(t, dbLogger.Logs, 0)
+
+	engine.ClearCacheByIDs(&cacheWarmUpEntity{}, ids...)
+	engine.WarmUpCacheLazy(entity, &CacheWarmUpOptions{BatchSize: 7})
+	receiver := NewBackgroundConsumer(engine)
+	receiver.DisableLoop()
+	receiver.blockTime = time.Millisecond
+	receiver.Digest(context.Background())
+	_, has = engine.GetRedis().Get(schema.getCacheKey(25))
+	assert.True(t, has)
+	_, has = engine.GetRedis().Get(cacheWarmUpLastIDKeyPrefix + schema.t.String())
+	assert.False(t, has)
+	_, has = engine.GetLocalCache().Get(schema.getCacheKey(1))
+	assert.False(t, has)
+}
+
+type cacheWarmUpFlushLogger struct {
+	flush func()
+}
+
+func (h *cacheWarmUpFlushLogger) Handle(log map[string]interface{}) {
+	if h.flush != nil && strings.Contains(log["query"].(string), "WHERE `ID` > ?") {
+		flush := h.flush
+		h.flush = nil
+		flush()
+	}
+}
+
+func TestWarmUpCacheConcurrentFlush(t *testing.T) {
+	var entity *cacheWarmUpEntity
+	engine, def := prepareTables(t, &Registry{}, 5, "", "2.0", entity)
+	defer def()
+	schema := engine.GetRegistry().GetTableSchemaForEntity(entity).(*tableSchema)
+
+	entity = &cacheWarmUpEntity{Name: "John"}
+	engine.Flush(entity)
+	engine.ClearCacheByIDs(entity, 1)
+
+	flushEngine := engine.GetRegistry().CreateEngine()
+	logger := &cacheWarmUpFlushLogger{flush: func() {
+		updated := &cacheWarmUpEntity{}
+		flushEngine.LoadByID(1, updated)
+		updated.Name = "Tom"
+		flushEngine.Flush(updated)
+	}}
+	engine.RegisterQueryLogger(logger, true, false, false)
+	assert.Equal(t, 1, engine.WarmUpCache(entity, nil))
+	assert.Nil(t, logger.flush)
+
+	_, has := engine.GetRedis().Get(schema.getCacheKey(1))
+	assert.False(t, has)
+	_, has = engine.GetLocalCache().Get(schema.getCacheKey(1))
+	assert.False(t, has)
+	loaded := &cacheWarmUpEntity{}
+	assert.True(t, engine.LoadByID(1, loaded))
+	assert.Equal(t, "Tom", loaded.Name)
+	engine.GetLocalCache().Clear()
+	loaded = &cacheWarmUpEntity{}
+	assert.True(t, engine.LoadByID(1, loaded))
+	assert.Equal(t, "Tom", loaded.Name)
+}
+
+func TestWarmUpCacheLazyNotEnabled(t *testing.T) {
+	var entity *cacheWarmUpEntity
+	engine, def := prepareTables(t, &Registry{}, 5, "", "2.0", entity)
+	defer def()
+
+	assert.PanicsWithError(t, "lazy cache warm-up is not enabled in registry", func() {
+		engine.WarmUpCacheLazy(&cacheWarmUpEntity{}, nil)
+	})
+}
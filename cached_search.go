@@ -6,12 +6,156 @@ import (
 	"reflect"
 	"strconv"
 	"strings"
+	"time"
 
 	"github.com/segmentio/fasthash/fnv1a"
 )
 
 const idsOnCachePage = 1000
 
+// incremental lists are stored in sorted set with zero padded IDs as members, so they are ordered by ID,
+// member "total" holds -(total + 1) as score and is always first, set can hold only first IDs of the list
+var cachedListReadScript = newRedisScript("orm-cached-list-read", `
+local pages = {}
+for i = 3, #ARGV do
+	pages[i - 2] = false
+end
+local score = redis.call('TYPE', KEYS[1]).ok == 'zset' and redis.call('ZSCORE', KEYS[1], 'total')
+if not score then
+	redis.call('DEL', KEYS[1])
+	return pages
+end
+local total = -tonumber(score) - 1
+local loaded = redis.call('ZCARD', KEYS[1]) - 1
+local limit = math.min(total, tonumber(ARGV[1]))
+local pageSize = tonumber(ARGV[2])
+for i = 3, #ARGV do
+	local from = (tonumber(ARGV[i]) - 1) * pageSize
+	local to = math.min(from + pageSize, limit)
+	if to <= loaded or loaded >= limit then
+		local values = {tostring(total)}
+		if from < to then
+			for _, id in ipairs(redis.call('ZRANGE', KEYS[1], from + 1, to)) do
+				table.insert(values, id)
+			end
+		end
+		pages[i - 2] = table.concat(values, ' ')
+	end
+end
+return pages
+`)
+
+var cachedListFillScript = newRedisScript("orm-cached-list-fill", `
+redis.call('DEL', KEYS[1])
+redis.call('ZADD', KEYS[1], -tonumber(ARGV[1]) - 1, 'total')
+local values = {}
+for i = 3, #ARGV do
+	table.insert(values, 0)
+	table.insert(values, ARGV[i])
+	if #values == 1000 or i == #ARGV then
+		redis.call('ZADD', KEYS[1], unpack(values))
+		values = {}
+	end
+end
+if tonumber(ARGV[2]) > 0 then
+	redis.call('EXPIRE', KEYS[1], ARGV[2])
+end
+return 1
+`)
+
+var cachedListUpdateScript = newRedisScript("orm-cached-list-update", `
+local score = redis.call('TYPE', KEYS[1]).ok == 'zset' and redis.call('ZSCORE', KEYS[1], 'total')
+if not score then
+	redis.call('DEL', KEYS[1])
+	return 0
+end
+local id = ARGV[1]
+local max = tonumber(ARGV[3])
+local total = -tonumber(score) - 1
+local loaded = redis.call('ZCARD', KEYS[1]) - 1
+local inLoaded = loaded >= math.min(total, max) and loaded < max
+if loaded > 0 and id < redis.call('ZRANGE', KEYS[1], -1, -1)[1] then
+	inLoaded = true
+end
+local exists = redis.call('ZSCORE', KEYS[1], id)
+if ARGV[2] == '1' then
+	if exists then
+		return 0
+	end
+	total = total + 1
+	if inLoaded then
+		redis.call('ZADD', KEYS[1], 0, id)
+		if loaded + 1 > max then
+			redis.call('ZREMRANGEBYRANK', KEYS[1], -1, -1)
+		end
+	end
+else
+	if exists then
+		redis.call('ZREM', KEYS[1], id)
+	elseif inLoaded or total <= loaded then
+		return 0
+	end
+	total = total - 1
+end
+redis.call('ZADD', KEYS[1], -total - 1, 'total')
+return 1
+`)
+
+type cachedListUpdate struct {
+	key string
+	id  uint64
+	add bool
+	max int
+}
+
+func (u *cachedListUpdate) apply(r *RedisCache) {
+	add := 0
+	if u.add {
+		add = 1
+	}
+	r.runScript(cachedListUpdateScript, []string{r.addNamespacePrefix(u.key)}, cachedListMember(u.id), add, u.max)
+}
+
+func cachedListMember(id uint64) string {
+	return fmt.Sprintf("%020d", id)
+}
+
+func getCachedListPages(redisCache *RedisCache, definition *cachedQueryDefinition, cacheKey string, pages ...string) map[string]interface{} {
+	if !definition.Incremental {
+		return redisCache.HMGet(cacheKey, pages...)
+	}
+	args := make([]interface{}, len(pages)+2)
+	args[0] = definition.Max
+	args[1] = idsOnCachePage
+	for i, page := range pages {
+		args[i+2] = page
+	}
+	res := redisCache.runScript(cachedListReadScript, []string{redisCache.addNamespacePrefix(cacheKey)}, args...).([]interface{})
+	results := make(map[string]interface{}, len(pages))
+	for i, page := range pages {
+		results[page] = res[i]
+	}
+	return results
+}
+
+func fillCachedList(redisCache *RedisCache, schema *tableSchema, definition *cachedQueryDefinition, cacheKey string, ids []uint64, total int) {
+	if len(ids) > definition.Max {
+		ids = ids[0:definition.Max]
+	}
+	args := make([]interface{}, len(ids)+2)
+	args[0] = total
+	args[1] = definition.getRedisTTL(schema)
+	for i, id := range ids {
+		args[i+2] = cachedListMember(id)
+	}
+	redisCache.runScript(cachedListFillScript, []string{redisCache.addNamespacePrefix(cacheKey)}, args...)
+}
+
+type cachedSearchIDs struct {
+	ids   []uint64
+	total int
+}
+
 func cachedSearch(serializer *serializer, engine *Engine, entities interface{}, indexName string, pager *Pager,
 	arguments []interface{}, checkIsSlice bool, references []string) (totalRows int, ids []uint64) {
 	value := reflect.ValueOf(entities)
@@ -66,7 +210,7 @@ func cachedSearch(serializer *serializer, engine *Engine, entities interface{},
 			}
 		}
 		if hasRedis && len(nilsKeys) > 0 {
-			fromRedis := redisCache.HMGet(cacheKey, nilsKeys...)
+			fromRedis := getCachedListPages(redisCache, definition, cacheKey, nilsKeys...)
 			for key, idsFromRedis := range fromRedis {
 				if idsFromRedis != nil {
 					ids := strings.Split(idsFromRedis.(string), " ")
@@ -83,14 +227,16 @@ func cachedSearch(serializer *serializer, engine *Engine, entities interface{},
 		}
 	} else if hasRedis {
 		fromRedis = true
-		fromCache = redisCache.HMGet(cacheKey, pages...)
+		fromCache = getCachedListPages(redisCache, definition, cacheKey, pages...)
 	}
 	hasNil := false
 	totalRows = 0
 	minPage := 9999
 	maxPage := 0
+	misses := 0
 	for key, idsSlice := range fromCache {
 		if idsSlice == nil {
+			misses++
 			hasNil = true
 			p, _ := strconv.Atoi(key)
 			if p < minPage {
@@ -116,10 +262,20 @@ func cachedSearch(serializer *serializer, engine *Engine, entities interface{},
 			}
 		}
 	}
+	schema.cacheCounters.search(len(fromCache)-misses, misses)
 
 	if hasNil {
+		if definition.Incremental && hasRedis {
+			// sorted set holds only first IDs of the list
+			minPage = 1
+		}
 		searchPager := NewPager(minPage, maxPage*idsOnCachePage)
-		results, total := searchIDsWithCount(false, engine, where, searchPager, entityType)
+		flightKey := cacheKey + ":" + strconv.Itoa(minPage) + ":" + strconv.Itoa(maxPage)
+		fromDB, _ := engine.registry.singleFlight.do(flightKey, func() interface{} {
+			results, total := searchIDsWithCount(false, engine, where, searchPager, entityType)
+			return &cachedSearchIDs{ids: results, total: total}
+		})
+		results, total := fromDB.(*cachedSearchIDs).ids, fromDB.(*cachedSearchIDs).total
 		totalRows = total
 		cacheFields := make([]interface{}, 0)
 		for key, ids := range fromCache {
@@ -150,8 +306,13 @@ func cachedSearch(serializer *serializer, engine *Engine, entities interface{},
 				cacheFields = append(cacheFields, page, cacheValue)
 			}
 		}
-		if hasRedis {
+		if hasRedis && definition.Incremental {
+			fillCachedList(redisCache, schema, definition, cacheKey, results, total)
+		} else if hasRedis {
 			redisCache.HSet(cacheKey, cacheFields...)
+			if ttl := definition.getRedisTTL(schema); ttl > 0 {
+				redisCache.Expire(cacheKey, time.Duration(ttl)*time.Second)
+			}
 		}
 	}
 	nilKeysLen := len(nilsKeys)
@@ -162,7 +323,7 @@ func cachedSearch(serializer *serializer, engine *Engine, entities interface{},
 			values = append(values, filledPages[v]...)
 			fields[v] = values
 		}
-		localCache.HMSet(cacheKey, fields)
+		localCache.HMSetWithTTL(cacheKey, definition.getLocalTTL(schema), fields)
 	}
 
 	resultsIDs := make([]uint64, 0)
@@ -219,6 +380,7 @@ func cachedSearchOne(serializer *serializer, engine *Engine, entity Entity, inde
 	}
 	id := uint64(0)
 	if fromCache["1"] == nil {
+		schema.cacheCounters.search(0, 1)
 		results, _ := searchIDs(true, engine, Where, NewPager(1, 1), false, entityType)
 		l := len(results)
 		value := strconv.Itoa(l)
@@ -227,12 +389,16 @@ func cachedSearchOne(serializer *serializer, engine *Engine, entity Entity, inde
 			value += " " + strconv.FormatUint(results[0], 10)
 		}
 		if hasLocalCache {
-			localCache.HMSet(cacheKey, map[string]interface{}{"1": value})
+			localCache.HMSetWithTTL(cacheKey, definition.getLocalTTL(schema), map[string]interface{}{"1": value})
 		}
 		if hasRedis {
 			redisCache.HSet(cacheKey, "1", value)
+			if ttl := definition.getRedisTTL(schema); ttl > 0 {
+				redisCache.Expire(cacheKey, time.Duration(ttl)*time.Second)
+			}
 		}
 	} else {
+		schema.cacheCounters.search(1, 0)
 		ids := strings.Split(fromCache["1"].(string), " ")
 		if ids[0] != "0" {
 			id, _ = strconv.ParseUint(ids[1], 10, 64)
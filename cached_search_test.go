@@ -335,3 +335,101 @@ func BenchmarkCachedSearch(b *testing.B) {
 		_ = engine.CachedSearch(&rows, "IndexAll", pager)
 	}
 }
+
+type cachedSearchIncrementalEntity struct {
+	ORM        `orm:"redisCache"`
+	ID         uint
+	Age        uint16
+	FakeDelete bool
+	IndexAge   *CachedQuery `query:":Age = ? ORDER BY :ID" orm:"max=3;ttl=30"`
+	IndexAll   *CachedQuery `query:""`
+}
+
+func TestCachedSearchIncremental(t *testing.T) {
+	var entity *cachedSearchIncrementalEntity
+	engine, def := prepareTables(t, &Registry{}, 5, "", "2.0", entity)
+	defer def()
+	schema := engine.GetRegistry().GetTableSchemaForEntity(entity).(*tableSchema)
+	assert.Equal(t, 3, schema.cachedIndexes["IndexAge"].Max)
+	assert.Equal(t, 30, schema.cachedIndexes["IndexAge"].TTL)
+	assert.True(t, schema.cachedIndexes["IndexAge"].Incremental)
+	assert.Equal(t, 50000, schema.cachedIndexes["IndexAll"].Max)
+	assert.True(t, schema.cachedIndexes["IndexAll"].Incremental)
+
+	flusher := engine.NewFlusher()
+	for i := 1; i <= 5; i++ {
+		flusher.Track(&cachedSearchIncrementalEntity{Age: 10})
+	}
+	flusher.Flush()
+
+	var rows []*cachedSearchIncrementalEntity
+	assert.Equal(t, 5, engine.CachedSearch(&rows, "IndexAll", nil))
+	assert.Equal(t, 5, engine.CachedSearch(&rows, "IndexAge", NewPager(1, 3), 10))
+	assert.Len(t, rows, 3)
+	assert.Equal(t, int64(4), engine.GetRedis().ZCard(getCacheKeySearch(schema, "IndexAge", 10)))
+	assert.PanicsWithError(t, "max cache index page size (3) exceeded IndexAge", func() {
+		engine.CachedSearch(&rows, "IndexAge", NewPager(2, 3), 10)
+	})
+
+	dbLogger := &testLogHandler{}
+	engine.RegisterQueryLogger(dbLogger, true, false, false)
+	engine.Flush(&cachedSearchIncrementalEntity{Age: 10})
+	dbLogger.clear()
+	totalRows, ids := engine.CachedSearchIDs(&cachedSearchIncrementalEntity{}, "IndexAll", nil)
+	assert.Equal(t, 6, totalRows)
+	assert.Equal(t, []uint64{1, 2, 3, 4, 5, 6}, ids)
+	totalRows, ids = engine.CachedSearchIDs(&cachedSearchIncrementalEntity{}, "IndexAge", nil, 10)
+	assert.Equal(t, 6, totalRows)
+	assert.Equal(t, []uint64{1, 2, 3}, ids)
+	assert.Len(t, dbLogger.Logs, 0)
+
+	entity = &cachedSearchIncrementalEntity{}
+	engine.LoadByID(2, entity)
+	engine.Delete(entity)
+	dbLogger.clear()
+	totalRows, ids = engine.CachedSearchIDs(&cachedSearchIncrementalEntity{}, "IndexAll", nil)
+	assert.Equal(t, 5, totalRows)
+	assert.Equal(t, []uint64{1, 3, 4, 5, 6}, ids)
+	assert.Len(t, dbLogger.Logs, 0)
+	totalRows, ids = engine.CachedSearchIDs(&cachedSearchIncrementalEntity{}, "IndexAge", nil, 10)
+	assert.Equal(t, 5, totalRows)
+	assert.Equal(t, []uint64{1, 3, 4}, ids)
+	assert.NotEmpty(t, dbLogger.Logs)
+
+	entity = &cachedSearchIncrementalEntity{}
+	engine.LoadByID(6, entity)
+	entity.Age = 20
+	engine.Flush(entity)
+	dbLogger.clear()
+	assert.Equal(t, 5, engine.CachedSearchCount(&cachedSearchIncrementalEntity{}, "IndexAll"))
+	assert.Len(t, dbLogger.Logs, 0)
+	assert.Equal(t, 4, engine.CachedSearchCount(&cachedSearchIncrementalEntity{}, "IndexAge", 10))
+	assert.Equal(t, 1, engine.CachedSearchCount(&cachedSearchIncrementalEntity{}, "IndexAge", 20))
+
+	engine.ForceDelete(entity)
+	dbLogger.clear()
+	assert.Equal(t, 4, engine.CachedSearchCount(&cachedSearchIncrementalEntity{}, "IndexAll"))
+	assert.Equal(t, 0, engine.CachedSearchCount(&cachedSearchIncrementalEntity{}, "IndexAge", 20))
+	assert.Len(t, dbLogger.Logs, 0)
+
+	engine.GetRedis().Del(getCacheKeySearch(schema, "IndexAll"))
+	engine.GetRedis().HSet(getCacheKeySearch(schema, "IndexAll"), "1", "2 1 3")
+	totalRows, ids = engine.CachedSearchIDs(&cachedSearchIncrementalEntity{}, "IndexAll", nil)
+	assert.Equal(t, 4, totalRows)
+	assert.Equal(t, []uint64{1, 3, 4, 5}, ids)
+
+	flusher = engine.NewFlusher()
+	for i := 1; i <= 3; i++ {
+		flusher.Track(&cachedSearchIncrementalEntity{Age: 30})
+	}
+	flusher.Flush()
+	assert.Equal(t, 3, engine.CachedSearchCount(&cachedSearchIncrementalEntity{}, "IndexAge", 30))
+	assert.Equal(t, int64(4), engine.GetRedis().ZCard(getCacheKeySearch(schema, "IndexAge", 30)))
+	id := engine.GetMysql().Exec("INSERT INTO `cachedSearchIncrementalEntity`(`Age`) VALUES(30)").LastInsertId()
+	entity = &cachedSearchIncrementalEntity{}
+	engine.LoadByID(id, entity)
+	engine.ForceDelete(entity)
+	dbLogger.clear()
+	assert.Equal(t, 3, engine.CachedSearchCount(&cachedSearchIncrementalEntity{}, "IndexAge", 30))
+	assert.Len(t, dbLogger.Logs, 0)
+}
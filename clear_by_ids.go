@@ -14,8 +14,11 @@ func clearByIDs(engine *Engine, entity Entity, ids ...uint64) {
 	if has {
 		localCache.Remove(cacheKeys...)
 	}
-	redisCache, has := schema.GetRedisCache(engine)
-	if has {
+	redisCache, hasRedis := schema.GetRedisCache(engine)
+	if hasRedis {
 		redisCache.Del(cacheKeys...)
 	}
+	if has {
+		engine.publishLocalCacheInvalidation(localCache.config.GetCode(), cacheKeys...)
+	}
 }
@@ -0,0 +1,93 @@
+package beeorm
+
+import (
+	"fmt"
+	"strconv"
+	"sync"
+
+	"github.com/klauspost/compress/snappy"
+	"github.com/klauspost/compress/zstd"
+)
+
+const (
+	compressionSnappy           = "snappy"
+	compressionZstd             = "zstd"
+	compressionHeaderSnappy     = byte(1)
+	compressionHeaderZstd       = byte(2)
+	defaultCompressionThreshold = 256
+)
+
+var zstdOnce sync.Once
+var zstdEncoder *zstd.Encoder
+var zstdDecoder *zstd.Decoder
+
+func initZstd() {
+	zstdOnce.Do(func() {
+		var err error
+		zstdEncoder, err = zstd.NewWriter(nil)
+		checkError(err)
+		zstdDecoder, err = zstd.NewReader(nil)
+		checkError(err)
+	})
+}
+
+func (tableSchema *tableSchema) initCompression() error {
+	compression := tableSchema.getTag("compress", "", "")
+	if compression == "" {
+		return nil
+	}
+	if compression != compressionSnappy && compression != compressionZstd {
+		return fmt.Errorf("invalid compression '%s' in %s", compression, tableSchema.t.String())
+	}
+	if !tableSchema.hasRedisCache {
+		return fmt.Errorf("compression in %s requires redisCache", tableSchema.t.String())
+	}
+	tableSchema.compression = compression
+	tableSchema.compressionThreshold = defaultCompressionThreshold
+	threshold := tableSchema.getTag("compressThreshold", "", "")
+	if threshold != "" {
+		value, err := strconv.Atoi(threshold)
+		if err != nil || value < 0 {
+			return fmt.Errorf("invalid compressThreshold '%s' in %s", threshold, tableSchema.t.String())
+		}
+		tableSchema.compressionThreshold = value
+	}
+	if compression == compressionZstd {
+		initZstd()
+	}
+	return nil
+}
+
+func (tableSchema *tableSchema) compressRedisValue(binary []byte) []byte {
+	if tableSchema.compression == "" || len(binary) < tableSchema.compressionThreshold {
+		return binary
+	}
+	var compressed []byte
+	if tableSchema.compression == compressionSnappy {
+		compressed = append([]byte{compressionHeaderSnappy}, snappy.Encode(nil, binary)...)
+	} else {
+		compressed = zstdEncoder.EncodeAll(binary, []byte{compressionHeaderZstd})
+	}
+	if len(compressed) >= len(binary) {
+		return binary
+	}
+	return compressed
+}
+
+func (tableSchema *tableSchema) decompressRedisValue(value []byte) []byte {
+	if len(value) == 0 || value[0] == tableSchema.structureHashPrefix {
+		return value
+	}
+	switch value[0] {
+	case compressionHeaderSnappy:
+		binary, err := snappy.Decode(nil, value[1:])
+		checkError(err)
+		return binary
+	case compressionHeaderZstd:
+		initZstd()
+		binary, err := zstdDecoder.DecodeAll(value[1:], nil)
+		checkError(err)
+		return binary
+	}
+	return value
+}
@@ -0,0 +1,80 @@
+package beeorm
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/klauspost/compress/snappy"
+	"github.com/stretchr/testify/assert"
+)
+
+type compressionSnappyEntity struct {
+	ORM         `orm:"redisCache;compress=snappy;compressThreshold=10"`
+	ID          uint
+	Description string `orm:"length=max"`
+}
+
+type compressionZstdEntity struct {
+	ORM         `orm:"redisCache;compress=zstd"`
+	ID          uint
+	Description string `orm:"length=max"`
+}
+
+type compressionInvalidEntity struct {
+	ORM `orm:"redisCache;compress=gzip"`
+	ID  uint
+}
+
+func TestCompression(t *testing.T) {
+	var snappyEntity *compressionSnappyEntity
+	var zstdEntity *compressionZstdEntity
+	engine, def := prepareTables(t, &Registry{}, 5, "", "2.0", snappyEntity, zstdEntity)
+	defer def()
+
+	description := strings.Repeat("compressed text ", 100)
+	engine.FlushMany(&compressionSnappyEntity{Description: description}, &compressionZstdEntity{Description: description})
+	snappySchema := engine.GetRegistry().GetTableSchemaForEntity(snappyEntity).(*tableSchema)
+	zstdSchema := engine.GetRegistry().GetTableSchemaForEntity(zstdEntity).(*tableSchema)
+
+	snappyEntity = &compressionSnappyEntity{}
+	assert.True(t, engine.LoadByID(1, snappyEntity))
+	zstdEntity = &compressionZstdEntity{}
+	assert.True(t, engine.LoadByID(1, zstdEntity))
+
+	raw, has := engine.GetRedis().Get(snappySchema.getCacheKey(1))
+	assert.True(t, has)
+	assert.Equal(t, compressionHeaderSnappy, raw[0])
+	assert.Less(t, len(raw), len(description))
+	raw, has = engine.GetRedis().Get(zstdSchema.getCacheKey(1))
+	assert.True(t, has)
+	assert.Equal(t, compressionHeaderZstd, raw[0])
+	assert.Less(t, len(raw), len(description))
+
+	snappyEntity = &compressionSnappyEntity{}
+	assert.True(t, engine.LoadByID(1, snappyEntity))
+	assert.Equal(t, description, snappyEntity.Description)
+	var rows []*compressionZstdEntity
+	engine.LoadByIDs([]uint64{1}, &rows)
+	assert.Len(t, rows, 1)
+	assert.Equal(t, description, rows[0].Description)
+
+	engine.GetRedis().Set(snappySchema.getCacheKey(1), snappyEntity.getORM().binary, 0)
+	snappyEntity = &compressionSnappyEntity{}
+	assert.True(t, engine.LoadByID(1, snappyEntity))
+	assert.Equal(t, description, snappyEntity.Description)
+
+	registry := &Registry{}
+	registry.RegisterMySQLPool("root:root@tcp(localhost:3311)/test")
+	registry.RegisterRedis("localhost:6382", "", 15)
+	registry.RegisterEntity(&compressionInvalidEntity{})
+	_, _, err := registry.Validate()
+	assert.EqualError(t, err, "invalid compression 'gzip' in beeorm.compressionInvalidEntity")
+}
+
+func TestDecompressRedisValueHashPrefix(t *testing.T) {
+	schema := &tableSchema{compression: compressionSnappy, structureHash: 0x81, structureHashPrefix: 0x81}
+	raw := []byte{0x81, 0x01, compressionHeaderSnappy, 10}
+	assert.Equal(t, raw, schema.decompressRedisValue(raw))
+	compressed := append([]byte{compressionHeaderSnappy}, snappy.Encode(nil, raw)...)
+	assert.Equal(t, raw, schema.decompressRedisValue(compressed))
+}
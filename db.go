@@ -19,7 +19,7 @@ type MySQLPoolConfig interface {
 	GetVersion() int
 	getClient() *sql.DB
 	getAutoincrement() uint64
-	getMaxConnections() int
+	GetOptions() MySQLPoolOptions
 }
 
 type mySQLPoolConfig struct {
@@ -30,6 +30,7 @@ type mySQLPoolConfig struct {
 	autoincrement  uint64
 	version        int
 	maxConnections int
+	options        MySQLPoolOptions
 }
 
 func (p *mySQLPoolConfig) GetCode() string {
@@ -56,8 +57,8 @@ func (p *mySQLPoolConfig) getAutoincrement() uint64 {
 	return p.autoincrement
 }
 
-func (p *mySQLPoolConfig) getMaxConnections() int {
-	return p.maxConnections
+func (p *mySQLPoolConfig) GetOptions() MySQLPoolOptions {
+	return p.options
 }
 
 type ExecResult interface {
@@ -259,6 +260,7 @@ func (db *DB) Commit() {
 		for cacheCode, pairs := range db.engine.afterCommitLocalCacheSets {
 			cache := db.engine.GetLocalCache(cacheCode)
 			cache.MSet(pairs...)
+			db.engine.afterCommitInvalidations.addSets(cacheCode, pairs)
 		}
 		db.engine.afterCommitLocalCacheSets = nil
 	}
@@ -267,6 +269,10 @@ func (db *DB) Commit() {
 		db.engine.afterCommitRedisFlusher.Flush()
 		db.engine.afterCommitRedisFlusher = nil
 	}
+	if db.engine.afterCommitInvalidations != nil {
+		db.engine.publishLocalCacheInvalidations(db.engine.afterCommitInvalidations)
+		db.engine.afterCommitInvalidations = nil
+	}
 }
 
 func (db *DB) Rollback() {
@@ -280,6 +286,7 @@ func (db *DB) Rollback() {
 	checkError(err)
 	db.engine.afterCommitLocalCacheSets = nil
 	db.engine.afterCommitRedisFlusher = nil
+	db.engine.afterCommitInvalidations = nil
 	db.inTransaction = false
 }
 
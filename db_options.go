@@ -0,0 +1,184 @@
+package beeorm
+
+import (
+	"database/sql"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/go-sql-driver/mysql"
+)
+
+const (
+	mySQLDefaultMaxConnections  = 100
+	mySQLDefaultConnMaxLifetime = time.Second * 180
+	mySQLDefaultVersion         = 8
+)
+
+type MySQLPoolOptions struct {
+	// MaxOpenConnections is equal to 90% of server max_connections but not more than 100 by default
+	MaxOpenConnections int
+	// MaxIdleConnections is equal to MaxOpenConnections by default
+	MaxIdleConnections int
+	// ConnMaxLifetime is equal to server wait_timeout but not more than 180 seconds by default
+	ConnMaxLifetime time.Duration
+	ConnMaxIdleTime time.Duration
+	ConnectTimeout  time.Duration
+	ReadTimeout     time.Duration
+	WriteTimeout    time.Duration
+	// SessionVariables are set on every new connection, for example time_zone or sql_mode
+	SessionVariables map[string]string
+	// LazyConnect skips server queries in Registry.Validate, ServerVersion and AutoIncrementIncrement are used instead
+	LazyConnect   bool
+	ServerVersion int
+	// AutoIncrementIncrement must be equal to server auto_increment_increment when LazyConnect is used, 1 by default
+	AutoIncrementIncrement uint64
+}
+
+// set is used by yaml options
+func (o *MySQLPoolOptions) set(name string, value interface{}) bool {
+	asString := fmt.Sprintf("%v", value)
+	var err error
+	switch name {
+	case "maxOpenConnections":
+		o.MaxOpenConnections, err = strconv.Atoi(asString)
+	case "maxIdleConnections":
+		o.MaxIdleConnections, err = strconv.Atoi(asString)
+	case "connMaxLifetime":
+		o.ConnMaxLifetime, err = parseRedisOptionDuration(asString)
+	case "connMaxIdleTime":
+		o.ConnMaxIdleTime, err = parseRedisOptionDuration(asString)
+	case "connectTimeout":
+		o.ConnectTimeout, err = parseRedisOptionDuration(asString)
+	case "readTimeout":
+		o.ReadTimeout, err = parseRedisOptionDuration(asString)
+	case "writeTimeout":
+		o.WriteTimeout, err = parseRedisOptionDuration(asString)
+	case "lazyConnect":
+		o.LazyConnect, err = strconv.ParseBool(asString)
+	case "serverVersion":
+		o.ServerVersion, err = strconv.Atoi(asString)
+	case "autoIncrementIncrement":
+		o.AutoIncrementIncrement, err = strconv.ParseUint(asString, 10, 64)
+	case "sessionVariables":
+		variables, ok := value.(map[string]interface{})
+		if !ok {
+			asMap, ok := value.(map[interface{}]interface{})
+			if !ok {
+				return false
+			}
+			variables = make(map[string]interface{}, len(asMap))
+			for k, v := range asMap {
+				variables[fmt.Sprintf("%v", k)] = v
+			}
+		}
+		o.SessionVariables = make(map[string]string, len(variables))
+		for k, v := range variables {
+			o.SessionVariables[k] = fmt.Sprintf("%v", v)
+		}
+	default:
+		return false
+	}
+	return err == nil
+}
+
+func (o MySQLPoolOptions) applyToDataSourceName(dataSourceName string) (string, error) {
+	if o.ConnectTimeout == 0 && o.ReadTimeout == 0 && o.WriteTimeout == 0 && len(o.SessionVariables) == 0 {
+		return dataSourceName, nil
+	}
+	config, err := mysql.ParseDSN(dataSourceName)
+	if err != nil {
+		return "", err
+	}
+	if o.ConnectTimeout > 0 {
+		config.Timeout = o.ConnectTimeout
+	}
+	if o.ReadTimeout > 0 {
+		config.ReadTimeout = o.ReadTimeout
+	}
+	if o.WriteTimeout > 0 {
+		config.WriteTimeout = o.WriteTimeout
+	}
+	if len(o.SessionVariables) > 0 && config.Params == nil {
+		config.Params = make(map[string]string)
+	}
+	for name, value := range o.SessionVariables {
+		// driver executes SET name=value so strings must be quoted
+		_, err = strconv.ParseFloat(value, 64)
+		if err != nil && !strings.HasPrefix(value, "'") {
+			value = "'" + strings.Replace(value, "'", "\\'", -1) + "'"
+		}
+		config.Params[name] = value
+	}
+	return config.FormatDSN(), nil
+}
+
+func (p *mySQLPoolConfig) connect() {
+	db, err := sql.Open("mysql", p.GetDataSourceURI())
+	checkError(err)
+	p.client = db
+	maxOpen := p.options.MaxOpenConnections
+	lifetime := p.options.ConnMaxLifetime
+	if p.options.LazyConnect {
+		p.version = p.options.ServerVersion
+		if p.version == 0 {
+			p.version = mySQLDefaultVersion
+		}
+		p.autoincrement = p.options.AutoIncrementIncrement
+		if p.autoincrement == 0 {
+			p.autoincrement = 1
+		}
+		if maxOpen == 0 {
+			maxOpen = p.maxConnections
+		}
+		if maxOpen == 0 {
+			maxOpen = mySQLDefaultMaxConnections
+		}
+		if lifetime == 0 {
+			lifetime = mySQLDefaultConnMaxLifetime
+		}
+	} else {
+		var version string
+		err = db.QueryRow("SELECT VERSION()").Scan(&version)
+		checkError(err)
+		p.version, _ = strconv.Atoi(strings.Split(version, ".")[0])
+
+		var skip string
+		err = db.QueryRow("SHOW VARIABLES LIKE 'auto_increment_increment'").Scan(&skip, &p.autoincrement)
+		checkError(err)
+
+		var maxConnections int
+		err = db.QueryRow("SHOW VARIABLES LIKE 'max_connections'").Scan(&skip, &maxConnections)
+		checkError(err)
+		var waitTimeout int
+		err = db.QueryRow("SHOW VARIABLES LIKE 'wait_timeout'").Scan(&skip, &waitTimeout)
+		checkError(err)
+		if maxOpen == 0 {
+			maxConnections = int(math.Max(math.Floor(float64(maxConnections)*0.9), 1))
+			maxOpen = p.maxConnections
+			if maxOpen == 0 {
+				maxOpen = mySQLDefaultMaxConnections
+			}
+			maxOpen = int(math.Min(float64(maxConnections), float64(maxOpen)))
+		}
+		if lifetime == 0 {
+			// connections must be closed before server drops them
+			lifetime = time.Duration(waitTimeout) * time.Second
+			if lifetime <= 0 || lifetime > mySQLDefaultConnMaxLifetime {
+				lifetime = mySQLDefaultConnMaxLifetime
+			}
+		}
+	}
+	maxIdle := p.options.MaxIdleConnections
+	if maxIdle == 0 {
+		maxIdle = maxOpen
+	}
+	db.SetMaxOpenConns(maxOpen)
+	db.SetMaxIdleConns(maxIdle)
+	db.SetConnMaxLifetime(lifetime)
+	if p.options.ConnMaxIdleTime > 0 {
+		db.SetConnMaxIdleTime(p.options.ConnMaxIdleTime)
+	}
+}
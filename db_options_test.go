@@ -0,0 +1,50 @@
+package beeorm
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMySQLPoolOptions(t *testing.T) {
+	registry := &Registry{}
+	registry.RegisterMySQLPoolWithOptions("root:root@tcp(localhost:1)/test?limit_connections=10", MySQLPoolOptions{
+		MaxIdleConnections: 2,
+		ConnMaxIdleTime:    time.Minute,
+		ConnectTimeout:     time.Second,
+		ReadTimeout:        time.Second * 5,
+		WriteTimeout:       time.Second * 6,
+		SessionVariables:   map[string]string{"time_zone": "+00:00", "sql_mode": "'TRADITIONAL'", "autocommit": "1"},
+		LazyConnect:        true,
+	})
+	registry.RegisterMySQLPoolWithOptions("root:root@tcp(localhost:1)/test", MySQLPoolOptions{
+		MaxOpenConnections:     7,
+		ConnMaxLifetime:        time.Minute,
+		LazyConnect:            true,
+		ServerVersion:          5,
+		AutoIncrementIncrement: 2,
+	}, "second")
+	uri := registry.mysqlPools["default"].GetDataSourceURI()
+	assert.Contains(t, uri, "timeout=1s")
+	assert.Contains(t, uri, "readTimeout=5s")
+	assert.Contains(t, uri, "writeTimeout=6s")
+	assert.Contains(t, uri, "time_zone=%27%2B00%3A00%27")
+	assert.Contains(t, uri, "sql_mode=%27TRADITIONAL%27")
+	assert.Contains(t, uri, "autocommit=1")
+	assert.Contains(t, uri, "multiStatements=true")
+	assert.NotContains(t, uri, "limit_connections")
+	assert.Equal(t, "root:root@tcp(localhost:1)/test?multiStatements=true", registry.mysqlPools["second"].GetDataSourceURI())
+
+	validatedRegistry, def, err := registry.Validate()
+	assert.NoError(t, err)
+	defer def()
+	engine := validatedRegistry.CreateEngine()
+	pool := engine.GetMysql().GetPoolConfig()
+	assert.Equal(t, 8, pool.GetVersion())
+	assert.Equal(t, 10, pool.getClient().Stats().MaxOpenConnections)
+	assert.Equal(t, 5, engine.GetMysql("second").GetPoolConfig().GetVersion())
+	assert.Equal(t, 7, engine.GetMysql("second").GetPoolConfig().getClient().Stats().MaxOpenConnections)
+	assert.Equal(t, uint64(1), pool.getAutoincrement())
+	assert.Equal(t, uint64(2), engine.GetMysql("second").GetPoolConfig().getAutoincrement())
+}
@@ -5,8 +5,6 @@ import (
 	"fmt"
 	"reflect"
 	"sync"
-
-	"github.com/golang/groupcache/lru"
 )
 
 type Engine struct {
@@ -25,6 +23,7 @@ type Engine struct {
 	hasLocalCacheLogger       bool
 	afterCommitLocalCacheSets map[string][]interface{}
 	afterCommitRedisFlusher   *redisFlusher
+	afterCommitInvalidations  localCacheInvalidations
 	eventBroker               *eventBroker
 	sync.Mutex
 }
@@ -82,7 +81,7 @@ func (e *Engine) GetLocalCache(code ...string) *LocalCache {
 		config, has := e.registry.localCacheServers[dbCode]
 		if !has {
 			if dbCode == requestCacheKey {
-				cache = &LocalCache{config: &localCachePoolConfig{code: dbCode, limit: 5000, lru: lru.New(5000)}, engine: e}
+				cache = &LocalCache{config: newLocalCachePoolConfig(dbCode, LocalCacheOptions{MaxEntries: 5000, Shards: 1}), engine: e}
 				if e.localCache == nil {
 					e.localCache = map[string]*LocalCache{dbCode: cache}
 				} else {
@@ -212,6 +211,33 @@ func (e *Engine) DeleteMany(entities ...Entity) {
 	e.FlushMany(entities...)
 }
 
+func (e *Engine) Restore(entity Entity) {
+	e.RestoreMany(entity)
+}
+
+func (e *Engine) RestoreMany(entities ...Entity) {
+	for _, entity := range entities {
+		orm := initIfNeeded(e.registry, entity)
+		if !orm.tableSchema.hasFakeDelete {
+			panic(fmt.Errorf("entity '%s' has no FakeDelete or DeletedAt field", orm.tableSchema.t.String()))
+		}
+		orm.restore = true
+		orm.fakeDelete = false
+		if orm.tableSchema.hasDeletedAt {
+			deletedAt := orm.elem.FieldByName("DeletedAt")
+			deletedAt.Set(reflect.Zero(deletedAt.Type()))
+		} else {
+			orm.elem.FieldByName("FakeDelete").SetBool(false)
+		}
+	}
+	defer func() {
+		for _, entity := range entities {
+			entity.getORM().restore = false
+		}
+	}()
+	e.FlushMany(entities...)
+}
+
 func (e *Engine) MarkDirty(entity Entity, queueCode string, ids ...uint64) {
 	entityName := e.GetRegistry().GetTableSchemaForEntity(entity).GetType().String()
 	flusher := e.GetEventBroker().NewFlusher()
@@ -237,6 +263,22 @@ func (e *Engine) SearchWithFakeDeleted(where *Where, pager *Pager, entities inte
 	search(newSerializer(nil), false, e, where, pager, false, true, reflect.ValueOf(entities).Elem(), references...)
 }
 
+// SearchOnlyFakeDeleted is a separate method because SearchWithFakeDeleted ends with variadic references
+// and can't get another argument without breaking its callers
+func (e *Engine) SearchOnlyFakeDeleted(where *Where, pager *Pager, entities interface{}, references ...string) {
+	elem := reflect.ValueOf(entities).Elem()
+	entityType, has, name := getEntityTypeForSlice(e.registry, elem.Type(), true)
+	if !has {
+		panic(fmt.Errorf("entity '%s' is not registered", name))
+	}
+	schema := getTableSchema(e.registry, entityType)
+	if !schema.hasFakeDelete {
+		panic(fmt.Errorf("entity '%s' has no FakeDelete or DeletedAt field", schema.t.String()))
+	}
+	where = &Where{query: schema.fakeDeleteOnlyWhere + " AND " + where.String(), parameters: where.GetParameters()}
+	search(newSerializer(nil), false, e, where, pager, false, true, elem, references...)
+}
+
 func (e *Engine) SearchIDsWithCount(where *Where, pager *Pager, entity Entity) (results []uint64, totalRows int) {
 	return searchIDsWithCount(true, e, where, pager, reflect.TypeOf(entity).Elem())
 }
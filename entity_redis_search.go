@@ -13,6 +13,7 @@ func (e *Engine) RedisSearchAggregate(entity Entity, query *RedisSearchAggregate
 	if query.query == nil {
 		query.query = NewRedisSearchQuery()
 	}
+	checkOnlyFakeDeleteRows(schema, query.query)
 	if schema.hasSearchableFakeDelete {
 		query.query.hasFakeDelete = true
 	}
@@ -126,6 +127,7 @@ func redisSearch(e *Engine, schema *tableSchema, query *RedisSearchQuery, pager
 			panic(fmt.Errorf("missing `searchable` tag for field %s", k))
 		}
 	}
+	checkOnlyFakeDeleteRows(schema, query)
 	search := e.GetRedisSearch(schema.searchCacheName)
 	query.hasFakeDelete = schema.hasSearchableFakeDelete
 	totalRows, res := search.search(schema.redisSearchIndex.Name, query, pager, true)
@@ -135,3 +137,9 @@ func redisSearch(e *Engine, schema *tableSchema, query *RedisSearchQuery, pager
 	}
 	return ids, totalRows
 }
+
+func checkOnlyFakeDeleteRows(schema *tableSchema, query *RedisSearchQuery) {
+	if query.onlyFakeDelete && !schema.hasSearchableFakeDelete {
+		panic(fmt.Errorf("only fake deleted rows of entity %s can't be searched, FakeDelete field must be searchable", schema.t.String()))
+	}
+}
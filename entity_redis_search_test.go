@@ -125,6 +125,20 @@ func testEntityRedisSearchIndexer(t *testing.T, redisNamespace, version string)
 	query.WithFakeDeleteRows()
 	total = engine.RedisSearchCount(entity, query)
 	assert.Equal(t, uint64(entityIndexerPage+100), total)
+	query = NewRedisSearchQuery()
+	query.OnlyFakeDeleteRows()
+	ids, total := engine.RedisSearchIds(entity, query, NewPager(1, 10))
+	assert.Equal(t, uint64(1), total)
+	assert.Equal(t, []uint64{10}, ids)
+
+	engine.Restore(e)
+	query = NewRedisSearchQuery()
+	total = engine.RedisSearchCount(entity, query)
+	assert.Equal(t, uint64(entityIndexerPage+100), total)
+	query = NewRedisSearchQuery()
+	query.OnlyFakeDeleteRows()
+	total = engine.RedisSearchCount(entity, query)
+	assert.Equal(t, uint64(0), total)
 }
 
 func TestEntityRedisSearchIndexerNoFakeDelete(t *testing.T) {
@@ -166,6 +180,22 @@ func TestEntityRedisSearchIndexerNoFakeDelete(t *testing.T) {
 	query.WithFakeDeleteRows()
 	total = engine.RedisSearchCount(entity, query)
 	assert.Equal(t, uint64(entityIndexerPage+99), total)
+	query = NewRedisSearchQuery()
+	query.OnlyFakeDeleteRows()
+	assert.PanicsWithError(t, "only fake deleted rows of entity beeorm.redisSearchEntityNoSearchableFakeDelete can't be searched, FakeDelete field must be searchable", func() {
+		engine.RedisSearchCount(entity, query)
+	})
+	assert.PanicsWithError(t, "only fake deleted rows of entity beeorm.redisSearchEntityNoSearchableFakeDelete can't be searched, FakeDelete field must be searchable", func() {
+		engine.RedisSearchAggregate(entity, &RedisSearchAggregate{query: query}, nil)
+	})
+
+	engine.Restore(e)
+	query = NewRedisSearchQuery()
+	total = engine.RedisSearchCount(entity, query)
+	assert.Equal(t, uint64(entityIndexerPage+100), total)
+	query = NewRedisSearchQuery()
+	query.FilterInt("Age", 10)
+	assert.Equal(t, uint64(1), engine.RedisSearchCount(entity, query))
 }
 
 func TestEntityRedisSearch(t *testing.T) {
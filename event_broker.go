@@ -71,6 +71,11 @@ type EventBroker interface {
 	Publish(stream string, body interface{}, meta ...string) (id string)
 	Consumer(group string) EventsConsumer
 	NewFlusher() EventFlusher
+	DeadLetters(stream, start string, count int64) []*DeadLetterEvent
+	DeadLettersCount(stream string) int64
+	GetDeadLetter(stream, id string) *DeadLetterEvent
+	RequeueDeadLetters(stream string, ids ...string) (requeued int)
+	PurgeDeadLetters(stream string, ids ...string) (purged int64)
 }
 
 type EventFlusher interface {
@@ -172,6 +177,7 @@ func (eb *eventBroker) Consumer(group string) EventsConsumer {
 		redis:             eb.engine.GetRedis(redisPool),
 		streams:           streams,
 		group:             group,
+		maxDeliveries:     eb.engine.registry.redisStreamMaxDeliveries[group],
 		lockTTL:           time.Second * 90,
 		lockTick:          time.Minute}
 }
@@ -187,6 +193,7 @@ type eventsConsumer struct {
 	redis           *RedisCache
 	streams         []string
 	group           string
+	maxDeliveries   int64
 	lockTTL         time.Duration
 	lockTick        time.Duration
 	garbageLastTick int64
@@ -211,11 +218,8 @@ func (r *eventsConsumer) consume(ctx context.Context, name string, count int, ha
 	if !has {
 		return false
 	}
-	timer := time.NewTimer(r.lockTick)
-	defer func() {
-		lock.Release()
-		timer.Stop()
-	}()
+	defer lock.Release()
+	lost := lock.keepAlive(ctx, r.lockTick)
 	r.garbage()
 
 	for _, stream := range r.streams {
@@ -238,11 +242,8 @@ func (r *eventsConsumer) consume(ctx context.Context, name string, count int, ha
 		select {
 		case <-ctx.Done():
 			return true
-		case <-timer.C:
-			if !lock.Refresh(r.lockTTL) {
-				return false
-			}
-			timer.Reset(r.lockTick)
+		case <-lost:
+			return false
 		default:
 			if r.digest(ctx, attributes) {
 				return true
@@ -307,6 +308,12 @@ func (r *eventsConsumer) digestKeys(ctx context.Context, attributes *consumeAttr
 		}
 		return true
 	}
+	if attributes.Pending && r.maxDeliveries > 0 {
+		results, totalMessages = r.deadLetter(attributes.Name, results)
+		if totalMessages == 0 {
+			return false
+		}
+	}
 	events := make([]Event, totalMessages)
 	i = 0
 	for _, row := range results {
@@ -315,7 +322,7 @@ func (r *eventsConsumer) digestKeys(ctx context.Context, attributes *consumeAttr
 			i++
 		}
 	}
-	attributes.Handler(events)
+	r.handle(attributes.Handler, events)
 	var toAck map[string][]string
 	allDeleted := true
 	for _, ev := range events {
@@ -338,6 +345,18 @@ func (r *eventsConsumer) digestKeys(ctx context.Context, attributes *consumeAttr
 	return false
 }
 
+func (r *eventsConsumer) handle(handler EventConsumerHandler, events []Event) {
+	if r.maxDeliveries > 0 {
+		defer func() {
+			if rec := recover(); rec != nil {
+				r.recordError(events, rec)
+				panic(rec)
+			}
+		}()
+	}
+	handler(events)
+}
+
 func (r *eventsConsumer) Claim(from, to int) {
 	for _, stream := range r.streams {
 		start := "-"
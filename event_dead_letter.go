@@ -0,0 +1,203 @@
+package beeorm
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/go-redis/redis/v8"
+	"github.com/shamaton/msgpack"
+)
+
+const deadLetterStreamSuffix = ":dead"
+const deadLetterErrorsSuffix = ":dead:errors"
+const deadLetterErrorsTTL = time.Hour * 24 * 7
+const deadLetterFieldPrefix = "_dead_"
+
+type DeadLetterEvent struct {
+	ID         string
+	Stream     string
+	Group      string
+	OriginalID string
+	Deliveries int64
+	Error      string
+	DeadAt     time.Time
+	values     map[string]interface{}
+}
+
+func (d *DeadLetterEvent) Tag(key string) (value string) {
+	val, has := d.values[key]
+	if has {
+		return val.(string)
+	}
+	return ""
+}
+
+func (d *DeadLetterEvent) Unserialize(value interface{}) {
+	val := d.values["s"]
+	err := msgpack.Unmarshal([]byte(val.(string)), &value)
+	checkError(err)
+}
+
+func newDeadLetterEvent(stream string, message redis.XMessage) *DeadLetterEvent {
+	dead := &DeadLetterEvent{ID: message.ID, Stream: stream, values: make(map[string]interface{})}
+	for key, value := range message.Values {
+		if !strings.HasPrefix(key, deadLetterFieldPrefix) {
+			dead.values[key] = value
+			continue
+		}
+		asString, _ := value.(string)
+		switch strings.TrimPrefix(key, deadLetterFieldPrefix) {
+		case "group":
+			dead.Group = asString
+		case "id":
+			dead.OriginalID = asString
+		case "deliveries":
+			dead.Deliveries, _ = strconv.ParseInt(asString, 10, 64)
+		case "error":
+			dead.Error = asString
+		case "time":
+			unix, _ := strconv.ParseInt(asString, 10, 64)
+			dead.DeadAt = time.Unix(unix, 0)
+		}
+	}
+	return dead
+}
+
+// recordError keeps error of panicking handler so it can be attached to dead letter later
+func (r *eventsConsumer) recordError(events []Event, reason interface{}) {
+	p := r.redis.PipeLine()
+	keys := make(map[string]bool)
+	for _, ev := range events {
+		ev := ev.(*event)
+		if ev.ack {
+			continue
+		}
+		key := ev.stream + deadLetterErrorsSuffix
+		p.HSet(key, r.group+":"+ev.message.ID, fmt.Sprintf("%v", reason))
+		keys[key] = true
+	}
+	for key := range keys {
+		p.Expire(key, deadLetterErrorsTTL)
+	}
+	p.Exec()
+}
+
+// deadLetter moves pending events delivered too many times to dead letter stream
+func (r *eventsConsumer) deadLetter(consumer string, results []redis.XStream) ([]redis.XStream, int) {
+	total := 0
+	for i, row := range results {
+		l := len(row.Messages)
+		if l == 0 {
+			continue
+		}
+		pending := r.redis.XPendingExt(&redis.XPendingExtArgs{Stream: row.Stream, Group: r.group, Consumer: consumer,
+			Start: row.Messages[0].ID, End: row.Messages[l-1].ID, Count: int64(l)})
+		deliveries := make(map[string]int64, len(pending))
+		for _, p := range pending {
+			deliveries[p.ID] = p.RetryCount
+		}
+		alive := make([]redis.XMessage, 0, l)
+		dead := make([]redis.XMessage, 0)
+		for _, message := range row.Messages {
+			if deliveries[message.ID] > r.maxDeliveries {
+				dead = append(dead, message)
+			} else {
+				alive = append(alive, message)
+			}
+		}
+		if len(dead) > 0 {
+			r.moveToDeadLetter(row.Stream, dead, deliveries)
+		}
+		results[i].Messages = alive
+		total += len(alive)
+	}
+	return results, total
+}
+
+func (r *eventsConsumer) moveToDeadLetter(stream string, messages []redis.XMessage, deliveries map[string]int64) {
+	errorsKey := stream + deadLetterErrorsSuffix
+	fields := make([]string, len(messages))
+	ids := make([]string, len(messages))
+	for i, message := range messages {
+		fields[i] = r.group + ":" + message.ID
+		ids[i] = message.ID
+	}
+	errors := r.redis.HMGet(errorsKey, fields...)
+	now := strconv.FormatInt(time.Now().Unix(), 10)
+	p := r.redis.PipeLine()
+	for i, message := range messages {
+		values := make([]string, 0, len(message.Values)*2+10)
+		for key, value := range message.Values {
+			values = append(values, key, fmt.Sprintf("%v", value))
+		}
+		reason := "max deliveries exceeded"
+		if asString, has := errors[fields[i]].(string); has {
+			reason = asString
+		}
+		values = append(values, deadLetterFieldPrefix+"group", r.group, deadLetterFieldPrefix+"id", message.ID,
+			deadLetterFieldPrefix+"deliveries", strconv.FormatInt(deliveries[message.ID], 10),
+			deadLetterFieldPrefix+"error", reason, deadLetterFieldPrefix+"time", now)
+		p.XAdd(stream+deadLetterStreamSuffix, values)
+	}
+	p.HDel(errorsKey, fields...)
+	p.Exec()
+	r.redis.XAck(stream, r.group, ids...)
+}
+
+// DeadLetters returns up to count dead letters of stream starting from id, "-" is used for first one
+func (eb *eventBroker) DeadLetters(stream, start string, count int64) []*DeadLetterEvent {
+	if start == "" {
+		start = "-"
+	}
+	messages := getRedisForStream(eb.engine, stream).XRange(stream+deadLetterStreamSuffix, start, "+", count)
+	events := make([]*DeadLetterEvent, len(messages))
+	for i, message := range messages {
+		events[i] = newDeadLetterEvent(stream, message)
+	}
+	return events
+}
+
+func (eb *eventBroker) DeadLettersCount(stream string) int64 {
+	return getRedisForStream(eb.engine, stream).XLen(stream + deadLetterStreamSuffix)
+}
+
+func (eb *eventBroker) GetDeadLetter(stream, id string) *DeadLetterEvent {
+	messages := getRedisForStream(eb.engine, stream).XRange(stream+deadLetterStreamSuffix, id, id, 1)
+	if len(messages) == 0 {
+		return nil
+	}
+	return newDeadLetterEvent(stream, messages[0])
+}
+
+// RequeueDeadLetters publishes dead letters again to original stream
+func (eb *eventBroker) RequeueDeadLetters(stream string, ids ...string) (requeued int) {
+	r := getRedisForStream(eb.engine, stream)
+	deadStream := stream + deadLetterStreamSuffix
+	for _, id := range ids {
+		messages := r.XRange(deadStream, id, id, 1)
+		if len(messages) == 0 {
+			continue
+		}
+		values := make([]string, 0, len(messages[0].Values)*2)
+		for key, value := range messages[0].Values {
+			if !strings.HasPrefix(key, deadLetterFieldPrefix) {
+				values = append(values, key, fmt.Sprintf("%v", value))
+			}
+		}
+		r.xAdd(stream, values)
+		r.XDel(deadStream, id)
+		requeued++
+	}
+	return requeued
+}
+
+// PurgeDeadLetters removes all dead letters of stream when ids are not provided
+func (eb *eventBroker) PurgeDeadLetters(stream string, ids ...string) (purged int64) {
+	r := getRedisForStream(eb.engine, stream)
+	if len(ids) == 0 {
+		return r.XTrim(stream+deadLetterStreamSuffix, 0)
+	}
+	return r.XDel(stream+deadLetterStreamSuffix, ids...)
+}
@@ -0,0 +1,103 @@
+package beeorm
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRedisStreamDeadLetter(t *testing.T) {
+	registry := &Registry{}
+	registry.RegisterRedis("localhost:6382", "", 15)
+	registry.RegisterRedisStream("test-stream", "default", []string{"test-group"})
+	registry.RegisterRedisStreamRetryPolicy("test-group", 2)
+	validatedRegistry, def, err := registry.Validate()
+	assert.NoError(t, err)
+	defer def()
+	engine := validatedRegistry.CreateEngine()
+	engine.GetRedis().FlushDB()
+	broker := engine.GetEventBroker()
+	type testEvent struct {
+		Name string
+	}
+	broker.Publish("test-stream", testEvent{"poison"}, "tag", "value")
+	broker.Publish("test-stream", testEvent{"valid"})
+
+	consumer := broker.Consumer("test-group")
+	consumer.(*eventsConsumer).blockTime = time.Millisecond
+	consumer.DisableLoop()
+	handler := func(events []Event) {
+		for _, ev := range events {
+			e := &testEvent{}
+			ev.Unserialize(e)
+			if e.Name == "poison" {
+				panic("invalid event")
+			}
+			ev.Ack()
+		}
+	}
+	for i := 0; i < 2; i++ {
+		assert.PanicsWithValue(t, "invalid event", func() {
+			consumer.Consume(context.Background(), 1, handler)
+		})
+	}
+	assert.Equal(t, int64(0), broker.DeadLettersCount("test-stream"))
+
+	consumed := 0
+	consumer.Consume(context.Background(), 1, func(events []Event) {
+		consumed += len(events)
+		handler(events)
+	})
+	assert.Equal(t, 1, consumed)
+	assert.Equal(t, int64(1), broker.DeadLettersCount("test-stream"))
+
+	deadLetters := broker.DeadLetters("test-stream", "-", 10)
+	assert.Len(t, deadLetters, 1)
+	dead := deadLetters[0]
+	assert.Equal(t, "test-stream", dead.Stream)
+	assert.Equal(t, "test-group", dead.Group)
+	assert.Equal(t, int64(3), dead.Deliveries)
+	assert.Equal(t, "invalid event", dead.Error)
+	assert.Equal(t, "value", dead.Tag("tag"))
+	assert.NotEmpty(t, dead.OriginalID)
+	assert.WithinDuration(t, time.Now(), dead.DeadAt, time.Minute)
+	e := &testEvent{}
+	dead.Unserialize(e)
+	assert.Equal(t, "poison", e.Name)
+	assert.Equal(t, dead.OriginalID, broker.GetDeadLetter("test-stream", dead.ID).OriginalID)
+	assert.Nil(t, broker.GetDeadLetter("test-stream", "1-0"))
+	pending := engine.GetRedis().XPending("test-stream", "test-group")
+	assert.Equal(t, int64(0), pending.Count)
+
+	assert.Equal(t, 1, broker.RequeueDeadLetters("test-stream", dead.ID, "1-0"))
+	assert.Equal(t, int64(0), broker.DeadLettersCount("test-stream"))
+	consumer.Consume(context.Background(), 1, func(events []Event) {
+		assert.Len(t, events, 1)
+		assert.Equal(t, "value", events[0].Tag("tag"))
+	})
+
+	broker.Publish("test-stream", testEvent{"poison"})
+	broker.Publish("test-stream", testEvent{"poison"})
+	for i := 0; i < 2; i++ {
+		assert.Panics(t, func() {
+			consumer.Consume(context.Background(), 10, handler)
+		})
+	}
+	consumer.Consume(context.Background(), 10, handler)
+	deadLetters = broker.DeadLetters("test-stream", "", 10)
+	assert.Len(t, deadLetters, 2)
+	assert.Equal(t, int64(1), broker.PurgeDeadLetters("test-stream", deadLetters[0].ID))
+	assert.Equal(t, int64(1), broker.PurgeDeadLetters("test-stream"))
+	assert.Equal(t, int64(0), broker.DeadLettersCount("test-stream"))
+
+	registry = &Registry{}
+	registry.RegisterRedis("localhost:6382", "", 15)
+	registry.RegisterRedisStreamRetryPolicy("missing-group", 2)
+	_, _, err = registry.Validate()
+	assert.EqualError(t, err, "retry policy uses unregistered stream group missing-group")
+	assert.PanicsWithError(t, "max deliveries must be higher than zero", func() {
+		registry.RegisterRedisStreamRetryPolicy("test-group", 0)
+	})
+}
@@ -0,0 +1,142 @@
+package beeorm
+
+// FlushPlan uses ID 0 for inserted entities without ID, cache keys and RediSearch updates that depend on it are not included
+type FlushPlan struct {
+	Queries           []*PlannedQuery
+	RedisDeletes      map[string][]string
+	RedisHSets        map[string]map[string][]interface{}
+	CachedListUpdates map[string][]*PlannedCachedListUpdate
+	LocalCacheSets    map[string]map[string]interface{}
+	LocalCacheDeletes map[string][]string
+	RedisSearch       []*PlannedRedisSearchUpdate
+	DirtyEvents       []*PlannedDirtyEvent
+	LogEntries        []*LogQueueValue
+}
+
+type PlannedQuery struct {
+	Pool  string
+	SQL   string
+	Binds []Bind
+}
+
+type PlannedCachedListUpdate struct {
+	Key string
+	ID  uint64
+	Add bool
+	Max int
+}
+
+type PlannedRedisSearchUpdate struct {
+	Index  string
+	ID     uint64
+	Delete bool
+	Values []interface{}
+}
+
+type PlannedDirtyEvent struct {
+	Stream string
+	Entity string
+	ID     uint64
+	Action string
+}
+
+type planEntityState struct {
+	binary []byte
+	inDB   bool
+	loaded bool
+	id     uint64
+}
+
+func (f *flusher) Plan() *FlushPlan {
+	plan := &FlushPlan{}
+	if f.trackedEntitiesCounter == 0 {
+		return plan
+	}
+	f.plan = plan
+	f.planStates = make(map[Entity]*planEntityState)
+	f.redisFlusher = &redisFlusher{engine: f.engine}
+	defer func() {
+		for entity, state := range f.planStates {
+			orm := entity.getORM()
+			orm.binary = state.binary
+			orm.inDB = state.inDB
+			orm.loaded = state.loaded
+			orm.idElem.SetUint(state.id)
+		}
+		f.plan = nil
+		f.planStates = nil
+		f.redisFlusher = nil
+		f.lazyMap = nil
+		f.clear()
+	}()
+	f.flush(true, false, false, f.trackedEntities...)
+	return plan
+}
+
+func (f *flusher) planSnapshot(entity Entity) {
+	_, has := f.planStates[entity]
+	if has {
+		return
+	}
+	orm := entity.getORM()
+	state := &planEntityState{inDB: orm.inDB, loaded: orm.loaded, id: orm.GetID()}
+	if orm.binary != nil {
+		state.binary = orm.copyBinary()
+	}
+	f.planStates[entity] = state
+}
+
+func (p *FlushPlan) addQuery(pool, sql string, binds ...Bind) {
+	p.Queries = append(p.Queries, &PlannedQuery{Pool: pool, SQL: sql, Binds: binds})
+}
+
+func (p *FlushPlan) addRedisSearch(schema *tableSchema, id uint64, delete bool, values []interface{}) {
+	p.RedisSearch = append(p.RedisSearch, &PlannedRedisSearchUpdate{Index: schema.redisSearchIndex.Name, ID: id, Delete: delete, Values: values})
+}
+
+func (p *FlushPlan) fillLocalCache(sets map[string][]interface{}, deletes map[string][]string) {
+	for cacheCode, keys := range deletes {
+		if p.LocalCacheDeletes == nil {
+			p.LocalCacheDeletes = make(map[string][]string)
+		}
+		p.LocalCacheDeletes[cacheCode] = append(p.LocalCacheDeletes[cacheCode], keys...)
+	}
+	for cacheCode, pairs := range sets {
+		if p.LocalCacheSets == nil {
+			p.LocalCacheSets = make(map[string]map[string]interface{})
+		}
+		if p.LocalCacheSets[cacheCode] == nil {
+			p.LocalCacheSets[cacheCode] = make(map[string]interface{})
+		}
+		for i := 0; i < len(pairs); i += 2 {
+			p.LocalCacheSets[cacheCode][pairs[i].(string)] = unwrapLocalCacheEntry(pairs[i+1])
+		}
+	}
+}
+
+func (p *FlushPlan) fillRedis(redisFlusher *redisFlusher) {
+	for pool, commands := range redisFlusher.pipelines {
+		if len(commands.deletes) > 0 {
+			if p.RedisDeletes == nil {
+				p.RedisDeletes = make(map[string][]string)
+			}
+			p.RedisDeletes[pool] = append(p.RedisDeletes[pool], commands.deletes...)
+		}
+		for key, values := range commands.hSets {
+			if p.RedisHSets == nil {
+				p.RedisHSets = make(map[string]map[string][]interface{})
+			}
+			if p.RedisHSets[pool] == nil {
+				p.RedisHSets[pool] = make(map[string][]interface{})
+			}
+			p.RedisHSets[pool][key] = append(p.RedisHSets[pool][key], values...)
+		}
+		for _, list := range commands.lists {
+			if p.CachedListUpdates == nil {
+				p.CachedListUpdates = make(map[string][]*PlannedCachedListUpdate)
+			}
+			p.CachedListUpdates[pool] = append(p.CachedListUpdates[pool], &PlannedCachedListUpdate{Key: list.key, ID: list.id, Add: list.add, Max: list.max})
+		}
+	}
+	redisFlusher.pipelines = nil
+}
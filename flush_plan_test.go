@@ -0,0 +1,107 @@
+package beeorm
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type flushPlanEntity struct {
+	ORM       `orm:"localCache;redisCache;log;dirty=plan_changed"`
+	ID        uint
+	Name      string       `orm:"index=NameIndex"`
+	IndexName *CachedQuery `query:":Name = ?"`
+}
+
+func TestFlushPlan(t *testing.T) {
+	var entity *flushPlanEntity
+	registry := &Registry{}
+	registry.RegisterRedisStream("plan_changed", "default", []string{"test-group"})
+	engine, def := prepareTables(t, registry, 5, "", "2.0", entity)
+	defer def()
+
+	entity = &flushPlanEntity{Name: "John"}
+	flusher := engine.NewFlusher().Track(entity)
+	plan := flusher.Plan()
+	assert.Len(t, plan.Queries, 1)
+	assert.Equal(t, "default", plan.Queries[0].Pool)
+	assert.Equal(t, "INSERT INTO flushPlanEntity(`Name`) VALUES ('John')", plan.Queries[0].SQL)
+	assert.Equal(t, []Bind{{"Name": "John"}}, plan.Queries[0].Binds)
+	assert.Len(t, plan.DirtyEvents, 1)
+	assert.Equal(t, "plan_changed", plan.DirtyEvents[0].Stream)
+	assert.Equal(t, "i", plan.DirtyEvents[0].Action)
+	assert.Len(t, plan.LogEntries, 1)
+	assert.Equal(t, "John", plan.LogEntries[0].Changes["Name"])
+	assert.NotEmpty(t, plan.RedisDeletes["default"])
+	assert.NotEmpty(t, plan.LocalCacheDeletes["default"])
+	assert.Equal(t, uint(0), entity.ID)
+	assert.False(t, entity.IsLoaded())
+	assert.Equal(t, 0, engine.SearchWithCount(NewWhere("1"), nil, &[]*flushPlanEntity{}))
+
+	engine.Flush(entity)
+	assert.Equal(t, uint(1), entity.ID)
+
+	entity.Name = "Tom"
+	plan = engine.NewFlusher().Track(entity).Plan()
+	assert.Len(t, plan.Queries, 1)
+	assert.Equal(t, "UPDATE flushPlanEntity SET `Name`='Tom' WHERE `ID` = 1", plan.Queries[0].SQL)
+	assert.Len(t, plan.LocalCacheSets["default"], 1)
+	assert.Contains(t, plan.RedisDeletes["default"], engine.GetRegistry().GetTableSchemaForEntity(entity).(*tableSchema).getCacheKey(1))
+	assert.Equal(t, "u", plan.DirtyEvents[0].Action)
+	assert.Equal(t, "Tom", plan.LogEntries[0].Changes["Name"])
+	assert.Equal(t, "John", plan.LogEntries[0].Before["Name"])
+
+	loaded := &flushPlanEntity{}
+	engine.LoadByID(1, loaded)
+	assert.Equal(t, "John", loaded.Name)
+	assert.True(t, entity.IsDirty())
+
+	plan = engine.NewFlusher().Delete(loaded).Plan()
+	assert.Len(t, plan.Queries, 1)
+	assert.Equal(t, "DELETE FROM `flushPlanEntity` WHERE `ID` IN (1)", plan.Queries[0].SQL)
+	assert.Equal(t, "d", plan.DirtyEvents[0].Action)
+	assert.True(t, engine.LoadByID(1, &flushPlanEntity{}))
+}
+
+type flushPlanListEntity struct {
+	ORM      `orm:"localCache;redisCache;redisSearch=search"`
+	ID       uint
+	Age      uint16       `orm:"searchable"`
+	IndexAll *CachedQuery `query:""`
+	IndexAge *CachedQuery `query:":Age = ? ORDER BY :ID"`
+}
+
+func TestFlushPlanCachedLists(t *testing.T) {
+	var entity *flushPlanListEntity
+	engine, def := prepareTables(t, &Registry{}, 5, "", "2.0", entity)
+	defer def()
+	schema := engine.GetRegistry().GetTableSchemaForEntity(entity).(*tableSchema)
+	keyAll := getCacheKeySearch(schema, "IndexAll")
+	keyAge := getCacheKeySearch(schema, "IndexAge", 10)
+
+	plan := engine.NewFlusher().Track(&flushPlanListEntity{Age: 10}).Plan()
+	assert.NotContains(t, plan.RedisDeletes["default"], schema.getCacheKey(0))
+	assert.NotContains(t, plan.RedisDeletes["default"], keyAll)
+	assert.NotContains(t, plan.RedisDeletes["default"], keyAge)
+	assert.NotContains(t, plan.LocalCacheSets["default"], schema.getCacheKey(0))
+	assert.Len(t, plan.RedisSearch, 0)
+	assert.ElementsMatch(t, []*PlannedCachedListUpdate{{Key: keyAll, ID: 0, Add: true, Max: 50000},
+		{Key: keyAge, ID: 0, Add: true, Max: 50000}}, plan.CachedListUpdates["default"])
+
+	plan = engine.NewFlusher().Track(&flushPlanListEntity{ID: 10, Age: 10}).Plan()
+	assert.Contains(t, plan.RedisDeletes["default"], schema.getCacheKey(10))
+	assert.NotContains(t, plan.RedisDeletes["default"], keyAll)
+	assert.Contains(t, plan.LocalCacheSets["default"], schema.getCacheKey(10))
+	assert.Len(t, plan.RedisSearch, 1)
+	assert.Equal(t, uint64(10), plan.RedisSearch[0].ID)
+	assert.ElementsMatch(t, []*PlannedCachedListUpdate{{Key: keyAll, ID: 10, Add: true, Max: 50000},
+		{Key: keyAge, ID: 10, Add: true, Max: 50000}}, plan.CachedListUpdates["default"])
+
+	entity = &flushPlanListEntity{Age: 10}
+	engine.Flush(entity)
+	plan = engine.NewFlusher().Delete(entity).Plan()
+	assert.NotContains(t, plan.RedisDeletes["default"], keyAll)
+	assert.ElementsMatch(t, []*PlannedCachedListUpdate{{Key: keyAll, ID: 1, Max: 50000},
+		{Key: keyAge, ID: 1, Max: 50000}}, plan.CachedListUpdates["default"])
+	assert.Equal(t, 1, engine.CachedSearchCount(entity, "IndexAll"))
+}
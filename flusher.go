@@ -37,6 +37,7 @@ type Flusher interface {
 	Clear()
 	Delete(entity ...Entity) Flusher
 	ForceDelete(entity ...Entity) Flusher
+	Plan() *FlushPlan
 }
 
 type flusher struct {
@@ -49,8 +50,11 @@ type flusher struct {
 	lazyMap                map[string]interface{}
 	localCacheDeletes      map[string][]string
 	localCacheSets         map[string][]interface{}
+	invalidations          localCacheInvalidations
 	stringBuilder          strings.Builder
 	serializer             *serializer
+	plan                   *FlushPlan
+	planStates             map[Entity]*planEntityState
 }
 
 func (f *flusher) Track(entity ...Entity) Flusher {
@@ -172,6 +176,7 @@ func (f *flusher) flushTrackedEntities(lazy bool, transaction bool) {
 		}
 	}
 	executed = true
+	f.engine.publishLocalCacheInvalidations(f.invalidations)
 	f.clear()
 }
 
@@ -226,6 +231,9 @@ func (f *flusher) flush(root bool, lazy bool, transaction bool, entities ...Enti
 
 	for _, entity := range entities {
 		initIfNeeded(f.engine.registry, entity)
+		if f.plan != nil {
+			f.planSnapshot(entity)
+		}
 		schema := entity.getORM().tableSchema
 		if !transaction && schema.GetMysql(f.engine).inTransaction {
 			transaction = true
@@ -295,6 +303,10 @@ func (f *flusher) flush(root bool, lazy bool, transaction bool, entities ...Enti
 }
 
 func (f *flusher) updateRedisCache(root bool, lazy bool, transaction bool) {
+	if f.plan != nil {
+		f.plan.fillRedis(f.getRedisFlusher())
+		return
+	}
 	if lazy {
 		lazyMap := f.getLazyMap()
 		deletesRedisCache, has := lazyMap["cr"].(map[string][]string)
@@ -309,6 +321,10 @@ func (f *flusher) updateRedisCache(root bool, lazy bool, transaction bool) {
 		}
 	} else if transaction {
 		f.engine.afterCommitRedisFlusher = f.getRedisFlusher()
+		for cacheCode, keys := range f.invalidations {
+			f.engine.afterCommitInvalidations.add(cacheCode, keys...)
+		}
+		f.invalidations = nil
 	}
 	if len(f.lazyMap) > 0 {
 		f.getRedisFlusher().Publish(lazyChannelName, f.lazyMap)
@@ -320,6 +336,10 @@ func (f *flusher) updateRedisCache(root bool, lazy bool, transaction bool) {
 }
 
 func (f *flusher) updateLocalCache(lazy bool, transaction bool) {
+	if f.plan != nil {
+		f.plan.fillLocalCache(f.localCacheSets, f.localCacheDeletes)
+		return
+	}
 	if f.localCacheDeletes != nil {
 		if lazy {
 			lazyMap := f.getLazyMap()
@@ -327,6 +347,7 @@ func (f *flusher) updateLocalCache(lazy bool, transaction bool) {
 		} else {
 			for cacheCode, allKeys := range f.localCacheDeletes {
 				f.engine.GetLocalCache(cacheCode).Remove(allKeys...)
+				f.invalidations.add(cacheCode, allKeys...)
 			}
 		}
 	}
@@ -334,6 +355,7 @@ func (f *flusher) updateLocalCache(lazy bool, transaction bool) {
 		cache := f.engine.GetLocalCache(cacheCode)
 		if !transaction {
 			cache.MSet(keys...)
+			f.invalidations.addSets(cacheCode, keys)
 		} else {
 			if f.engine.afterCommitLocalCacheSets == nil {
 				f.engine.afterCommitLocalCacheSets = make(map[string][]interface{})
@@ -375,7 +397,11 @@ func (f *flusher) executeDeletes(lazy bool) {
 			bindBuilder, _ := orm.buildDirtyBind(f.getSerializer())
 			if !lazy {
 				if !queryExecuted {
-					_ = db.Exec(deleteSQL)
+					if f.plan != nil {
+						f.plan.addQuery(db.GetPoolConfig().GetCode(), deleteSQL)
+					} else {
+						_ = db.Exec(deleteSQL)
+					}
 					queryExecuted = true
 				}
 				f.addDirtyQueues(bindBuilder.current, schema, id, "d", lazy)
@@ -394,17 +420,21 @@ func (f *flusher) executeDeletes(lazy bool) {
 				cacheKey := schema.getCacheKey(id)
 				keys := f.getCacheQueriesKeys(schema, bindBuilder.bind, bindBuilder.current, true, true)
 				if hasLocalCache {
-					f.addLocalCacheSet(localCache.config.GetCode(), cacheKey, cacheNilValue)
+					f.addLocalCacheSet(localCache.config.GetCode(), cacheKey, newLocalCacheEntry(cacheNilValue, schema.localCacheTTL))
 					f.addLocalCacheDeletes(localCache.config.GetCode(), keys...)
 				}
 				if hasRedis {
 					f.getRedisFlusher().Del(redisCache.config.GetCode(), cacheKey)
-					f.getRedisFlusher().Del(redisCache.config.GetCode(), keys...)
+					lists := f.getCachedListUpdates(schema, bindBuilder.bind, bindBuilder.current, id, false, true, lazy)
+					f.delCachedQueries(redisCache.config.GetCode(), keys, lists)
 				}
 			}
 			if schema.hasSearchCache {
 				key := schema.redisSearchPrefix + strconv.FormatUint(id, 10)
 				f.getRedisFlusher().Del(schema.searchCacheName, key)
+				if f.plan != nil {
+					f.plan.addRedisSearch(schema, id, true, nil)
+				}
 			}
 		}
 		if lazy {
@@ -414,6 +444,9 @@ func (f *flusher) executeDeletes(lazy bool) {
 }
 
 func (f *flusher) executeUpdates() {
+	if f.plan != nil {
+		return
+	}
 	for pool, queries := range f.updateSQLs {
 		db := f.engine.GetMysql(pool)
 		l := len(queries)
@@ -476,6 +509,15 @@ func (f *flusher) executeInserts(flushPackage *flushPackage, lazy bool) {
 				}
 			}
 			f.fillLazyQuery(db.GetPoolConfig().GetCode(), sql, logEvents, dirtyEvents)
+		} else if f.plan != nil {
+			f.plan.addQuery(db.GetPoolConfig().GetCode(), sql, flushPackage.insertBinds[typeOf]...)
+			for key, entity := range flushPackage.insertReflectValues[typeOf] {
+				orm := entity.getORM()
+				orm.inDB = true
+				orm.loaded = true
+				orm.serialize(f.getSerializer())
+				f.updateCacheForInserted(entity, lazy, entity.GetID(), flushPackage.insertBinds[typeOf][key])
+			}
 		} else {
 			res := db.Exec(sql)
 			id := res.LastInsertId()
@@ -528,6 +570,9 @@ func (f *flusher) flushDelete(t reflect.Type, currentID uint64, entity Entity) {
 }
 
 func (f *flusher) startTransaction() {
+	if f.plan != nil {
+		return
+	}
 	dbPools := make(map[string]*DB)
 	for _, entity := range f.trackedEntities {
 		db := entity.getORM().tableSchema.GetMysql(f.engine)
@@ -543,6 +588,9 @@ func (f *flusher) flushReferences(flushPackage *flushPackage, lazy bool, transac
 		if lazy {
 			panic(fmt.Errorf("lazy flush for unsaved references is not supported"))
 		}
+		if f.plan != nil {
+			panic(fmt.Errorf("flush plan for unsaved references is not supported"))
+		}
 		if !transaction {
 			f.startTransaction()
 		}
@@ -605,6 +653,9 @@ func (f *flusher) flushUpdate(entity Entity, bindBuilder *bindBuilder, currentID
 			f.updateSQLs = make(map[string][]string)
 		}
 		f.updateSQLs[schema.mysqlPoolName] = append(f.updateSQLs[schema.mysqlPoolName], sql)
+		if f.plan != nil {
+			f.plan.addQuery(schema.mysqlPoolName, sql, bindBuilder.bind)
+		}
 		entity.getORM().serialize(f.getSerializer())
 		f.updateCacheAfterUpdate(entity, bindBuilder.bind, bindBuilder.current, schema, currentID, false)
 	}
@@ -654,6 +705,10 @@ func (f *flusher) flushOnDuplicateKey(lazy bool, bindBuilder *bindBuilder, schem
 	sql := f.stringBuilder.String()
 	f.stringBuilder.Reset()
 	db := schema.GetMysql(f.engine)
+	if f.plan != nil {
+		f.plan.addQuery(db.GetPoolConfig().GetCode(), sql, bindBuilder.bind)
+		return true
+	}
 	result := db.Exec(sql)
 	affected := result.RowsAffected()
 	if affected > 0 {
@@ -732,23 +787,29 @@ func (f *flusher) updateCacheForInserted(entity Entity, lazy bool, id uint64, bi
 		localCache = f.engine.GetLocalCache(requestCacheKey)
 	}
 	redisCache, hasRedis := schema.GetRedisCache(f.engine)
+	// ID of planned insert is not known yet
+	pendingID := f.plan != nil && id == 0
 	if hasLocalCache || hasRedis {
 		cacheKey := schema.getCacheKey(id)
 		keys := f.getCacheQueriesKeys(schema, bind, nil, false, true)
 		if hasLocalCache {
-			if !lazy {
-				f.addLocalCacheSet(localCache.config.GetCode(), cacheKey, entity.getORM().copyBinary())
-			} else {
+			if lazy {
 				f.addLocalCacheDeletes(localCache.config.GetCode(), schema.getCacheKey(id))
+			} else if !pendingID {
+				f.addLocalCacheSet(localCache.config.GetCode(), cacheKey, newLocalCacheEntry(entity.getORM().copyBinary(), schema.localCacheTTL))
 			}
 			f.addLocalCacheDeletes(localCache.config.GetCode(), keys...)
 		}
 		if hasRedis {
-			f.getRedisFlusher().Del(redisCache.config.GetCode(), cacheKey)
-			f.getRedisFlusher().Del(redisCache.config.GetCode(), keys...)
+			if !pendingID {
+				f.getRedisFlusher().Del(redisCache.config.GetCode(), cacheKey)
+			}
+			f.delCachedQueries(redisCache.config.GetCode(), keys, f.getCachedListUpdates(schema, bind, nil, id, true, false, lazy))
 		}
 	}
-	f.fillRedisSearchFromBind(schema, bind, id, true)
+	if !pendingID {
+		f.fillRedisSearchFromBind(schema, bind, id, true)
+	}
 	return f.addToLogQueue(schema, id, nil, bind, entity.getORM().logMeta, lazy), f.addDirtyQueues(bind, schema, id, "i", lazy)
 }
 
@@ -781,18 +842,29 @@ func (f *flusher) updateCacheAfterUpdate(entity Entity, bind, current Bind, sche
 		keysOld := f.getCacheQueriesKeys(schema, bind, current, true, false)
 		keysNew := f.getCacheQueriesKeys(schema, bind, current, false, false)
 		if hasLocalCache {
-			f.addLocalCacheSet(localCache.config.GetCode(), cacheKey, entity.getORM().copyBinary())
+			f.addLocalCacheSet(localCache.config.GetCode(), cacheKey, newLocalCacheEntry(entity.getORM().copyBinary(), schema.localCacheTTL))
 			f.addLocalCacheDeletes(localCache.config.GetCode(), keysOld...)
 			f.addLocalCacheDeletes(localCache.config.GetCode(), keysNew...)
 		}
 		if hasRedis {
 			redisFlusher := f.getRedisFlusher()
 			redisFlusher.Del(redisCache.config.GetCode(), cacheKey)
-			redisFlusher.Del(redisCache.config.GetCode(), keysOld...)
-			redisFlusher.Del(redisCache.config.GetCode(), keysNew...)
+			lists := f.getCachedListUpdates(schema, bind, current, currentID, false, false, lazy)
+			f.delCachedQueries(redisCache.config.GetCode(), append(keysOld, keysNew...), lists)
 		}
 	}
-	f.fillRedisSearchFromBind(schema, bind, entity.GetID(), false)
+	if entity.getORM().restore && !schema.hasSearchableFakeDelete {
+		fullBind := make(Bind, len(current)+len(bind))
+		for k, v := range current {
+			fullBind[k] = v
+		}
+		for k, v := range bind {
+			fullBind[k] = v
+		}
+		f.fillRedisSearchFromBind(schema, fullBind, entity.GetID(), true)
+	} else {
+		f.fillRedisSearchFromBind(schema, bind, entity.GetID(), false)
+	}
 	dirtyValue := f.addDirtyQueues(bind, schema, currentID, "u", lazy)
 	if schema.hasLog {
 		return f.addToLogQueue(schema, currentID, current, bind, entity.getORM().logMeta, lazy), dirtyValue
@@ -815,7 +887,9 @@ func (f *flusher) addDirtyQueues(bind Bind, schema *tableSchema, id uint64, acti
 			if key == nil {
 				key = &dirtyEvent{A: action, E: schema.t.String(), I: id}
 			}
-			if !lazy {
+			if f.plan != nil {
+				f.plan.DirtyEvents = append(f.plan.DirtyEvents, &PlannedDirtyEvent{Stream: stream, Entity: key.E, ID: key.I, Action: key.A})
+			} else if !lazy {
 				f.getRedisFlusher().Publish(stream, key)
 			} else {
 				allStreams = append(allStreams, stream)
@@ -846,7 +920,7 @@ func (f *flusher) addToLogQueue(tableSchema *tableSchema, id uint64, before, cha
 		}
 	}
 	val := &LogQueueValue{TableName: tableSchema.logTableName, ID: id,
-		PoolName: tableSchema.logPoolName, Before: before,
+		PoolName: tableSchema.logPoolName, Sink: tableSchema.logSinkCode, Before: before,
 		Changes: changes, Updated: time.Now(), Meta: entityMeta}
 	if val.Meta == nil {
 		val.Meta = f.engine.logMetaData
@@ -855,7 +929,9 @@ func (f *flusher) addToLogQueue(tableSchema *tableSchema, id uint64, before, cha
 			val.Meta[k] = v
 		}
 	}
-	if !lazy {
+	if f.plan != nil {
+		f.plan.LogEntries = append(f.plan.LogEntries, val)
+	} else if !lazy {
 		f.getRedisFlusher().Publish(logChannelName, val)
 	}
 	return val
@@ -866,10 +942,13 @@ func (f *flusher) fillRedisSearchFromBind(schema *tableSchema, bind Bind, id uin
 		values := make([]interface{}, 0)
 		hasChangedField := false
 		if schema.hasFakeDelete {
-			val, has := bind["FakeDelete"]
-			if has && val.(uint64) > 0 {
+			val, has := bind[schema.fakeDeleteColumn]
+			if has && schema.isFakeDeletedValue(val) {
 				if !schema.hasSearchableFakeDelete {
 					f.getRedisFlusher().Del(schema.searchCacheName, schema.redisSearchPrefix+strconv.FormatUint(id, 10))
+					if f.plan != nil {
+						f.plan.addRedisSearch(schema, id, true, nil)
+					}
 				} else {
 					values = append(values, "FakeDelete", "true")
 					hasChangedField = true
@@ -893,6 +972,9 @@ func (f *flusher) fillRedisSearchFromBind(schema *tableSchema, bind Bind, id uin
 		}
 		if hasChangedField {
 			f.getRedisFlusher().HSet(schema.searchCacheName, schema.redisSearchPrefix+strconv.FormatUint(id, 10), values...)
+			if f.plan != nil {
+				f.plan.addRedisSearch(schema, id, false, values)
+			}
 		}
 	}
 }
@@ -901,7 +983,7 @@ func (f *flusher) getCacheQueriesKeys(schema *tableSchema, bind, current Bind, o
 	keys = make([]string, 0)
 	for indexName, definition := range schema.cachedIndexesAll {
 		if !addedDeleted && schema.hasFakeDelete {
-			_, addedDeleted = bind["FakeDelete"]
+			_, addedDeleted = bind[schema.fakeDeleteColumn]
 		}
 		if addedDeleted && len(definition.TrackedFields) == 0 {
 			keys = append(keys, getCacheKeySearch(schema, indexName))
@@ -915,7 +997,7 @@ func (f *flusher) getCacheQueriesKeys(schema *tableSchema, bind, current Bind, o
 					if !has || old {
 						val = current[trackedFieldSub]
 					}
-					if !schema.hasFakeDelete || trackedFieldSub != "FakeDelete" {
+					if !schema.hasFakeDelete || trackedFieldSub != schema.fakeDeleteColumn {
 						attributes = append(attributes, val)
 					}
 				}
@@ -927,6 +1009,87 @@ func (f *flusher) getCacheQueriesKeys(schema *tableSchema, bind, current Bind, o
 	return
 }
 
+func (f *flusher) getCachedListUpdates(schema *tableSchema, bind, current Bind, id uint64, inserted, deleted, lazy bool) []*cachedListUpdate {
+	if lazy {
+		return nil
+	}
+	var lists []*cachedListUpdate
+	for indexName, definition := range schema.cachedIndexes {
+		if !definition.Incremental {
+			continue
+		}
+		if inserted || deleted {
+			values := current
+			if inserted {
+				values = bind
+			}
+			if schema.hasFakeDelete {
+				value, has := values[schema.fakeDeleteColumn]
+				if has && schema.isFakeDeletedValue(value) {
+					continue
+				}
+			}
+			key := getCachedQueryKey(schema, indexName, definition, values, nil)
+			lists = append(lists, &cachedListUpdate{key: key, id: id, add: inserted, max: definition.Max})
+			continue
+		}
+		if schema.hasFakeDelete {
+			value, has := bind[schema.fakeDeleteColumn]
+			if has {
+				if schema.isFakeDeletedValue(value) {
+					key := getCachedQueryKey(schema, indexName, definition, current, nil)
+					lists = append(lists, &cachedListUpdate{key: key, id: id, max: definition.Max})
+				} else {
+					key := getCachedQueryKey(schema, indexName, definition, current, bind)
+					lists = append(lists, &cachedListUpdate{key: key, id: id, add: true, max: definition.Max})
+				}
+				continue
+			}
+		}
+		oldKey := getCachedQueryKey(schema, indexName, definition, current, nil)
+		newKey := getCachedQueryKey(schema, indexName, definition, current, bind)
+		if oldKey != newKey {
+			lists = append(lists, &cachedListUpdate{key: oldKey, id: id, max: definition.Max},
+				&cachedListUpdate{key: newKey, id: id, add: true, max: definition.Max})
+		}
+	}
+	return lists
+}
+
+func (f *flusher) delCachedQueries(redisPool string, keys []string, lists []*cachedListUpdate) {
+	if len(lists) == 0 {
+		f.getRedisFlusher().Del(redisPool, keys...)
+		return
+	}
+	updated := make(map[string]bool, len(lists))
+	for _, list := range lists {
+		updated[list.key] = true
+	}
+	toDelete := make([]string, 0, len(keys))
+	for _, key := range keys {
+		if !updated[key] {
+			toDelete = append(toDelete, key)
+		}
+	}
+	f.getRedisFlusher().Del(redisPool, toDelete...)
+	f.getRedisFlusher().UpdateCachedList(redisPool, lists...)
+}
+
+func getCachedQueryKey(schema *tableSchema, indexName string, definition *cachedQueryDefinition, current, bind Bind) string {
+	attributes := make([]interface{}, 0, len(definition.QueryFields))
+	for _, field := range definition.QueryFields {
+		if schema.hasFakeDelete && field == schema.fakeDeleteColumn {
+			continue
+		}
+		val, has := bind[field]
+		if !has {
+			val = current[field]
+		}
+		attributes = append(attributes, val)
+	}
+	return getCacheKeySearch(schema, indexName, attributes...)
+}
+
 func (f *flusher) addLocalCacheSet(cacheCode string, keys ...interface{}) {
 	if f.localCacheSets == nil {
 		f.localCacheSets = make(map[string][]interface{})
@@ -968,4 +1131,5 @@ func (f *flusher) clear() {
 	f.deleteBinds = nil
 	f.localCacheDeletes = nil
 	f.localCacheSets = nil
+	f.invalidations = nil
 }
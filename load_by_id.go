@@ -22,6 +22,7 @@ func loadByID(serializer *serializer, engine *Engine, id uint64, entity Entity,
 		if hasLocalCache {
 			cacheKey = schema.getCacheKey(id)
 			e, has := localCache.Get(cacheKey)
+			schema.cacheCounters.localLookup(localCache, has)
 			if has {
 				if e == cacheNilValue {
 					return false, schema
@@ -37,44 +38,53 @@ func loadByID(serializer *serializer, engine *Engine, id uint64, entity Entity,
 		if hasRedis {
 			cacheKey = schema.getCacheKey(id)
 			row, has := redisCache.Get(cacheKey)
+			schema.cacheCounters.redisLookup(has)
 			if has {
 				if row == cacheNilValue {
 					if localCache != nil {
-						localCache.Set(cacheKey, cacheNilValue)
+						localCache.SetWithTTL(cacheKey, cacheNilValue, schema.localCacheTTL)
 					}
 					return false, schema
 				}
-				fillFromBinary(serializer, engine.registry, []byte(row), entity)
+				fillFromBinary(serializer, engine.registry, schema.decodeRedisValue([]byte(row)), entity)
 				if len(references) > 0 {
 					warmUpReferences(serializer, engine, schema, orm.value, references, false)
 				}
 				if localCache != nil {
-					localCache.Set(cacheKey, orm.copyBinary())
+					localCache.SetWithTTL(cacheKey, orm.copyBinary(), schema.localCacheTTL)
 				}
 				return true, schema
 			}
 		}
 	}
 
-	found, _, data := searchRow(serializer, false, engine, NewWhere("`ID` = ?", id), entity, nil)
-	if !found {
-		if localCache != nil {
-			localCache.Set(cacheKey, cacheNilValue)
-		}
-		if redisCache != nil {
-			redisCache.Set(cacheKey, cacheNilValue, 60)
+	if cacheKey != "" {
+		binary, leader := engine.registry.singleFlight.do(cacheKey, func() interface{} {
+			return loadByIDFromDB(serializer, engine, id, entity, localCache, redisCache, cacheKey)
+		})
+		if binary == nil {
+			if !leader && localCache != nil {
+				localCache.SetWithTTL(cacheKey, cacheNilValue, schema.localCacheTTL)
+			}
+			return false, schema
 		}
-		return false, schema
-	}
-	if useCache {
-		if localCache != nil {
-			localCache.Set(cacheKey, orm.copyBinary())
+		if !leader {
+			fillFromBinary(serializer, engine.registry, binary.([]byte), entity)
+			if localCache != nil {
+				localCache.SetWithTTL(cacheKey, orm.copyBinary(), schema.localCacheTTL)
+			}
 		}
-		if redisCache != nil {
-			redisCache.Set(cacheKey, orm.binary, 0)
+		if len(references) > 0 {
+			warmUpReferences(serializer, engine, schema, orm.elem, references, false)
 		}
+		return true, schema
 	}
 
+	schema.cacheCounters.db(1)
+	found, _, data := searchRow(serializer, false, engine, NewWhere("`ID` = ?", id), entity, nil)
+	if !found {
+		return false, schema
+	}
 	if len(references) > 0 {
 		warmUpReferences(serializer, engine, schema, orm.elem, references, false)
 	} else {
@@ -83,6 +93,51 @@ func loadByID(serializer *serializer, engine *Engine, id uint64, entity Entity,
 	return true, schema
 }
 
+func loadByIDFromDB(serializer *serializer, engine *Engine, id uint64, entity Entity, localCache *LocalCache, redisCache *RedisCache, cacheKey string) interface{} {
+	orm := entity.getORM()
+	schema := orm.tableSchema
+	if redisCache != nil {
+		lock, enabled := engine.obtainStampedeLock(cacheKey, true)
+		if lock != nil {
+			defer lock.Release()
+		}
+		if enabled {
+			row, has := redisCache.Get(cacheKey)
+			if has {
+				if row == cacheNilValue {
+					if localCache != nil {
+						localCache.SetWithTTL(cacheKey, cacheNilValue, schema.localCacheTTL)
+					}
+					return nil
+				}
+				fillFromBinary(serializer, engine.registry, schema.decodeRedisValue([]byte(row)), entity)
+				if localCache != nil {
+					localCache.SetWithTTL(cacheKey, orm.copyBinary(), schema.localCacheTTL)
+				}
+				return orm.copyBinary()
+			}
+		}
+	}
+	schema.cacheCounters.db(1)
+	found, _, _ := searchRow(serializer, false, engine, NewWhere("`ID` = ?", id), entity, nil)
+	if !found {
+		if localCache != nil {
+			localCache.SetWithTTL(cacheKey, cacheNilValue, schema.localCacheTTL)
+		}
+		if redisCache != nil {
+			redisCache.Set(cacheKey, cacheNilValue, schema.getRedisNilTTL())
+		}
+		return nil
+	}
+	if localCache != nil {
+		localCache.SetWithTTL(cacheKey, orm.copyBinary(), schema.localCacheTTL)
+	}
+	if redisCache != nil {
+		redisCache.Set(cacheKey, schema.encodeRedisValue(orm.binary), schema.redisCacheTTL)
+	}
+	return orm.copyBinary()
+}
+
 func initIfNeeded(registry *validatedRegistry, entity Entity) *ORM {
 	orm := entity.getORM()
 	if !orm.initialised {
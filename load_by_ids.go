@@ -3,6 +3,7 @@ package beeorm
 import (
 	"fmt"
 	"reflect"
+	"sort"
 	"strconv"
 	"strings"
 )
@@ -60,6 +61,13 @@ func tryByIDs(serializer *serializer, engine *Engine, ids []uint64, entities ref
 			localCache, _ = schema.GetLocalCache(engine)
 		}
 		inCache := localCache.MGet(cacheKeys...)
+		hits := 0
+		for _, val := range inCache {
+			if val != nil {
+				hits++
+			}
+		}
+		schema.cacheCounters.local(localCache, hits, len(inCache)-hits)
 		for i, val := range inCache {
 			if val != nil {
 				if val != cacheNilValue {
@@ -85,13 +93,20 @@ func tryByIDs(serializer *serializer, engine *Engine, ids []uint64, entities ref
 	if hasRedis && j > 0 {
 		redisCache, _ = schema.GetRedisCache(engine)
 		inCache := redisCache.MGet(cacheKeys[0:j]...)
+		hits := 0
+		for _, val := range inCache {
+			if val != nil {
+				hits++
+			}
+		}
+		schema.cacheCounters.redis(hits, len(inCache)-hits)
 		for i, val := range inCache {
 			if val != nil {
 				if val != cacheNilValue {
 					e := schema.NewEntity()
 					k := cacheKeysMap[cacheKeys[i]]
 					newSlice.Index(k).Set(e.getORM().value)
-					fillFromBinary(serializer, engine.registry, []byte(val.(string)), e)
+					fillFromBinary(serializer, engine.registry, schema.decodeRedisValue([]byte(val.(string))), e)
 					if hasLocalCache {
 						localCacheToSet = append(localCacheToSet, cacheKeys[i], e.getORM().copyBinary())
 					}
@@ -110,43 +125,63 @@ func tryByIDs(serializer *serializer, engine *Engine, ids []uint64, entities ref
 		}
 	}
 	if len(idsDB) > 0 {
+		sort.Slice(idsDB, func(i, j int) bool {
+			return idsDB[i] < idsDB[j]
+		})
 		query := "SELECT " + schema.fieldsQuery + " FROM `" + schema.tableName + "` WHERE `ID` IN (" + strconv.FormatUint(idsDB[0], 10)
 		for _, id := range idsDB[1:] {
 			query += "," + strconv.FormatUint(id, 10)
 		}
 		query += ")"
-		pool := schema.GetMysql(engine)
-		results, def := pool.Query(query)
-		defer def()
-		found := 0
-		for results.Next() {
-			pointers := prepareScan(schema)
-			results.Scan(pointers...)
-			id := *pointers[schema.idIndex].(*uint64)
-			cacheKey := schema.getCacheKey(id)
-			e := schema.NewEntity()
-			k := cacheKeysMap[cacheKey]
-			newSlice.Index(k).Set(e.getORM().value)
-			fillFromDBRow(serializer, id, engine.registry, pointers, e)
-			if hasLocalCache {
-				localCacheToSet = append(localCacheToSet, cacheKey, e.getORM().copyBinary())
+		rows, leader := engine.registry.singleFlight.do(schema.cachePrefix+":"+query, func() interface{} {
+			schema.cacheCounters.db(len(idsDB))
+			rows := make(map[uint64][]byte)
+			pool := schema.GetMysql(engine)
+			results, def := pool.Query(query)
+			defer def()
+			for results.Next() {
+				pointers := prepareScan(schema)
+				results.Scan(pointers...)
+				id := *pointers[schema.idIndex].(*uint64)
+				cacheKey := schema.getCacheKey(id)
+				e := schema.NewEntity()
+				k := cacheKeysMap[cacheKey]
+				newSlice.Index(k).Set(e.getORM().value)
+				fillFromDBRow(serializer, id, engine.registry, pointers, e)
+				if hasLocalCache {
+					localCacheToSet = append(localCacheToSet, cacheKey, e.getORM().copyBinary())
+				}
+				if hasRedis {
+					redisCacheToSet = append(redisCacheToSet, cacheKey, schema.encodeRedisValue(e.getORM().binary))
+				}
+				rows[id] = e.getORM().copyBinary()
 			}
-			if hasRedis {
-				redisCacheToSet = append(redisCacheToSet, cacheKey, e.getORM().binary)
+			def()
+			return rows
+		})
+		found := len(rows.(map[uint64][]byte))
+		if !leader {
+			for id, binary := range rows.(map[uint64][]byte) {
+				e := schema.NewEntity()
+				newSlice.Index(cacheKeysMap[schema.getCacheKey(id)]).Set(e.getORM().value)
+				fillFromBinary(serializer, engine.registry, binary, e)
 			}
+		}
+		if found > 0 {
 			hasValid = true
-			found++
 		}
-		def()
 		if !hasMissing && found < len(idsDB) {
 			hasMissing = true
 		}
 	}
 	if len(localCacheToSet) > 0 && localCache != nil {
+		for i := 1; i < len(localCacheToSet); i += 2 {
+			localCacheToSet[i] = newLocalCacheEntry(localCacheToSet[i], schema.localCacheTTL)
+		}
 		localCache.MSet(localCacheToSet...)
 	}
 	if len(redisCacheToSet) > 0 && redisCache != nil {
-		redisCache.MSet(redisCacheToSet...)
+		redisCache.mSetWithTTL(schema.redisCacheTTL, redisCacheToSet...)
 	}
 	for _, list := range duplicates {
 		for _, k := range list[1:] {
@@ -303,8 +338,10 @@ func warmUpReferences(serializer *serializer, engine *Engine, schema *tableSchem
 		}
 		for key, fromCache := range engine.GetRedis(k).MGet(keys...) {
 			if fromCache != nil && fromCache != cacheNilValue {
-				for _, r := range v[keys[key]] {
-					fillFromBinary(serializer, engine.registry, []byte(fromCache.(string)), r)
+				refs := v[keys[key]]
+				binary := initIfNeeded(engine.registry, refs[0]).tableSchema.decodeRedisValue([]byte(fromCache.(string)))
+				for _, r := range refs {
+					fillFromBinary(serializer, engine.registry, binary, r)
 				}
 				fillRef(keys[key], nil, redisMap, dbMap)
 			}
@@ -341,11 +378,14 @@ func warmUpReferences(serializer *serializer, engine *Engine, schema *tableSchem
 		if len(v) == 0 {
 			continue
 		}
-		values := make([]interface{}, 0)
+		values := make(map[int][]interface{})
 		for cacheKey, refs := range v {
-			values = append(values, cacheKey, refs[0].getORM().binary)
+			refSchema := refs[0].getORM().tableSchema
+			values[refSchema.redisCacheTTL] = append(values[refSchema.redisCacheTTL], cacheKey, refSchema.encodeRedisValue(refs[0].getORM().binary))
+		}
+		for ttl, pairs := range values {
+			engine.GetRedis(pool).mSetWithTTL(ttl, pairs...)
 		}
-		engine.GetRedis(pool).MSet(values...)
 	}
 	for pool, v := range localMap {
 		if len(v) == 0 {
@@ -353,7 +393,7 @@ func warmUpReferences(serializer *serializer, engine *Engine, schema *tableSchem
 		}
 		values := make([]interface{}, 0)
 		for cacheKey, refs := range v {
-			values = append(values, cacheKey, refs[0].getORM().binary)
+			values = append(values, cacheKey, newLocalCacheEntry(refs[0].getORM().binary, refs[0].getORM().tableSchema.localCacheTTL))
 		}
 		engine.GetLocalCache(pool).MSet(values...)
 	}
@@ -4,23 +4,88 @@ import (
 	"fmt"
 	"strings"
 	"sync"
+	"sync/atomic"
 	"time"
 
-	"github.com/golang/groupcache/lru"
+	"github.com/segmentio/fasthash/fnv1a"
 )
 
 const requestCacheKey = "_request"
 
+type LocalCachePolicy string
+
+const (
+	LocalCachePolicyLRU     LocalCachePolicy = "lru"
+	LocalCachePolicyLFU     LocalCachePolicy = "lfu"
+	LocalCachePolicyTinyLFU LocalCachePolicy = "tinylfu"
+)
+
+type LocalCacheOptions struct {
+	MaxEntries int
+	MaxBytes   int64
+	Policy     LocalCachePolicy
+	Shards     int
+}
+
+type LocalCacheStats struct {
+	Hits      uint64
+	Misses    uint64
+	Evictions uint64
+	Entries   int
+	Bytes     int64
+}
+
 type LocalCachePoolConfig interface {
 	GetCode() string
 	GetLimit() int
+	GetMaxBytes() int64
+	GetPolicy() LocalCachePolicy
 }
 
-type localCachePoolConfig struct {
-	code  string
-	limit int
-	lru   *lru.Cache
+type localCacheShard struct {
 	m     sync.Mutex
+	store localCacheStore
+}
+
+type localCachePoolConfig struct {
+	hits      uint64
+	misses    uint64
+	evictions uint64
+	code      string
+	limit     int
+	maxBytes  int64
+	policy    LocalCachePolicy
+	shards    []*localCacheShard
+}
+
+func newLocalCachePoolConfig(code string, options LocalCacheOptions) *localCachePoolConfig {
+	if options.Policy == "" {
+		options.Policy = LocalCachePolicyLRU
+	}
+	shards := options.Shards
+	if shards <= 0 {
+		shards = 16
+		if options.MaxEntries > 0 && options.MaxEntries < 1024 {
+			shards = 1
+		}
+	}
+	config := &localCachePoolConfig{code: code, limit: options.MaxEntries, maxBytes: options.MaxBytes, policy: options.Policy,
+		shards: make([]*localCacheShard, shards)}
+	maxEntries := 0
+	if options.MaxEntries > 0 {
+		maxEntries = (options.MaxEntries + shards - 1) / shards
+	}
+	maxBytes := int64(0)
+	if options.MaxBytes > 0 {
+		maxBytes = (options.MaxBytes + int64(shards) - 1) / int64(shards)
+	}
+	onEvict := func() {
+		atomic.AddUint64(&config.evictions, 1)
+	}
+	for i := range config.shards {
+		config.shards[i] = &localCacheShard{store: newLocalCacheStore(options.Policy, maxEntries, maxBytes, onEvict)}
+	}
+	return config
 }
 
 func (p *localCachePoolConfig) GetCode() string {
@@ -31,14 +96,73 @@ func (p *localCachePoolConfig) GetLimit() int {
 	return p.limit
 }
 
+func (p *localCachePoolConfig) GetMaxBytes() int64 {
+	return p.maxBytes
+}
+
+func (p *localCachePoolConfig) GetPolicy() LocalCachePolicy {
+	return p.policy
+}
+
+func (p *localCachePoolConfig) shard(key string) *localCacheShard {
+	if len(p.shards) == 1 {
+		return p.shards[0]
+	}
+	return p.shards[fnv1a.HashString32(key)%uint32(len(p.shards))]
+}
+
+func (p *localCachePoolConfig) remove(keys ...string) {
+	for _, key := range keys {
+		shard := p.shard(key)
+		shard.m.Lock()
+		shard.store.remove(key)
+		shard.m.Unlock()
+	}
+}
+
+func (p *localCachePoolConfig) keys(prefix string) []string {
+	keys := make([]string, 0)
+	for _, shard := range p.shards {
+		shard.m.Lock()
+		keys = shard.store.keys(prefix, keys)
+		shard.m.Unlock()
+	}
+	return keys
+}
+
+func (p *localCachePoolConfig) countHits(hits, misses int) {
+	if hits > 0 {
+		atomic.AddUint64(&p.hits, uint64(hits))
+	}
+	if misses > 0 {
+		atomic.AddUint64(&p.misses, uint64(misses))
+	}
+}
+
 type LocalCache struct {
 	engine *Engine
 	config *localCachePoolConfig
 }
 
-type ttlValue struct {
-	value interface{}
-	time  int64
+type localCacheEntry struct {
+	value       interface{}
+	expireAt    int64
+	computeTime time.Duration
+}
+
+func newLocalCacheEntry(value interface{}, ttl time.Duration) interface{} {
+	if ttl <= 0 {
+		return value
+	}
+	return &localCacheEntry{value: value, expireAt: time.Now().Add(ttl).UnixNano()}
+}
+
+func unwrapLocalCacheEntry(value interface{}) interface{} {
+	entry, is := value.(*localCacheEntry)
+	if is {
+		return entry.value
+	}
+	return value
 }
 
 func (c *LocalCache) GetPoolConfig() LocalCachePoolConfig {
@@ -46,25 +170,60 @@ func (c *LocalCache) GetPoolConfig() LocalCachePoolConfig {
 }
 
 func (c *LocalCache) GetSet(key string, ttl time.Duration, provider func() interface{}) interface{} {
-	val, has := c.Get(key)
-	if has {
-		ttlVal := val.(ttlValue)
-		seconds := int64(ttl.Seconds())
-		if seconds == 0 || time.Now().Unix()-ttlVal.time <= seconds {
-			return ttlVal.value
-		}
+	shard := c.config.shard(key)
+	shard.m.Lock()
+	val, entry, has := shard.getEntry(key)
+	shard.m.Unlock()
+	c.countHit(has)
+	if c.engine.hasLocalCacheLogger {
+		c.fillLogFields("GET", "GET "+key, !has)
+	}
+	if has && (entry == nil || !c.engine.shouldRecomputeEarly(entry.computeTime, entry.expireAt)) {
+		return val
+	}
+	flightKey := "local:" + c.config.GetCode() + ":" + key
+	if has && c.engine.registry.singleFlight.running(flightKey) {
+		return val
 	}
-	userVal := provider()
-	val = ttlValue{value: userVal, time: time.Now().Unix()}
-	c.Set(key, val)
+	userVal, _ := c.engine.registry.singleFlight.do(flightKey, func() interface{} {
+		start := time.Now()
+		userVal := provider()
+		c.set(key, userVal, ttl.Truncate(time.Second), time.Since(start))
+		return userVal
+	})
 	return userVal
 }
 
-func (c *LocalCache) Get(key string) (value interface{}, ok bool) {
-	c.config.m.Lock()
-	defer c.config.m.Unlock()
+func (s *localCacheShard) getEntry(key string) (value interface{}, entry *localCacheEntry, ok bool) {
+	value, ok = s.store.get(key)
+	if !ok {
+		return nil, nil, false
+	}
+	entry, is := value.(*localCacheEntry)
+	if !is {
+		return value, nil, true
+	}
+	if time.Now().UnixNano() > entry.expireAt {
+		s.store.remove(key)
+		return nil, nil, false
+	}
+	return entry.value, entry, true
+}
 
-	value, ok = c.config.lru.Get(key)
+func (c *LocalCache) countHit(hit bool) {
+	if hit {
+		c.config.countHits(1, 0)
+	} else {
+		c.config.countHits(0, 1)
+	}
+}
+
+func (c *LocalCache) Get(key string) (value interface{}, ok bool) {
+	shard := c.config.shard(key)
+	shard.m.Lock()
+	value, _, ok = shard.getEntry(key)
+	shard.m.Unlock()
+	c.countHit(ok)
 	if c.engine.hasLocalCacheLogger {
 		c.fillLogFields("GET", "GET "+key, !ok)
 	}
@@ -72,19 +231,20 @@ func (c *LocalCache) Get(key string) (value interface{}, ok bool) {
 }
 
 func (c *LocalCache) MGet(keys ...string) []interface{} {
-	c.config.m.Lock()
-	defer c.config.m.Unlock()
-
 	results := make([]interface{}, len(keys))
 	misses := 0
 	for i, key := range keys {
-		value, ok := c.config.lru.Get(key)
+		shard := c.config.shard(key)
+		shard.m.Lock()
+		value, _, ok := shard.getEntry(key)
+		shard.m.Unlock()
 		if !ok {
 			misses++
 			value = nil
 		}
 		results[i] = value
 	}
+	c.config.countHits(len(keys)-misses, misses)
 	if c.engine.hasLocalCacheLogger {
 		c.fillLogFields("MGET", "MGET "+strings.Join(keys, " "), misses > 0)
 	}
@@ -92,37 +252,62 @@ func (c *LocalCache) MGet(keys ...string) []interface{} {
 }
 
 func (c *LocalCache) Set(key string, value interface{}) {
-	c.config.m.Lock()
-	defer c.config.m.Unlock()
-	c.config.lru.Add(key, value)
+	shard := c.config.shard(key)
+	shard.m.Lock()
+	shard.store.add(key, value)
+	shard.m.Unlock()
 	if c.engine.hasLocalCacheLogger {
 		c.fillLogFields("SET", fmt.Sprintf("SET %s %v", key, value), false)
 	}
 }
 
+func (c *LocalCache) SetWithTTL(key string, value interface{}, ttl time.Duration) {
+	c.set(key, value, ttl, 0)
+}
+
+func (c *LocalCache) set(key string, value interface{}, ttl, computeTime time.Duration) {
+	entry := newLocalCacheEntry(value, ttl)
+	if e, is := entry.(*localCacheEntry); is {
+		e.computeTime = computeTime
+	}
+	shard := c.config.shard(key)
+	shard.m.Lock()
+	shard.store.add(key, entry)
+	shard.m.Unlock()
+	if c.engine.hasLocalCacheLogger {
+		c.fillLogFields("SET", fmt.Sprintf("SET %s %v %s", key, value, ttl), false)
+	}
+}
+
 func (c *LocalCache) MSet(pairs ...interface{}) {
 	max := len(pairs)
-	c.config.m.Lock()
-	defer c.config.m.Unlock()
 	for i := 0; i < max; i += 2 {
-		c.config.lru.Add(pairs[i], pairs[i+1])
+		key, is := pairs[i].(string)
+		if !is {
+			key = fmt.Sprintf("%v", pairs[i])
+		}
+		shard := c.config.shard(key)
+		shard.m.Lock()
+		shard.store.add(key, pairs[i+1])
+		shard.m.Unlock()
 	}
 	if c.engine.hasLocalCacheLogger {
 		message := "MSET "
 		for _, v := range pairs {
-			message += fmt.Sprintf(" %v", v)
+			message += fmt.Sprintf(" %v", unwrapLocalCacheEntry(v))
 		}
 		c.fillLogFields("MSET", message, false)
 	}
 }
 
 func (c *LocalCache) HMGet(key string, fields ...string) map[string]interface{} {
-	c.config.m.Lock()
-	defer c.config.m.Unlock()
+	shard := c.config.shard(key)
+	shard.m.Lock()
+	defer shard.m.Unlock()
 
 	l := len(fields)
 	results := make(map[string]interface{}, l)
-	value, ok := c.config.lru.Get(key)
+	value, _, ok := shard.getEntry(key)
 	misses := 0
 	for _, field := range fields {
 		if !ok {
@@ -138,6 +323,7 @@ func (c *LocalCache) HMGet(key string, fields ...string) map[string]interface{}
 			}
 		}
 	}
+	c.config.countHits(l-misses, misses)
 	if c.engine.hasLocalCacheLogger {
 		c.fillLogFields("HMGET", "HMGET "+key+" "+strings.Join(fields, " "), misses > 0)
 	}
@@ -145,17 +331,28 @@ func (c *LocalCache) HMGet(key string, fields ...string) map[string]interface{}
 }
 
 func (c *LocalCache) HMSet(key string, fields map[string]interface{}) {
-	c.config.m.Lock()
-	defer c.config.m.Unlock()
+	c.HMSetWithTTL(key, 0, fields)
+}
+
+func (c *LocalCache) HMSetWithTTL(key string, ttl time.Duration, fields map[string]interface{}) {
+	shard := c.config.shard(key)
+	shard.m.Lock()
+	defer shard.m.Unlock()
 
-	m, has := c.config.lru.Get(key)
+	m, entry, has := shard.getEntry(key)
+	var stored interface{}
 	if !has {
 		m = make(map[string]interface{})
-		c.config.lru.Add(key, m)
+		stored = newLocalCacheEntry(m, ttl)
+	} else if entry != nil {
+		stored = entry
+	} else {
+		stored = m
 	}
 	for k, v := range fields {
 		m.(map[string]interface{})[k] = v
 	}
+	shard.store.add(key, stored)
 	if c.engine.hasLocalCacheLogger {
 		message := "HMSET " + key + " "
 		for k, v := range fields {
@@ -166,29 +363,46 @@ func (c *LocalCache) HMSet(key string, fields map[string]interface{}) {
 }
 
 func (c *LocalCache) Remove(keys ...string) {
-	c.config.m.Lock()
-	defer c.config.m.Unlock()
-	for _, v := range keys {
-		c.config.lru.Remove(v)
-	}
+	c.config.remove(keys...)
 	if c.engine.hasLocalCacheLogger {
 		c.fillLogFields("REMOVE", "REMOVE "+strings.Join(keys, " "), false)
 	}
 }
 
 func (c *LocalCache) Clear() {
-	c.config.m.Lock()
-	defer c.config.m.Unlock()
-	c.config.lru.Clear()
+	for _, shard := range c.config.shards {
+		shard.m.Lock()
+		shard.store.clear()
+		shard.m.Unlock()
+	}
 	if c.engine.hasLocalCacheLogger {
 		c.fillLogFields("CLEAR", "CLEAR", false)
 	}
 }
 
 func (c *LocalCache) GetObjectsCount() int {
-	c.config.m.Lock()
-	defer c.config.m.Unlock()
-	return c.config.lru.Len()
+	total := 0
+	for _, shard := range c.config.shards {
+		shard.m.Lock()
+		total += shard.store.len()
+		shard.m.Unlock()
+	}
+	return total
+}
+
+func (c *LocalCache) GetStats() LocalCacheStats {
+	stats := LocalCacheStats{
+		Hits:      atomic.LoadUint64(&c.config.hits),
+		Misses:    atomic.LoadUint64(&c.config.misses),
+		Evictions: atomic.LoadUint64(&c.config.evictions),
+	}
+	for _, shard := range c.config.shards {
+		shard.m.Lock()
+		stats.Entries += shard.store.len()
+		stats.Bytes += shard.store.bytes()
+		shard.m.Unlock()
+	}
+	return stats
 }
 
 func (c *LocalCache) fillLogFields(operation, query string, cacheMiss bool) {
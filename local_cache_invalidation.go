@@ -0,0 +1,106 @@
+package beeorm
+
+import (
+	"context"
+	"os"
+	"strconv"
+	"time"
+
+	"github.com/shamaton/msgpack"
+)
+
+const localCacheInvalidationChannelName = "orm-local-cache-invalidation"
+
+type localCacheInvalidation struct {
+	S string
+	P string
+	K []string
+}
+
+func (r *Registry) EnableLocalCacheInvalidation(redisPool ...string) {
+	code := "default"
+	if len(redisPool) > 0 {
+		code = redisPool[0]
+	}
+	r.localCacheBusPool = code
+}
+
+func (e *Engine) publishLocalCacheInvalidation(cacheCode string, keys ...string) {
+	if e.registry.localCacheBusPool == "" || cacheCode == requestCacheKey || len(keys) == 0 {
+		return
+	}
+	body, err := msgpack.Marshal(&localCacheInvalidation{S: e.registry.instanceID, P: cacheCode, K: keys})
+	checkError(err)
+	e.GetRedis(e.registry.localCacheBusPool).Publish(localCacheInvalidationChannelName, body)
+}
+
+// localCacheInvalidations are published after redis cache is updated, so other instances can't reload stale values
+type localCacheInvalidations map[string][]string
+
+func (i *localCacheInvalidations) add(cacheCode string, keys ...string) {
+	if *i == nil {
+		*i = make(localCacheInvalidations)
+	}
+	(*i)[cacheCode] = append((*i)[cacheCode], keys...)
+}
+
+func (i *localCacheInvalidations) addSets(cacheCode string, pairs []interface{}) {
+	keys := make([]string, 0, len(pairs)/2)
+	for j := 0; j < len(pairs); j += 2 {
+		keys = append(keys, pairs[j].(string))
+	}
+	i.add(cacheCode, keys...)
+}
+
+func (e *Engine) publishLocalCacheInvalidations(invalidations localCacheInvalidations) {
+	for cacheCode, keys := range invalidations {
+		e.publishLocalCacheInvalidation(cacheCode, keys...)
+	}
+}
+
+func (r *validatedRegistry) startLocalCacheInvalidation() (stop func(), err error) {
+	config := r.redisServers[r.localCacheBusPool]
+	channel := localCacheInvalidationChannelName
+	if config.HasNamespace() {
+		channel = config.GetNamespace() + ":" + channel
+	}
+	r.instanceID = strconv.Itoa(os.Getpid()) + "-" + strconv.FormatInt(time.Now().UnixNano(), 36)
+	ctx, cancel := context.WithCancel(context.Background())
+	pubSub := config.getClient().Subscribe(ctx, channel)
+	_, err = pubSub.Receive(ctx)
+	if err != nil {
+		cancel()
+		_ = pubSub.Close()
+		return nil, err
+	}
+	go func() {
+		defer func() {
+			_ = pubSub.Close()
+		}()
+		messages := pubSub.Channel()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case message, ok := <-messages:
+				if !ok {
+					return
+				}
+				r.applyLocalCacheInvalidation(message.Payload)
+			}
+		}
+	}()
+	return cancel, nil
+}
+
+func (r *validatedRegistry) applyLocalCacheInvalidation(payload string) {
+	value := &localCacheInvalidation{}
+	if msgpack.Unmarshal([]byte(payload), value) != nil || value.S == r.instanceID {
+		return
+	}
+	pool, has := r.localCacheServers[value.P]
+	if !has {
+		return
+	}
+	pool.(*localCachePoolConfig).remove(value.K...)
+}
@@ -0,0 +1,117 @@
+package beeorm
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type localCacheInvalidationEntity struct {
+	ORM  `orm:"localCache"`
+	ID   uint
+	Name string
+}
+
+func TestLocalCacheInvalidation(t *testing.T) {
+	var entity *localCacheInvalidationEntity
+	registry := &Registry{}
+	registry.EnableLocalCacheInvalidation()
+	engine, def := prepareTables(t, registry, 5, "", "2.0", entity)
+	defer def()
+	registry2 := &Registry{}
+	registry2.EnableLocalCacheInvalidation()
+	engine2, def2 := prepareTables(t, registry2, 5, "", "2.0", entity)
+	defer def2()
+
+	entity = &localCacheInvalidationEntity{Name: "John"}
+	engine.Flush(entity)
+
+	entity2 := &localCacheInvalidationEntity{}
+	assert.True(t, engine2.LoadByID(1, entity2))
+	assert.Equal(t, "John", entity2.Name)
+	schema := engine2.GetRegistry().GetTableSchemaForEntity(entity).(*tableSchema)
+	_, has := engine2.GetLocalCache().Get(schema.getCacheKey(1))
+	assert.True(t, has)
+
+	entity.Name = "Tom"
+	engine.Flush(entity)
+	assert.Eventually(t, func() bool {
+		_, has = engine2.GetLocalCache().Get(schema.getCacheKey(1))
+		return !has
+	}, time.Second, time.Millisecond*10)
+	entity2 = &localCacheInvalidationEntity{}
+	assert.True(t, engine2.LoadByID(1, entity2))
+	assert.Equal(t, "Tom", entity2.Name)
+	_, has = engine.GetLocalCache().Get(schema.getCacheKey(1))
+	assert.True(t, has)
+
+	engine.ClearCacheByIDs(entity, 1)
+	assert.Eventually(t, func() bool {
+		_, has = engine2.GetLocalCache().Get(schema.getCacheKey(1))
+		return !has
+	}, time.Second, time.Millisecond*10)
+
+	registry = &Registry{}
+	registry.EnableLocalCacheInvalidation("invalid")
+	registry.RegisterMySQLPool("root:root@tcp(localhost:3311)/test")
+	_, _, err := registry.Validate()
+	assert.EqualError(t, err, "redis pool 'invalid' not found")
+}
+
+type localCacheInvalidationRedisEntity struct {
+	ORM  `orm:"localCache;redisCache"`
+	ID   uint
+	Name string
+}
+
+func TestLocalCacheInvalidationOrder(t *testing.T) {
+	var entity *localCacheInvalidationRedisEntity
+	registry := &Registry{}
+	registry.EnableLocalCacheInvalidation()
+	engine, def := prepareTables(t, registry, 5, "", "2.0", entity)
+	defer def()
+	registry2 := &Registry{}
+	registry2.EnableLocalCacheInvalidation()
+	engine2, def2 := prepareTables(t, registry2, 5, "", "2.0", entity)
+	defer def2()
+	schema := engine.GetRegistry().GetTableSchemaForEntity(entity).(*tableSchema)
+
+	entity = &localCacheInvalidationRedisEntity{Name: "John"}
+	engine.Flush(entity)
+	assert.True(t, engine2.LoadByID(1, &localCacheInvalidationRedisEntity{}))
+
+	logger := &testLogHandler{}
+	engine.RegisterQueryLogger(logger, false, true, false)
+	entity.Name = "Tom"
+	engine.Flush(entity)
+	operations := make([]string, 0)
+	for _, log := range logger.Logs {
+		operations = append(operations, log["operation"].(string))
+	}
+	assert.Equal(t, []string{"DEL", "PUBLISH"}, operations)
+
+	assert.Eventually(t, func() bool {
+		_, has := engine2.GetLocalCache().Get(schema.getCacheKey(1))
+		return !has
+	}, time.Second, time.Millisecond*10)
+	assert.True(t, engine2.LoadByID(1, &localCacheInvalidationRedisEntity{}))
+
+	logger.clear()
+	engine.GetMysql().Begin()
+	entity.Name = "Adam"
+	engine.Flush(entity)
+	time.Sleep(time.Millisecond * 100)
+	_, has := engine2.GetLocalCache().Get(schema.getCacheKey(1))
+	assert.True(t, has)
+	assert.Len(t, logger.Logs, 0)
+	engine.GetMysql().Commit()
+	assert.Equal(t, "PUBLISH", logger.Logs[len(logger.Logs)-1]["operation"])
+	assert.Eventually(t, func() bool {
+		_, has = engine2.GetLocalCache().Get(schema.getCacheKey(1))
+		return !has
+	}, time.Second, time.Millisecond*10)
+	loaded := &localCacheInvalidationRedisEntity{}
+	assert.True(t, engine2.LoadByID(1, loaded))
+	assert.Equal(t, "Adam", loaded.Name)
+}
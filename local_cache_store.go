@@ -0,0 +1,502 @@
+package beeorm
+
+import (
+	"container/list"
+	"strings"
+
+	"github.com/segmentio/fasthash/fnv1a"
+)
+
+const localCacheItemOverhead = 64
+
+type localCacheStore interface {
+	get(key string) (value interface{}, ok bool)
+	add(key string, value interface{})
+	remove(key string)
+	clear()
+	keys(prefix string, target []string) []string
+	len() int
+	bytes() int64
+}
+
+type localCacheItem struct {
+	key   string
+	value interface{}
+	size  int64
+	freq  int
+}
+
+func newLocalCacheStore(policy LocalCachePolicy, maxEntries int, maxBytes int64, onEvict func()) localCacheStore {
+	switch policy {
+	case LocalCachePolicyLFU:
+		return &lfuStore{maxEntries: maxEntries, maxBytes: maxBytes, onEvict: onEvict, items: make(map[string]*list.Element),
+			frequencies: make(map[int]*list.List)}
+	case LocalCachePolicyTinyLFU:
+		return newTinyLFUStore(maxEntries, maxBytes, onEvict)
+	default:
+		// unknown policies are rejected in Registry.Validate
+		return &lruStore{lruList: newLRUList(maxEntries, maxBytes), onEvict: onEvict}
+	}
+}
+
+func localCacheItemSize(key string, value interface{}) int64 {
+	return int64(len(key)) + localCacheValueSize(value) + localCacheItemOverhead
+}
+
+func localCacheValueSize(value interface{}) int64 {
+	switch v := value.(type) {
+	case nil:
+		return 0
+	case string:
+		return int64(len(v))
+	case []byte:
+		return int64(len(v))
+	case []uint64:
+		return int64(len(v)) * 8
+	case []string:
+		size := int64(0)
+		for _, s := range v {
+			size += int64(len(s)) + 16
+		}
+		return size
+	case map[string]interface{}:
+		size := int64(0)
+		for k, val := range v {
+			size += int64(len(k)) + localCacheValueSize(val) + 16
+		}
+		return size
+	case *localCacheEntry:
+		return localCacheValueSize(v.value) + 24
+	default:
+		return 16
+	}
+}
+
+type lruList struct {
+	maxEntries int
+	maxBytes   int64
+	size       int64
+	ll         *list.List
+	items      map[string]*list.Element
+}
+
+func newLRUList(maxEntries int, maxBytes int64) *lruList {
+	return &lruList{maxEntries: maxEntries, maxBytes: maxBytes, ll: list.New(), items: make(map[string]*list.Element)}
+}
+
+func (l *lruList) get(key string) (*localCacheItem, bool) {
+	element, has := l.items[key]
+	if !has {
+		return nil, false
+	}
+	l.ll.MoveToFront(element)
+	return element.Value.(*localCacheItem), true
+}
+
+func (l *lruList) push(item *localCacheItem) {
+	element, has := l.items[item.key]
+	if has {
+		old := element.Value.(*localCacheItem)
+		l.size += item.size - old.size
+		element.Value = item
+		l.ll.MoveToFront(element)
+		return
+	}
+	l.items[item.key] = l.ll.PushFront(item)
+	l.size += item.size
+}
+
+func (l *lruList) remove(key string) *localCacheItem {
+	element, has := l.items[key]
+	if !has {
+		return nil
+	}
+	return l.removeElement(element)
+}
+
+func (l *lruList) removeElement(element *list.Element) *localCacheItem {
+	item := element.Value.(*localCacheItem)
+	l.ll.Remove(element)
+	delete(l.items, item.key)
+	l.size -= item.size
+	return item
+}
+
+func (l *lruList) oldest() *localCacheItem {
+	element := l.ll.Back()
+	if element == nil {
+		return nil
+	}
+	return element.Value.(*localCacheItem)
+}
+
+func (l *lruList) removeOldest() *localCacheItem {
+	element := l.ll.Back()
+	if element == nil {
+		return nil
+	}
+	return l.removeElement(element)
+}
+
+func (l *lruList) overLimit() bool {
+	return (l.maxEntries > 0 && l.ll.Len() > l.maxEntries) || (l.maxBytes > 0 && l.size > l.maxBytes)
+}
+
+func (l *lruList) fits(item *localCacheItem) bool {
+	return (l.maxEntries <= 0 || l.ll.Len() < l.maxEntries) && (l.maxBytes <= 0 || l.size+item.size <= l.maxBytes)
+}
+
+func (l *lruList) keys(prefix string, target []string) []string {
+	for key := range l.items {
+		if strings.HasPrefix(key, prefix) {
+			target = append(target, key)
+		}
+	}
+	return target
+}
+
+func (l *lruList) clear() {
+	l.ll.Init()
+	l.items = make(map[string]*list.Element)
+	l.size = 0
+}
+
+type lruStore struct {
+	*lruList
+	onEvict func()
+}
+
+func (s *lruStore) get(key string) (interface{}, bool) {
+	item, has := s.lruList.get(key)
+	if !has {
+		return nil, false
+	}
+	return item.value, true
+}
+
+func (s *lruStore) add(key string, value interface{}) {
+	s.push(&localCacheItem{key: key, value: value, size: localCacheItemSize(key, value)})
+	for s.overLimit() && s.ll.Len() > 1 {
+		s.removeOldest()
+		s.onEvict()
+	}
+}
+
+func (s *lruStore) remove(key string) {
+	s.lruList.remove(key)
+}
+
+func (s *lruStore) len() int {
+	return s.ll.Len()
+}
+
+func (s *lruStore) bytes() int64 {
+	return s.size
+}
+
+type lfuStore struct {
+	maxEntries  int
+	maxBytes    int64
+	size        int64
+	minFreq     int
+	items       map[string]*list.Element
+	frequencies map[int]*list.List
+	onEvict     func()
+}
+
+func (s *lfuStore) get(key string) (interface{}, bool) {
+	element, has := s.items[key]
+	if !has {
+		return nil, false
+	}
+	item := s.touch(element)
+	return item.value, true
+}
+
+func (s *lfuStore) touch(element *list.Element) *localCacheItem {
+	item := element.Value.(*localCacheItem)
+	s.unlink(element)
+	item.freq++
+	s.items[item.key] = s.link(item)
+	return item
+}
+
+func (s *lfuStore) link(item *localCacheItem) *list.Element {
+	l, has := s.frequencies[item.freq]
+	if !has {
+		l = list.New()
+		s.frequencies[item.freq] = l
+	}
+	return l.PushFront(item)
+}
+
+func (s *lfuStore) unlink(element *list.Element) {
+	item := element.Value.(*localCacheItem)
+	l := s.frequencies[item.freq]
+	l.Remove(element)
+	if l.Len() == 0 {
+		delete(s.frequencies, item.freq)
+		if s.minFreq == item.freq {
+			s.minFreq++
+		}
+	}
+}
+
+func (s *lfuStore) add(key string, value interface{}) {
+	size := localCacheItemSize(key, value)
+	element, has := s.items[key]
+	if has {
+		item := element.Value.(*localCacheItem)
+		s.size += size - item.size
+		item.value = value
+		item.size = size
+		s.touch(element)
+		for s.overLimitWith(nil) && len(s.items) > 1 {
+			s.evict()
+		}
+		return
+	}
+	item := &localCacheItem{key: key, value: value, size: size, freq: 1}
+	for s.overLimitWith(item) && len(s.items) > 0 {
+		s.evict()
+	}
+	s.items[key] = s.link(item)
+	s.size += size
+	s.minFreq = 1
+}
+
+func (s *lfuStore) overLimitWith(item *localCacheItem) bool {
+	entries := len(s.items)
+	size := s.size
+	if item != nil {
+		entries++
+		size += item.size
+	}
+	return (s.maxEntries > 0 && entries > s.maxEntries) || (s.maxBytes > 0 && size > s.maxBytes)
+}
+
+func (s *lfuStore) evict() {
+	l, has := s.frequencies[s.minFreq]
+	for !has {
+		s.minFreq++
+		l, has = s.frequencies[s.minFreq]
+	}
+	s.removeElement(l.Back())
+	s.onEvict()
+}
+
+func (s *lfuStore) removeElement(element *list.Element) {
+	item := element.Value.(*localCacheItem)
+	s.unlink(element)
+	delete(s.items, item.key)
+	s.size -= item.size
+	if len(s.items) == 0 {
+		s.minFreq = 0
+	}
+}
+
+func (s *lfuStore) remove(key string) {
+	element, has := s.items[key]
+	if has {
+		s.removeElement(element)
+	}
+}
+
+func (s *lfuStore) clear() {
+	s.items = make(map[string]*list.Element)
+	s.frequencies = make(map[int]*list.List)
+	s.size = 0
+	s.minFreq = 0
+}
+
+func (s *lfuStore) keys(prefix string, target []string) []string {
+	for key := range s.items {
+		if strings.HasPrefix(key, prefix) {
+			target = append(target, key)
+		}
+	}
+	return target
+}
+
+func (s *lfuStore) len() int {
+	return len(s.items)
+}
+
+func (s *lfuStore) bytes() int64 {
+	return s.size
+}
+
+type tinyLFUStore struct {
+	window  *lruList
+	main    *lruList
+	sketch  *countMinSketch
+	onEvict func()
+}
+
+func newTinyLFUStore(maxEntries int, maxBytes int64, onEvict func()) *tinyLFUStore {
+	windowEntries := 0
+	if maxEntries > 0 {
+		windowEntries = maxEntries / 100
+		if windowEntries < 1 {
+			windowEntries = 1
+		}
+	}
+	mainEntries := 0
+	if maxEntries > 0 {
+		mainEntries = maxEntries - windowEntries
+		if mainEntries < 1 {
+			mainEntries = 1
+		}
+	}
+	windowBytes := int64(0)
+	if maxBytes > 0 {
+		windowBytes = maxBytes / 100
+		if windowBytes < 1 {
+			windowBytes = 1
+		}
+	}
+	capacity := maxEntries
+	if capacity <= 0 {
+		capacity = int(maxBytes / 256)
+	}
+	return &tinyLFUStore{
+		window:  newLRUList(windowEntries, windowBytes),
+		main:    newLRUList(mainEntries, maxBytes-windowBytes),
+		sketch:  newCountMinSketch(capacity),
+		onEvict: onEvict,
+	}
+}
+
+func (s *tinyLFUStore) get(key string) (interface{}, bool) {
+	s.sketch.increment(key)
+	item, has := s.window.get(key)
+	if !has {
+		item, has = s.main.get(key)
+	}
+	if !has {
+		return nil, false
+	}
+	return item.value, true
+}
+
+func (s *tinyLFUStore) add(key string, value interface{}) {
+	s.sketch.increment(key)
+	item := &localCacheItem{key: key, value: value, size: localCacheItemSize(key, value)}
+	if _, has := s.main.items[key]; has {
+		s.main.push(item)
+		for s.main.overLimit() && s.main.ll.Len() > 1 {
+			s.main.removeOldest()
+			s.onEvict()
+		}
+		return
+	}
+	s.window.push(item)
+	for s.window.overLimit() {
+		candidate := s.window.removeOldest()
+		s.admit(candidate)
+	}
+}
+
+func (s *tinyLFUStore) admit(candidate *localCacheItem) {
+	candidateFrequency := s.sketch.estimate(candidate.key)
+	for !s.main.fits(candidate) {
+		victim := s.main.oldest()
+		if victim == nil {
+			break
+		}
+		if s.sketch.estimate(victim.key) >= candidateFrequency {
+			s.onEvict()
+			return
+		}
+		s.main.removeOldest()
+		s.onEvict()
+	}
+	s.main.push(candidate)
+}
+
+func (s *tinyLFUStore) remove(key string) {
+	if s.window.remove(key) == nil {
+		s.main.remove(key)
+	}
+}
+
+func (s *tinyLFUStore) clear() {
+	s.window.clear()
+	s.main.clear()
+	s.sketch.clear()
+}
+
+func (s *tinyLFUStore) keys(prefix string, target []string) []string {
+	return s.main.keys(prefix, s.window.keys(prefix, target))
+}
+
+func (s *tinyLFUStore) len() int {
+	return s.window.ll.Len() + s.main.ll.Len()
+}
+
+func (s *tinyLFUStore) bytes() int64 {
+	return s.window.size + s.main.size
+}
+
+type countMinSketch struct {
+	rows      [4][]uint8
+	mask      uint64
+	additions int
+	resetAt   int
+}
+
+func newCountMinSketch(capacity int) *countMinSketch {
+	width := 64
+	for width < capacity {
+		width *= 2
+	}
+	sketch := &countMinSketch{mask: uint64(width - 1), resetAt: width * 10}
+	for i := range sketch.rows {
+		sketch.rows[i] = make([]uint8, width)
+	}
+	return sketch
+}
+
+func (s *countMinSketch) index(hash uint64, row int) uint64 {
+	return (hash + uint64(row)*(hash>>32|1)) & s.mask
+}
+
+func (s *countMinSketch) increment(key string) {
+	hash := fnv1a.HashString64(key)
+	for i := range s.rows {
+		position := s.index(hash, i)
+		if s.rows[i][position] < 15 {
+			s.rows[i][position]++
+		}
+	}
+	s.additions++
+	if s.additions >= s.resetAt {
+		for i := range s.rows {
+			for j := range s.rows[i] {
+				s.rows[i][j] >>= 1
+			}
+		}
+		s.additions /= 2
+	}
+}
+
+func (s *countMinSketch) estimate(key string) uint8 {
+	hash := fnv1a.HashString64(key)
+	min := uint8(15)
+	for i := range s.rows {
+		value := s.rows[i][s.index(hash, i)]
+		if value < min {
+			min = value
+		}
+	}
+	return min
+}
+
+func (s *countMinSketch) clear() {
+	for i := range s.rows {
+		for j := range s.rows[i] {
+			s.rows[i][j] = 0
+		}
+	}
+	s.additions = 0
+}
@@ -3,7 +3,9 @@ package beeorm
 import (
 	"io/ioutil"
 	"log"
+	"strconv"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
 )
@@ -43,6 +45,14 @@ func TestLocalCache(t *testing.T) {
 	assert.True(t, has)
 	assert.Equal(t, "hello", val)
 
+	c.SetWithTTL("test_get_ttl", "hello", time.Millisecond*50)
+	val, has = c.Get("test_get_ttl")
+	assert.True(t, has)
+	assert.Equal(t, "hello", val)
+	time.Sleep(time.Millisecond * 60)
+	_, has = c.Get("test_get_ttl")
+	assert.False(t, has)
+
 	engine = validatedRegistry.CreateEngine()
 	engine.RegisterQueryLogger(testLogger, false, false, true)
 	engine.RegisterQueryLogger(testQueryLog, false, false, true)
@@ -91,3 +101,57 @@ func TestLocalCache(t *testing.T) {
 	assert.Nil(t, valuesMap["a"])
 	assert.Nil(t, valuesMap["b"])
 }
+
+func TestLocalCachePolicies(t *testing.T) {
+	for _, policy := range []LocalCachePolicy{LocalCachePolicyLRU, LocalCachePolicyLFU, LocalCachePolicyTinyLFU} {
+		registry := &Registry{}
+		registry.RegisterLocalCacheWithOptions(LocalCacheOptions{MaxEntries: 3, Policy: policy})
+		registry.RegisterLocalCacheWithOptions(LocalCacheOptions{MaxBytes: 1000, Policy: policy, Shards: 4}, "bytes")
+		validatedRegistry, def, err := registry.Validate()
+		assert.Nil(t, err)
+		engine := validatedRegistry.CreateEngine()
+
+		c := engine.GetLocalCache()
+		assert.Equal(t, policy, c.GetPoolConfig().GetPolicy())
+		c.Set("a", "1")
+		c.Set("b", "2")
+		c.Set("c", "3")
+		for i := 0; i < 5; i++ {
+			c.Get("a")
+		}
+		c.Set("d", "4")
+		c.Set("e", "5")
+		assert.LessOrEqual(t, c.GetObjectsCount(), 3)
+		_, has := c.Get("a")
+		assert.True(t, has)
+		_, has = c.Get("missing")
+		assert.False(t, has)
+		stats := c.GetStats()
+		assert.Equal(t, uint64(6), stats.Hits)
+		assert.Equal(t, uint64(1), stats.Misses)
+		assert.GreaterOrEqual(t, stats.Evictions, uint64(2))
+		assert.LessOrEqual(t, stats.Entries, 3)
+
+		c = engine.GetLocalCache("bytes")
+		assert.Equal(t, int64(1000), c.GetPoolConfig().GetMaxBytes())
+		for i := 0; i < 100; i++ {
+			c.Set("key"+strconv.Itoa(i), make([]byte, 100))
+		}
+		stats = c.GetStats()
+		assert.LessOrEqual(t, stats.Bytes, int64(1000))
+		assert.Greater(t, stats.Evictions, uint64(0))
+		def()
+	}
+}
+
+func TestLocalCacheUnknownPolicy(t *testing.T) {
+	registry := &Registry{}
+	registry.RegisterLocalCacheWithOptions(LocalCacheOptions{MaxEntries: 3, Policy: "lfu2"})
+	_, _, err := registry.Validate()
+	assert.EqualError(t, err, "local cache pool 'default' has unknown policy 'lfu2'")
+
+	registry = NewRegistry()
+	registry.InitByYaml(map[string]interface{}{"default": map[string]interface{}{"local_cache": map[string]interface{}{"entries": 10, "policy": "lfu2"}}})
+	_, _, err = registry.Validate()
+	assert.EqualError(t, err, "local cache pool 'default' has unknown policy 'lfu2'")
+}
@@ -2,88 +2,96 @@ package beeorm
 
 import (
 	"context"
+	"crypto/rand"
+	"encoding/hex"
 	"fmt"
+	"strings"
+	"sync"
 	"time"
 
-	"github.com/pkg/errors"
-
 	"github.com/bsm/redislock"
 )
 
-type lockerClient interface {
-	Obtain(ctx context.Context, key string, ttl time.Duration, opt *redislock.Options) (*redislock.Lock, error)
-}
-
-type standardLockerClient struct {
-	client *redislock.Client
-}
+const lockFencingSuffix = ":fencing"
 
-func (l *standardLockerClient) Obtain(ctx context.Context, key string, ttl time.Duration, opt *redislock.Options) (*redislock.Lock, error) {
-	return l.client.Obtain(ctx, key, ttl, opt)
+type lockHandle interface {
+	Release(ctx context.Context) error
+	TTL(ctx context.Context) (time.Duration, error)
+	Refresh(ctx context.Context, ttl time.Duration, opt *redislock.Options) error
 }
 
 type Locker struct {
-	locker lockerClient
-	r      *RedisCache
+	r *RedisCache
 }
 
 func (r *RedisCache) GetLocker() *Locker {
 	if r.locker != nil {
 		return r.locker
 	}
-	lockerClient := &standardLockerClient{client: redislock.New(r.client)}
-	r.locker = &Locker{locker: lockerClient, r: r}
+	r.locker = &Locker{r: r}
 	return r.locker
 }
 
 func (l *Locker) Obtain(key string, ttl time.Duration, waitTimeout time.Duration) (lock *Lock, obtained bool) {
-	key = l.r.addNamespacePrefix(key)
-	if ttl == 0 {
-		panic(errors.New("ttl must be higher than zero"))
-	}
-	var options *redislock.Options
-	if waitTimeout > 0 {
-		minInterval := 16 * time.Millisecond
-		maxInterval := 256 * time.Millisecond
-		max := int(waitTimeout / maxInterval)
-		if max == 0 {
-			max = 1
-		}
-		options = &redislock.Options{RetryStrategy: redislock.LimitRetry(redislock.ExponentialBackoff(minInterval, maxInterval), max)}
-	}
-	start := getNow(l.r.engine.hasRedisLogger)
-	redisLock, err := l.locker.Obtain(context.Background(), key, ttl, options)
-	if err != nil {
-		if err == redislock.ErrNotObtained {
-			if l.r.engine.hasRedisLogger {
-				message := fmt.Sprintf("LOCK OBTAIN %s TTL %s WAIT %s", key, ttl.String(), waitTimeout.String())
-				l.fillLogFields("LOCK OBTAIN", message, start, true, nil)
-			}
-			return nil, false
-		}
+	token := make([]byte, 16)
+	_, err := rand.Read(token)
+	checkError(err)
+	handle := &scriptedLock{r: l.r, key: l.r.addNamespacePrefix(key), owner: hex.EncodeToString(token),
+		release: lockReleaseScript, refresh: lockRefreshScript, ttl: lockTTLScript}
+	return l.obtainScripted(handle, "", lockObtainScript, ttl, waitTimeout, handle.owner)
+}
+
+// lockFencingKey must be in the same cluster slot as lock key because both are used in one script
+func lockFencingKey(key string) string {
+	start := strings.IndexByte(key, '{')
+	if start >= 0 && strings.IndexByte(key[start+1:], '}') > 0 {
+		return key + lockFencingSuffix
 	}
-	if l.r.engine.hasRedisLogger {
-		message := fmt.Sprintf("LOCK OBTAIN %s TTL %s WAIT %s", key, ttl.String(), waitTimeout.String())
-		l.fillLogFields("LOCK OBTAIN", message, start, false, nil)
+	return "{" + key + "}" + lockFencingSuffix
+}
+
+func (l *Locker) newLock(handle lockHandle, key string, ttl time.Duration, token int64) *Lock {
+	return &Lock{lock: handle, locker: l, key: key, has: true, engine: l.r.engine, ttl: ttl, token: token,
+		released: make(chan struct{})}
+}
+
+func lockRetryStrategy(waitTimeout time.Duration) redislock.RetryStrategy {
+	minInterval := 16 * time.Millisecond
+	maxInterval := 256 * time.Millisecond
+	max := int(waitTimeout / maxInterval)
+	if max == 0 {
+		max = 1
 	}
-	checkError(err)
-	lock = &Lock{lock: redisLock, locker: l, key: key, has: true, engine: l.r.engine}
-	return lock, true
+	return redislock.LimitRetry(redislock.ExponentialBackoff(minInterval, maxInterval), max)
 }
 
 type Lock struct {
-	lock   *redislock.Lock
-	key    string
-	locker *Locker
-	has    bool
-	engine *Engine
+	lock     lockHandle
+	key      string
+	locker   *Locker
+	has      bool
+	engine   *Engine
+	ttl      time.Duration
+	token    int64
+	m        sync.Mutex
+	released chan struct{}
+}
+
+// FencingToken is generated in the same script that obtains lock and grows with every lock obtained for the same key.
+// Last token is kept in redis for 24 hours, new tokens are based on redis time so they keep growing after it expires.
+func (l *Lock) FencingToken() int64 {
+	return l.token
 }
 
 func (l *Lock) Release() {
+	l.m.Lock()
 	if !l.has {
+		l.m.Unlock()
 		return
 	}
 	l.has = false
+	close(l.released)
+	l.m.Unlock()
 	start := getNow(l.engine.hasRedisLogger)
 	err := l.lock.Release(context.Background())
 	if err == redislock.ErrLockNotHeld {
@@ -106,8 +114,51 @@ func (l *Lock) TTL() time.Duration {
 }
 
 func (l *Lock) Refresh(ttl time.Duration) bool {
+	has, err := l.refresh(ttl)
+	checkError(err)
+	return has
+}
+
+// KeepAlive refreshes lock in background until ctx is done or lock is released,
+// returned channel is closed when lock is lost
+func (l *Lock) KeepAlive(ctx context.Context) <-chan struct{} {
+	return l.keepAlive(ctx, l.ttl/3)
+}
+
+func (l *Lock) keepAlive(ctx context.Context, interval time.Duration) <-chan struct{} {
+	lost := make(chan struct{})
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		refreshed := time.Now()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-l.released:
+				return
+			case <-ticker.C:
+				has, err := l.refresh(l.ttl)
+				if err == nil && has {
+					refreshed = time.Now()
+					continue
+				}
+				// redis errors are retried as long as lock can still be held
+				if err == nil || time.Since(refreshed) >= l.ttl {
+					close(lost)
+					return
+				}
+			}
+		}
+	}()
+	return lost
+}
+
+func (l *Lock) refresh(ttl time.Duration) (bool, error) {
+	l.m.Lock()
+	defer l.m.Unlock()
 	if !l.has {
-		return false
+		return false, nil
 	}
 	start := getNow(l.engine.hasRedisLogger)
 	err := l.lock.Refresh(context.Background(), ttl, nil)
@@ -116,13 +167,13 @@ func (l *Lock) Refresh(ttl time.Duration) bool {
 		has = false
 		err = nil
 		l.has = false
+		close(l.released)
 	}
 	if l.engine.hasRedisLogger {
 		message := fmt.Sprintf("LOCK REFRESH %s %s", l.key, ttl.String())
 		l.locker.fillLogFields("LOCK REFRESH", message, start, false, err)
 	}
-	checkError(err)
-	return has
+	return has, err
 }
 
 func (l *Locker) fillLogFields(operation, query string, start *time.Time, cacheMiss bool, err error) {
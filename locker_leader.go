@@ -0,0 +1,73 @@
+package beeorm
+
+import (
+	"context"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+type LeaderElection struct {
+	r      *RedisCache
+	key    string
+	ttl    time.Duration
+	leader int32
+}
+
+func (r *RedisCache) LeaderElection(key string, ttl time.Duration) *LeaderElection {
+	if ttl == 0 {
+		panic(errors.New("ttl must be higher than zero"))
+	}
+	return &LeaderElection{r: r, key: key, ttl: ttl}
+}
+
+func (e *LeaderElection) IsLeader() bool {
+	return atomic.LoadInt32(&e.leader) == 1
+}
+
+// Run blocks until ctx is cancelled. onElected is executed when leadership is gained with context
+// cancelled when it is lost, onLost is called after onElected returns.
+// When lock expires another replica can be elected before onElected returns, so onElected must stop
+// its work as soon as its context is cancelled.
+func (e *LeaderElection) Run(ctx context.Context, onElected func(ctx context.Context), onLost func()) {
+	locker := e.r.GetLocker()
+	for {
+		lock, has := locker.Obtain(e.key, e.ttl, 0)
+		if has {
+			e.lead(ctx, lock, onElected)
+			if onLost != nil {
+				onLost()
+			}
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(e.ttl / 3):
+		}
+	}
+}
+
+func (e *LeaderElection) lead(ctx context.Context, lock *Lock, onElected func(ctx context.Context)) {
+	atomic.StoreInt32(&e.leader, 1)
+	leaderCtx, cancel := context.WithCancel(ctx)
+	lost := lock.KeepAlive(leaderCtx)
+	wg := sync.WaitGroup{}
+	if onElected != nil {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			onElected(leaderCtx)
+		}()
+	}
+	select {
+	case <-ctx.Done():
+	case <-lost:
+	}
+	atomic.StoreInt32(&e.leader, 0)
+	cancel()
+	// lock is kept until onElected returns, unless it has already expired
+	wg.Wait()
+	lock.Release()
+}
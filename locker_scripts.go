@@ -0,0 +1,268 @@
+package beeorm
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"time"
+
+	"github.com/bsm/redislock"
+	"github.com/pkg/errors"
+)
+
+const lockScriptNow = `
+redis.replicate_commands()
+local t = redis.call('TIME')
+local now = tonumber(t[1]) * 1000 + math.floor(tonumber(t[2]) / 1000)
+`
+
+// fencing token is stored in KEYS[2], lockScriptNow must be included before
+const lockScriptFencingToken = `
+local function fencingToken()
+	local token = tonumber(t[1]) * 1000000 + tonumber(t[2])
+	local last = tonumber(redis.call('GET', KEYS[2]) or '0')
+	if token <= last then
+		token = last + 1
+	end
+	redis.call('SET', KEYS[2], string.format('%d', token), 'PX', 86400000)
+	return token
+end
+`
+
+var lockObtainScript = newRedisScript("orm-lock-obtain", lockScriptNow+lockScriptFencingToken+`
+if not redis.call('SET', KEYS[1], ARGV[1], 'NX', 'PX', ARGV[2]) then
+	return 0
+end
+return fencingToken()
+`)
+
+var lockReleaseScript = newRedisScript("orm-lock-release", `
+if redis.call('GET', KEYS[1]) ~= ARGV[1] then
+	return 0
+end
+return redis.call('DEL', KEYS[1])
+`)
+
+var lockRefreshScript = newRedisScript("orm-lock-refresh", `
+if redis.call('GET', KEYS[1]) ~= ARGV[1] then
+	return 0
+end
+return redis.call('PEXPIRE', KEYS[1], ARGV[2])
+`)
+
+var lockTTLScript = newRedisScript("orm-lock-ttl", `
+if redis.call('GET', KEYS[1]) ~= ARGV[1] then
+	return 0
+end
+return redis.call('PTTL', KEYS[1])
+`)
+
+var lockReentrantObtainScript = newRedisScript("orm-lock-reentrant-obtain", lockScriptNow+lockScriptFencingToken+`
+local owner = redis.call('HGET', KEYS[1], 'owner')
+if owner and owner ~= ARGV[1] then
+	return 0
+end
+redis.call('HSET', KEYS[1], 'owner', ARGV[1])
+redis.call('HINCRBY', KEYS[1], 'count', 1)
+redis.call('PEXPIRE', KEYS[1], ARGV[2])
+return fencingToken()
+`)
+
+var lockReentrantReleaseScript = newRedisScript("orm-lock-reentrant-release", `
+if redis.call('HGET', KEYS[1], 'owner') ~= ARGV[1] then
+	return 0
+end
+if redis.call('HINCRBY', KEYS[1], 'count', -1) <= 0 then
+	redis.call('DEL', KEYS[1])
+end
+return 1
+`)
+
+var lockReentrantRefreshScript = newRedisScript("orm-lock-reentrant-refresh", `
+if redis.call('HGET', KEYS[1], 'owner') ~= ARGV[1] then
+	return 0
+end
+redis.call('PEXPIRE', KEYS[1], ARGV[2])
+return 1
+`)
+
+var lockReentrantTTLScript = newRedisScript("orm-lock-reentrant-ttl", `
+if redis.call('HGET', KEYS[1], 'owner') ~= ARGV[1] then
+	return 0
+end
+return redis.call('PTTL', KEYS[1])
+`)
+
+// hash holds lock mode and expiration time of every holder
+var lockRWObtainScript = newRedisScript("orm-lock-rw-obtain", lockScriptNow+lockScriptFencingToken+`
+local fields = redis.call('HGETALL', KEYS[1])
+local holders = 0
+for i = 1, #fields, 2 do
+	if fields[i] ~= 'mode' then
+		if tonumber(fields[i + 1]) <= now then
+			redis.call('HDEL', KEYS[1], fields[i])
+		else
+			holders = holders + 1
+		end
+	end
+end
+if holders > 0 and (ARGV[1] == 'w' or redis.call('HGET', KEYS[1], 'mode') == 'w') then
+	return 0
+end
+redis.call('HMSET', KEYS[1], 'mode', ARGV[1], ARGV[2], now + tonumber(ARGV[3]))
+if redis.call('PTTL', KEYS[1]) < tonumber(ARGV[3]) then
+	redis.call('PEXPIRE', KEYS[1], ARGV[3])
+end
+return fencingToken()
+`)
+
+var lockRWReleaseScript = newRedisScript("orm-lock-rw-release", `
+if redis.call('HDEL', KEYS[1], ARGV[1]) == 0 then
+	return 0
+end
+if redis.call('HLEN', KEYS[1]) <= 1 then
+	redis.call('DEL', KEYS[1])
+end
+return 1
+`)
+
+var lockRWRefreshScript = newRedisScript("orm-lock-rw-refresh", lockScriptNow+`
+local expire = redis.call('HGET', KEYS[1], ARGV[1])
+if not expire or tonumber(expire) <= now then
+	return 0
+end
+redis.call('HSET', KEYS[1], ARGV[1], now + tonumber(ARGV[2]))
+if redis.call('PTTL', KEYS[1]) < tonumber(ARGV[2]) then
+	redis.call('PEXPIRE', KEYS[1], ARGV[2])
+end
+return 1
+`)
+
+var lockRWTTLScript = newRedisScript("orm-lock-rw-ttl", lockScriptNow+`
+local expire = redis.call('HGET', KEYS[1], ARGV[1])
+if not expire or tonumber(expire) <= now then
+	return 0
+end
+return tonumber(expire) - now
+`)
+
+type scriptedLock struct {
+	r       *RedisCache
+	key     string
+	owner   string
+	release *redisScript
+	refresh *redisScript
+	ttl     *redisScript
+}
+
+func (s *scriptedLock) Release(_ context.Context) error {
+	res, err := s.r.evalScript(s.release, []string{s.key}, s.owner)
+	if err != nil {
+		return err
+	}
+	if res.(int64) == 0 {
+		return redislock.ErrLockNotHeld
+	}
+	return nil
+}
+
+func (s *scriptedLock) TTL(_ context.Context) (time.Duration, error) {
+	res, err := s.r.evalScript(s.ttl, []string{s.key}, s.owner)
+	if err != nil {
+		return 0, err
+	}
+	ttl := res.(int64)
+	if ttl < 0 {
+		ttl = 0
+	}
+	return time.Duration(ttl) * time.Millisecond, nil
+}
+
+func (s *scriptedLock) Refresh(_ context.Context, ttl time.Duration, _ *redislock.Options) error {
+	res, err := s.r.evalScript(s.refresh, []string{s.key}, s.owner, ttl.Milliseconds())
+	if err != nil {
+		return err
+	}
+	if res.(int64) == 0 {
+		return redislock.ErrNotObtained
+	}
+	return nil
+}
+
+// ObtainReentrant can be called many times by the same owner, lock is free when all of them are released
+func (l *Locker) ObtainReentrant(key, owner string, ttl time.Duration, waitTimeout time.Duration) (lock *Lock, obtained bool) {
+	if owner == "" {
+		panic(errors.New("lock owner can't be empty"))
+	}
+	handle := &scriptedLock{r: l.r, key: l.r.addNamespacePrefix(key), owner: owner, release: lockReentrantReleaseScript,
+		refresh: lockReentrantRefreshScript, ttl: lockReentrantTTLScript}
+	return l.obtainScripted(handle, "REENTRANT", lockReentrantObtainScript, ttl, waitTimeout, owner)
+}
+
+// ObtainRead can be held by many holders at the same time as long as there is no write lock
+func (l *Locker) ObtainRead(key string, ttl time.Duration, waitTimeout time.Duration) (lock *Lock, obtained bool) {
+	return l.obtainRW(key, "r", "READ", ttl, waitTimeout)
+}
+
+// ObtainWrite is granted only when there are no read or write holders
+func (l *Locker) ObtainWrite(key string, ttl time.Duration, waitTimeout time.Duration) (lock *Lock, obtained bool) {
+	return l.obtainRW(key, "w", "WRITE", ttl, waitTimeout)
+}
+
+func (l *Locker) obtainRW(key, mode, name string, ttl time.Duration, waitTimeout time.Duration) (*Lock, bool) {
+	token := make([]byte, 16)
+	_, err := rand.Read(token)
+	checkError(err)
+	handle := &scriptedLock{r: l.r, key: l.r.addNamespacePrefix(key), owner: hex.EncodeToString(token),
+		release: lockRWReleaseScript, refresh: lockRWRefreshScript, ttl: lockRWTTLScript}
+	return l.obtainScripted(handle, name, lockRWObtainScript, ttl, waitTimeout, mode, handle.owner)
+}
+
+func (l *Locker) obtainScripted(handle *scriptedLock, name string, script *redisScript, ttl, waitTimeout time.Duration, args ...interface{}) (*Lock, bool) {
+	if ttl == 0 {
+		panic(errors.New("ttl must be higher than zero"))
+	}
+	args = append(args, ttl.Milliseconds())
+	var retry redislock.RetryStrategy
+	if waitTimeout > 0 {
+		retry = lockRetryStrategy(waitTimeout)
+	} else {
+		retry = redislock.NoRetry()
+	}
+	start := getNow(l.r.engine.hasRedisLogger)
+	keys := []string{handle.key, lockFencingKey(handle.key)}
+	token := int64(0)
+	for {
+		res, err := l.r.evalScript(script, keys, args...)
+		if err == nil && res.(int64) > 0 {
+			token = res.(int64)
+			break
+		}
+		if err != nil {
+			if l.r.engine.hasRedisLogger {
+				l.fillLogFields("LOCK OBTAIN", lockObtainMessage(name, handle.key, ttl, waitTimeout), start, false, err)
+			}
+			checkError(err)
+		}
+		backoff := retry.NextBackoff()
+		if backoff < 1 {
+			break
+		}
+		time.Sleep(backoff)
+	}
+	if l.r.engine.hasRedisLogger {
+		l.fillLogFields("LOCK OBTAIN", lockObtainMessage(name, handle.key, ttl, waitTimeout), start, token == 0, nil)
+	}
+	if token == 0 {
+		return nil, false
+	}
+	return l.newLock(handle, handle.key, ttl, token), true
+}
+
+func lockObtainMessage(name, key string, ttl, waitTimeout time.Duration) string {
+	if name != "" {
+		key = name + " " + key
+	}
+	return fmt.Sprintf("LOCK OBTAIN %s TTL %s WAIT %s", key, ttl.String(), waitTimeout.String())
+}
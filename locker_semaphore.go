@@ -0,0 +1,73 @@
+package beeorm
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+// sorted set holds every permit holder with its expiration time as score
+var semaphoreAcquireScript = newRedisScript("orm-semaphore-acquire", lockScriptNow+lockScriptFencingToken+`
+redis.call('ZREMRANGEBYSCORE', KEYS[1], '-inf', now)
+if redis.call('ZCARD', KEYS[1]) >= tonumber(ARGV[1]) then
+	return 0
+end
+redis.call('ZADD', KEYS[1], now + tonumber(ARGV[3]), ARGV[2])
+if redis.call('PTTL', KEYS[1]) < tonumber(ARGV[3]) then
+	redis.call('PEXPIRE', KEYS[1], ARGV[3])
+end
+return fencingToken()
+`)
+
+var semaphoreReleaseScript = newRedisScript("orm-semaphore-release", `
+return redis.call('ZREM', KEYS[1], ARGV[1])
+`)
+
+var semaphoreRefreshScript = newRedisScript("orm-semaphore-refresh", lockScriptNow+`
+local expire = redis.call('ZSCORE', KEYS[1], ARGV[1])
+if not expire or tonumber(expire) <= now then
+	return 0
+end
+redis.call('ZADD', KEYS[1], now + tonumber(ARGV[2]), ARGV[1])
+if redis.call('PTTL', KEYS[1]) < tonumber(ARGV[2]) then
+	redis.call('PEXPIRE', KEYS[1], ARGV[2])
+end
+return 1
+`)
+
+var semaphoreTTLScript = newRedisScript("orm-semaphore-ttl", lockScriptNow+`
+local expire = redis.call('ZSCORE', KEYS[1], ARGV[1])
+if not expire or tonumber(expire) <= now then
+	return 0
+end
+return tonumber(expire) - now
+`)
+
+type Semaphore struct {
+	r       *RedisCache
+	key     string
+	permits int
+}
+
+func (r *RedisCache) GetSemaphore(key string, permits int) *Semaphore {
+	if permits <= 0 {
+		panic(errors.New("semaphore permits must be higher than zero"))
+	}
+	return &Semaphore{r: r, key: key, permits: permits}
+}
+
+// Acquire returns permit that expires after ttl unless it is refreshed, so permits of crashed holders are recovered
+func (s *Semaphore) Acquire(ttl time.Duration, waitTimeout time.Duration) (permit *Lock, acquired bool) {
+	token := make([]byte, 16)
+	_, err := rand.Read(token)
+	checkError(err)
+	handle := &scriptedLock{r: s.r, key: s.r.addNamespacePrefix(s.key), owner: hex.EncodeToString(token),
+		release: semaphoreReleaseScript, refresh: semaphoreRefreshScript, ttl: semaphoreTTLScript}
+	return s.r.GetLocker().obtainScripted(handle, "SEMAPHORE", semaphoreAcquireScript, ttl, waitTimeout, s.permits, handle.owner)
+}
+
+func (s *Semaphore) Release(permit *Lock) {
+	permit.Release()
+}
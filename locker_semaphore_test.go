@@ -0,0 +1,89 @@
+package beeorm
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSemaphore(t *testing.T) {
+	l, def := prepareLocker(t)
+	defer def()
+	r := l.r
+	semaphore := r.GetSemaphore("test_semaphore", 2)
+	permit1, has := semaphore.Acquire(time.Second, 0)
+	assert.True(t, has)
+	permit2, has := semaphore.Acquire(time.Second, 0)
+	assert.True(t, has)
+	_, has = semaphore.Acquire(time.Second, time.Millisecond)
+	assert.False(t, has)
+	assert.True(t, permit1.Refresh(time.Second))
+	assert.Greater(t, permit1.TTL(), time.Duration(0))
+
+	semaphore.Release(permit1)
+	permit3, has := semaphore.Acquire(time.Second, 0)
+	assert.True(t, has)
+	semaphore.Release(permit2)
+	semaphore.Release(permit3)
+
+	crashed, has := r.GetSemaphore("test_semaphore", 1).Acquire(time.Millisecond*50, 0)
+	assert.True(t, has)
+	assert.NotNil(t, crashed)
+	permit, has := r.GetSemaphore("test_semaphore", 1).Acquire(time.Second, time.Second)
+	assert.True(t, has)
+	assert.False(t, crashed.Refresh(time.Second))
+	permit.Release()
+
+	assert.PanicsWithError(t, "semaphore permits must be higher than zero", func() {
+		r.GetSemaphore("test_semaphore", 0)
+	})
+}
+
+func TestLeaderElection(t *testing.T) {
+	l, def := prepareLocker(t)
+	defer def()
+	r := l.r
+	elected := make(chan int, 10)
+	lost := make(chan int, 10)
+	ctx1, cancel1 := context.WithCancel(context.Background())
+	ctx2, cancel2 := context.WithCancel(context.Background())
+	defer cancel2()
+	election1 := r.LeaderElection("test_leader", time.Millisecond*300)
+	election2 := r.LeaderElection("test_leader", time.Millisecond*300)
+	go election1.Run(ctx1, func(ctx context.Context) {
+		elected <- 1
+		<-ctx.Done()
+	}, func() {
+		lost <- 1
+	})
+	assert.Equal(t, 1, <-elected)
+	go election2.Run(ctx2, func(ctx context.Context) {
+		elected <- 2
+		<-ctx.Done()
+	}, func() {
+		lost <- 2
+	})
+	time.Sleep(time.Millisecond * 500)
+	assert.True(t, election1.IsLeader())
+	assert.False(t, election2.IsLeader())
+
+	cancel1()
+	assert.Equal(t, 1, <-lost)
+	select {
+	case leader := <-elected:
+		assert.Equal(t, 2, leader)
+	case <-time.After(time.Second):
+		assert.Fail(t, "new leader not elected")
+	}
+	assert.True(t, election2.IsLeader())
+
+	r.Del("test_leader")
+	select {
+	case leader := <-lost:
+		assert.Equal(t, 2, leader)
+	case <-time.After(time.Second):
+		assert.Fail(t, "lost leadership not detected")
+	}
+}
@@ -1,6 +1,7 @@
 package beeorm
 
 import (
+	"context"
 	"testing"
 	"time"
 
@@ -48,3 +49,158 @@ func testLocker(t *testing.T, namespace string) {
 		_, _ = l.Obtain("test_key", 0, time.Millisecond)
 	})
 }
+
+func TestLockerKeepAlive(t *testing.T) {
+	l, def := prepareLocker(t)
+	defer def()
+	lock, has := l.Obtain("test_keep_alive", time.Millisecond*300, 0)
+	assert.True(t, has)
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	lost := lock.KeepAlive(ctx)
+	time.Sleep(time.Millisecond * 600)
+	_, has = l.Obtain("test_keep_alive", time.Second, 0)
+	assert.False(t, has)
+	select {
+	case <-lost:
+		assert.Fail(t, "lock lost")
+	default:
+	}
+
+	l.r.Del("test_keep_alive")
+	select {
+	case <-lost:
+	case <-time.After(time.Second):
+		assert.Fail(t, "lost lock not detected")
+	}
+
+	lock, has = l.Obtain("test_keep_alive", time.Millisecond*300, 0)
+	assert.True(t, has)
+	lost = lock.KeepAlive(ctx)
+	lock.Release()
+	time.Sleep(time.Millisecond * 200)
+	select {
+	case <-lost:
+		assert.Fail(t, "released lock reported as lost")
+	default:
+	}
+}
+
+func TestLockerFencingToken(t *testing.T) {
+	l, def := prepareLocker(t)
+	defer def()
+	lock, has := l.Obtain("test_fencing", time.Second, 0)
+	assert.True(t, has)
+	first := lock.FencingToken()
+	assert.Greater(t, first, int64(0))
+	lock.Release()
+	lock, has = l.Obtain("test_fencing", time.Second, 0)
+	assert.True(t, has)
+	assert.Greater(t, lock.FencingToken(), first)
+	lock.Release()
+}
+
+func TestLockerFencingTokenExpiredHolder(t *testing.T) {
+	l, def := prepareLocker(t)
+	defer def()
+	stalled, has := l.Obtain("test_fencing_expired", time.Millisecond*100, 0)
+	assert.True(t, has)
+	time.Sleep(time.Millisecond * 150)
+	lock, has := l.Obtain("test_fencing_expired", time.Second, 0)
+	assert.True(t, has)
+	assert.Greater(t, lock.FencingToken(), stalled.FencingToken())
+	assert.False(t, stalled.Refresh(time.Second))
+	stalled.Release()
+	assert.Greater(t, lock.TTL(), time.Duration(0))
+	lock.Release()
+
+	semaphore := l.r.GetSemaphore("test_fencing_expired", 1)
+	permit, has := semaphore.Acquire(time.Second, 0)
+	assert.True(t, has)
+	assert.Greater(t, permit.FencingToken(), lock.FencingToken())
+	permit.Release()
+}
+
+func TestLockerReentrant(t *testing.T) {
+	l, def := prepareLocker(t)
+	defer def()
+	lock, has := l.ObtainReentrant("test_reentrant", "owner-a", time.Second, 0)
+	assert.True(t, has)
+	nested, has := l.ObtainReentrant("test_reentrant", "owner-a", time.Second, 0)
+	assert.True(t, has)
+	assert.Greater(t, nested.FencingToken(), lock.FencingToken())
+	_, has = l.ObtainReentrant("test_reentrant", "owner-b", time.Second, time.Millisecond)
+	assert.False(t, has)
+	assert.True(t, nested.Refresh(time.Second))
+	assert.Greater(t, nested.TTL(), time.Duration(0))
+
+	nested.Release()
+	_, has = l.ObtainReentrant("test_reentrant", "owner-b", time.Second, 0)
+	assert.False(t, has)
+	lock.Release()
+	other, has := l.ObtainReentrant("test_reentrant", "owner-b", time.Second, 0)
+	assert.True(t, has)
+	assert.False(t, lock.Refresh(time.Second))
+	other.Release()
+
+	assert.PanicsWithError(t, "lock owner can't be empty", func() {
+		_, _ = l.ObtainReentrant("test_reentrant", "", time.Second, 0)
+	})
+}
+
+func TestLockerReadWrite(t *testing.T) {
+	l, def := prepareLocker(t)
+	defer def()
+	read1, has := l.ObtainRead("test_rw", time.Second, 0)
+	assert.True(t, has)
+	read2, has := l.ObtainRead("test_rw", time.Second, 0)
+	assert.True(t, has)
+	_, has = l.ObtainWrite("test_rw", time.Second, time.Millisecond)
+	assert.False(t, has)
+	assert.True(t, read1.Refresh(time.Second))
+	assert.Greater(t, read1.TTL(), time.Duration(0))
+
+	read1.Release()
+	_, has = l.ObtainWrite("test_rw", time.Second, 0)
+	assert.False(t, has)
+	read2.Release()
+	assert.Equal(t, time.Duration(0), read2.TTL())
+
+	write, has := l.ObtainWrite("test_rw", time.Second, 0)
+	assert.True(t, has)
+	_, has = l.ObtainRead("test_rw", time.Second, 0)
+	assert.False(t, has)
+	_, has = l.ObtainWrite("test_rw", time.Second, 0)
+	assert.False(t, has)
+	go func() {
+		time.Sleep(time.Millisecond * 100)
+		write.Release()
+	}()
+	read, has := l.ObtainRead("test_rw", time.Second, time.Second)
+	assert.True(t, has)
+	read.Release()
+
+	expired, has := l.ObtainRead("test_rw", time.Millisecond*50, 0)
+	assert.True(t, has)
+	time.Sleep(time.Millisecond * 100)
+	assert.False(t, expired.Refresh(time.Second))
+	write, has = l.ObtainWrite("test_rw", time.Second, 0)
+	assert.True(t, has)
+	write.Release()
+	assert.Equal(t, int64(0), l.r.Exists("test_rw"))
+}
+
+func prepareLocker(t *testing.T) (*Locker, func()) {
+	registry := &Registry{}
+	registry.RegisterRedis("localhost:6382", "", 15)
+	validatedRegistry, def, err := registry.Validate()
+	assert.Nil(t, err)
+	engine := validatedRegistry.CreateEngine()
+	engine.GetRedis().FlushDB()
+	return engine.GetRedis().GetLocker(), def
+}
+
+func TestLockFencingKey(t *testing.T) {
+	assert.Equal(t, "{test}:fencing", lockFencingKey("test"))
+	assert.Equal(t, "ns:{test}:a:fencing", lockFencingKey("ns:{test}:a"))
+}
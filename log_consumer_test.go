@@ -3,6 +3,9 @@ package beeorm
 import (
 	"context"
 	"database/sql"
+	"io/ioutil"
+	"path/filepath"
+	"strings"
 	"testing"
 	"time"
 
@@ -203,3 +206,189 @@ func TestLogReceiver(t *testing.T) {
 	assert.Equal(t, "COMMIT", logger.Logs[0]["operation"])
 	assert.Equal(t, "PIPELINE EXEC", logger.Logs[1]["operation"])
 }
+
+type logRetentionEntity struct {
+	ORM  `orm:"log;logRetention=1d;logArchive"`
+	ID   uint
+	Name string
+}
+
+func TestLogRetention(t *testing.T) {
+	var entity *logRetentionEntity
+	registry := &Registry{}
+	engine, def := prepareTables(t, registry, 5, "", "2.0", entity)
+	defer def()
+	engine.GetMysql().Exec("TRUNCATE TABLE `_log_default_logRetentionEntity`")
+	engine.GetMysql().Exec("TRUNCATE TABLE `_log_default_logRetentionEntity_archive`")
+
+	old := time.Now().Add(-time.Hour * 48).Format(timeFormat)
+	fresh := time.Now().Format(timeFormat)
+	engine.GetMysql().Exec("INSERT INTO `_log_default_logRetentionEntity`(`entity_id`, `added_at`, `changes`) VALUES(1, ?, '{}'), (2, ?, '{}'), (3, ?, '{}')",
+		old, old, fresh)
+
+	consumer := NewBackgroundConsumer(engine)
+	consumer.EnforceLogRetention()
+
+	var total int
+	engine.GetMysql().QueryRow(NewWhere("SELECT COUNT(*) FROM `_log_default_logRetentionEntity`"), &total)
+	assert.Equal(t, 1, total)
+	engine.GetMysql().QueryRow(NewWhere("SELECT COUNT(*) FROM `_log_default_logRetentionEntity_archive`"), &total)
+	assert.Equal(t, 2, total)
+
+	type invalidRetention struct {
+		ORM `orm:"log;logRetention=abc"`
+		ID  uint
+	}
+	registry = NewRegistry()
+	registry.RegisterEntity(&invalidRetention{})
+	registry.RegisterMySQLPool("root:root@tcp(localhost:3311)/test")
+	_, _, err := registry.Validate()
+	assert.EqualError(t, err, "invalid log retention 'abc' in beeorm.invalidRetention")
+
+	type partitionedFileLog struct {
+		ORM `orm:"logSink=file;logPartition"`
+		ID  uint
+	}
+	registry = NewRegistry()
+	registry.RegisterEntity(&partitionedFileLog{})
+	registry.RegisterMySQLPool("root:root@tcp(localhost:3311)/test")
+	registry.RegisterLogSink(NewFileLogSink("file", filepath.Join(t.TempDir(), "entity.log"), 1024, 2))
+	_, _, err = registry.Validate()
+	assert.EqualError(t, err, "log partition requires MySQL log sink in beeorm.partitionedFileLog")
+
+	type archivedFileLog struct {
+		ORM `orm:"logSink=file;logArchive"`
+		ID  uint
+	}
+	registry = NewRegistry()
+	registry.RegisterEntity(&archivedFileLog{})
+	registry.RegisterMySQLPool("root:root@tcp(localhost:3311)/test")
+	registry.RegisterLogSink(NewFileLogSink("file", filepath.Join(t.TempDir(), "entity.log"), 1024, 2))
+	_, _, err = registry.Validate()
+	assert.EqualError(t, err, "log archive requires MySQL log sink in beeorm.archivedFileLog")
+
+	assert.PanicsWithError(t, "only one log sink can be forced in all entities", func() {
+		NewRegistry().ForceEntityLogInAllEntities("default", NewFileLogSink("a", "a.log", 1024, 2), NewFileLogSink("b", "b.log", 1024, 2))
+	})
+}
+
+type logPartitionEntity struct {
+	ORM  `orm:"log;logRetention=30d;logPartition"`
+	ID   uint
+	Name string
+}
+
+func TestLogPartitions(t *testing.T) {
+	var entity *logPartitionEntity
+	registry := &Registry{}
+	engine, def := prepareTables(t, registry, 5, "", "2.0", entity)
+	defer def()
+	schema := engine.GetRegistry().GetTableSchemaForEntity(entity).(*tableSchema)
+	db := engine.GetMysql()
+	db.Exec("DROP TABLE `_log_default_logPartitionEntity`")
+	for _, alter := range engine.GetAlters() {
+		alter.Exec()
+	}
+	month := logMonthStart(time.Now())
+	partitions := getLogPartitions(db, schema.logTableName)
+	assert.Equal(t, []string{"p" + month.Format(logPartitionFormat), "p" + month.AddDate(0, 1, 0).Format(logPartitionFormat),
+		"p" + month.AddDate(0, 2, 0).Format(logPartitionFormat), "p" + month.AddDate(0, 3, 0).Format(logPartitionFormat), "pmax"}, partitions)
+	assert.Equal(t, "", buildLogPartitionsRollSQL(db, schema.logTableName, time.Now()))
+
+	future := month.AddDate(0, 2, 0)
+	assert.Equal(t, "ALTER TABLE `test`.`_log_default_logPartitionEntity` REORGANIZE PARTITION pmax INTO (\n"+
+		buildLogPartitionSQL(month.AddDate(0, 4, 0))+",\n"+buildLogPartitionSQL(month.AddDate(0, 5, 0))+
+		",\nPARTITION pmax VALUES LESS THAN MAXVALUE);", buildLogPartitionsRollSQL(db, schema.logTableName, future))
+	rollLogPartitions(engine, schema, future)
+	partitions = getLogPartitions(db, schema.logTableName)
+	assert.Len(t, partitions, 7)
+	assert.Equal(t, "p"+month.AddDate(0, 5, 0).Format(logPartitionFormat), partitions[5])
+	assert.Equal(t, "", buildLogPartitionsRollSQL(db, schema.logTableName, future))
+
+	added := time.Now().Format(timeFormat)
+	db.Exec("INSERT INTO `_log_default_logPartitionEntity`(`entity_id`, `added_at`, `changes`) VALUES(1, ?, '{}')", added)
+	enforceLogRetention(engine, schema, month.AddDate(0, 3, 0))
+	partitions = getLogPartitions(db, schema.logTableName)
+	assert.NotContains(t, partitions, "p"+month.Format(logPartitionFormat))
+	assert.NotContains(t, partitions, "p"+month.AddDate(0, 1, 0).Format(logPartitionFormat))
+	assert.Contains(t, partitions, "p"+month.AddDate(0, 2, 0).Format(logPartitionFormat))
+	var total int
+	db.QueryRow(NewWhere("SELECT COUNT(*) FROM `_log_default_logPartitionEntity`"), &total)
+	assert.Equal(t, 0, total)
+}
+
+func TestLogPartitionsSQL(t *testing.T) {
+	now := time.Date(2021, 11, 15, 10, 0, 0, 0, time.Local)
+	assert.Equal(t, "PARTITION BY RANGE (TO_DAYS(`added_at`))\n("+
+		"PARTITION p202111 VALUES LESS THAN (TO_DAYS('2021-12-01')),\n"+
+		"PARTITION p202112 VALUES LESS THAN (TO_DAYS('2022-01-01')),\n"+
+		"PARTITION p202201 VALUES LESS THAN (TO_DAYS('2022-02-01')),\n"+
+		"PARTITION p202202 VALUES LESS THAN (TO_DAYS('2022-03-01')),\n"+
+		"PARTITION pmax VALUES LESS THAN MAXVALUE)", buildLogPartitionsSQL(now))
+}
+
+type logSinkEntity1 struct {
+	ORM  `orm:"logSink=file"`
+	ID   uint
+	Name string
+}
+
+type logSinkEntity2 struct {
+	ORM  `orm:"logSink=stream"`
+	ID   uint
+	Name string
+}
+
+func TestLogSinks(t *testing.T) {
+	var entity1 *logSinkEntity1
+	var entity2 *logSinkEntity2
+	path := filepath.Join(t.TempDir(), "entity.log")
+	registry := &Registry{}
+	registry.RegisterRedisStream("entity-log-stream", "default", []string{"test-group"})
+	registry.RegisterLogSink(NewFileLogSink("file", path, 1024, 2), NewRedisStreamLogSink("stream", "entity-log-stream"))
+	engine, def := prepareTables(t, registry, 5, "", "2.0", entity1, entity2)
+	defer def()
+	engine.GetRedis().FlushDB()
+
+	consumer := NewBackgroundConsumer(engine)
+	consumer.DisableLoop()
+	consumer.blockTime = time.Millisecond
+
+	engine.Flush(&logSinkEntity1{Name: "John"})
+	engine.Flush(&logSinkEntity2{Name: "Tom"})
+	consumer.Digest(context.Background())
+
+	content, err := ioutil.ReadFile(path)
+	assert.NoError(t, err)
+	lines := strings.Split(strings.TrimSpace(string(content)), "\n")
+	assert.Len(t, lines, 1)
+	assert.Contains(t, lines[0], "\"table\":\"_log_default_logSinkEntity1\"")
+	assert.Contains(t, lines[0], "\"changes\":{\"Name\":\"John\"}")
+
+	streamConsumer := engine.GetEventBroker().Consumer("test-group")
+	streamConsumer.(*eventsConsumer).blockTime = time.Millisecond
+	streamConsumer.DisableLoop()
+	valid := false
+	streamConsumer.Consume(context.Background(), 10, func(events []Event) {
+		assert.Len(t, events, 1)
+		var value LogQueueValue
+		events[0].Unserialize(&value)
+		assert.Equal(t, uint64(1), value.ID)
+		assert.Equal(t, "Tom", value.Changes["Name"])
+		valid = true
+	})
+	assert.True(t, valid)
+
+	for i := 0; i < 20; i++ {
+		engine.Flush(&logSinkEntity1{Name: "John"})
+	}
+	consumer.Digest(context.Background())
+	_, err = ioutil.ReadFile(path + ".1")
+	assert.NoError(t, err)
+
+	registry = NewRegistry()
+	registry.RegisterEntity(&logSinkEntity1{})
+	registry.RegisterMySQLPool("root:root@tcp(localhost:3311)/test")
+	_, _, err = registry.Validate()
+	assert.EqualError(t, err, "log sink 'file' not found")
+}
@@ -0,0 +1,222 @@
+package beeorm
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const logRetentionBatchSize = 1000
+const logRetentionInterval = 3600
+const logRetentionTick = time.Minute
+const logRetentionLockKey = "orm_log_retention"
+const logPartitionsAhead = 3
+const logPartitionFormat = "200601"
+
+func (tableSchema *tableSchema) initLogRetention() error {
+	retention := tableSchema.getTag("logRetention", "", "")
+	tableSchema.logArchive = tableSchema.getTag("logArchive", "true", "") == "true"
+	tableSchema.logPartitioned = tableSchema.getTag("logPartition", "true", "") == "true"
+	tableSchema.logArchiveTableName = tableSchema.logTableName + "_archive"
+	if (retention != "" || tableSchema.logArchive || tableSchema.logPartitioned) && !tableSchema.hasLog {
+		return fmt.Errorf("log retention requires entity log in %s", tableSchema.t.String())
+	}
+	if retention != "" && tableSchema.logSinkCode != "" {
+		return fmt.Errorf("log retention requires MySQL log sink in %s", tableSchema.t.String())
+	}
+	if tableSchema.logArchive && tableSchema.logSinkCode != "" {
+		return fmt.Errorf("log archive requires MySQL log sink in %s", tableSchema.t.String())
+	}
+	if tableSchema.logPartitioned && tableSchema.logSinkCode != "" {
+		return fmt.Errorf("log partition requires MySQL log sink in %s", tableSchema.t.String())
+	}
+	if retention != "" {
+		duration, err := parseLogRetention(retention)
+		if err != nil || duration <= 0 {
+			return fmt.Errorf("invalid log retention '%s' in %s", retention, tableSchema.t.String())
+		}
+		tableSchema.logRetention = duration
+	}
+	if tableSchema.logArchive && tableSchema.logRetention == 0 {
+		return fmt.Errorf("log archive requires log retention in %s", tableSchema.t.String())
+	}
+	return nil
+}
+
+func parseLogRetention(value string) (time.Duration, error) {
+	if strings.HasSuffix(value, "d") {
+		days, err := strconv.ParseUint(value[0:len(value)-1], 10, 64)
+		if err != nil {
+			return 0, err
+		}
+		return time.Duration(days) * time.Hour * 24, nil
+	}
+	return time.ParseDuration(value)
+}
+
+// EnforceLogRetention creates missing partitions of partitioned log tables and removes or archives expired logs
+func (r *BackgroundConsumer) EnforceLogRetention() {
+	now := time.Now()
+	for _, schema := range r.engine.registry.tableSchemas {
+		if !schema.hasLog {
+			continue
+		}
+		if schema.logPartitioned {
+			rollLogPartitions(r.engine, schema, now)
+		}
+		if schema.logRetention > 0 {
+			enforceLogRetention(r.engine, schema, now)
+		}
+	}
+}
+
+// logRetentionLoop runs retention also when consumer is blocked waiting for events,
+// panic stops consumer and is repeated in Digest
+func (r *BackgroundConsumer) logRetentionLoop(ctx context.Context, cancel context.CancelFunc, panicked chan<- interface{}) {
+	defer func() {
+		if rec := recover(); rec != nil {
+			panicked <- rec
+			cancel()
+		}
+	}()
+	ticker := time.NewTicker(logRetentionTick)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			r.handleLogRetention()
+		}
+	}
+}
+
+func (r *BackgroundConsumer) handleLogRetention() {
+	now := time.Now().Unix()
+	if now-r.logRetentionLastTick < logRetentionInterval {
+		return
+	}
+	r.logRetentionLastTick = now
+	hasRetention := false
+	for _, schema := range r.engine.registry.tableSchemas {
+		if schema.logRetention > 0 || schema.logPartitioned {
+			hasRetention = true
+			break
+		}
+	}
+	if !hasRetention {
+		return
+	}
+	if !getRedisForStream(r.engine, logChannelName).SetNX(logRetentionLockKey, "1", logRetentionInterval) {
+		return
+	}
+	r.EnforceLogRetention()
+}
+
+func enforceLogRetention(engine *Engine, schema *tableSchema, now time.Time) {
+	db := engine.GetMysql(schema.logPoolName)
+	cutoff := now.Add(-schema.logRetention)
+	if schema.logPartitioned && !schema.logArchive {
+		for _, partition := range getLogPartitions(db, schema.logTableName) {
+			month, err := time.ParseInLocation(logPartitionFormat, partition[1:], time.Local)
+			if err != nil {
+				continue
+			}
+			if !month.AddDate(0, 1, 0).After(cutoff) {
+				db.Exec(fmt.Sprintf("ALTER TABLE `%s` DROP PARTITION `%s`", schema.logTableName, partition))
+			}
+		}
+	}
+	/* #nosec */
+	query := "SELECT `id` FROM `" + schema.logTableName + "` WHERE `added_at` < ? ORDER BY `id` LIMIT " + strconv.Itoa(logRetentionBatchSize)
+	for {
+		results, def := db.Query(query, cutoff.Format(timeFormat))
+		ids := make([]string, 0)
+		for results.Next() {
+			var id uint64
+			results.Scan(&id)
+			ids = append(ids, strconv.FormatUint(id, 10))
+		}
+		def()
+		if len(ids) == 0 {
+			return
+		}
+		in := strings.Join(ids, ",")
+		if schema.logArchive {
+			/* #nosec */
+			db.Exec("INSERT IGNORE INTO `" + schema.logArchiveTableName + "` SELECT * FROM `" + schema.logTableName + "` WHERE `id` IN (" + in + ")")
+		}
+		/* #nosec */
+		db.Exec("DELETE FROM `" + schema.logTableName + "` WHERE `id` IN (" + in + ")")
+		if len(ids) < logRetentionBatchSize {
+			return
+		}
+	}
+}
+
+func rollLogPartitions(engine *Engine, schema *tableSchema, now time.Time) {
+	db := engine.GetMysql(schema.logPoolName)
+	if len(getLogPartitions(db, schema.logTableName)) == 0 {
+		return
+	}
+	rollSQL := buildLogPartitionsRollSQL(db, schema.logTableName, now)
+	if rollSQL != "" {
+		db.Exec(rollSQL)
+	}
+}
+
+func getLogPartitions(db *DB, tableName string) []string {
+	query := "SELECT `PARTITION_NAME` FROM `information_schema`.`PARTITIONS` WHERE `TABLE_SCHEMA` = ? AND `TABLE_NAME` = ? " +
+		"AND `PARTITION_NAME` IS NOT NULL ORDER BY `PARTITION_ORDINAL_POSITION`"
+	results, def := db.Query(query, db.GetPoolConfig().GetDatabase(), tableName)
+	defer def()
+	partitions := make([]string, 0)
+	for results.Next() {
+		var name string
+		results.Scan(&name)
+		partitions = append(partitions, name)
+	}
+	return partitions
+}
+
+func logMonthStart(now time.Time) time.Time {
+	return time.Date(now.Year(), now.Month(), 1, 0, 0, 0, 0, now.Location())
+}
+
+func buildLogPartitionSQL(month time.Time) string {
+	return fmt.Sprintf("PARTITION p%s VALUES LESS THAN (TO_DAYS('%s'))", month.Format(logPartitionFormat), month.AddDate(0, 1, 0).Format(dateformat))
+}
+
+func buildLogPartitionsSQL(now time.Time) string {
+	sql := "PARTITION BY RANGE (TO_DAYS(`added_at`))\n("
+	month := logMonthStart(now)
+	for i := 0; i <= logPartitionsAhead; i++ {
+		sql += buildLogPartitionSQL(month) + ",\n"
+		month = month.AddDate(0, 1, 0)
+	}
+	return sql + "PARTITION pmax VALUES LESS THAN MAXVALUE)"
+}
+
+func buildLogPartitionsRollSQL(db *DB, tableName string, now time.Time) string {
+	last := ""
+	for _, partition := range getLogPartitions(db, tableName) {
+		if partition != "pmax" && partition > last {
+			last = partition
+		}
+	}
+	missing := make([]string, 0)
+	month := logMonthStart(now)
+	for i := 0; i <= logPartitionsAhead; i++ {
+		if "p"+month.Format(logPartitionFormat) > last {
+			missing = append(missing, buildLogPartitionSQL(month))
+		}
+		month = month.AddDate(0, 1, 0)
+	}
+	if len(missing) == 0 {
+		return ""
+	}
+	return fmt.Sprintf("ALTER TABLE `%s`.`%s` REORGANIZE PARTITION pmax INTO (\n%s,\nPARTITION pmax VALUES LESS THAN MAXVALUE);",
+		db.GetPoolConfig().GetDatabase(), tableName, strings.Join(missing, ",\n"))
+}
@@ -0,0 +1,140 @@
+package beeorm
+
+import (
+	"fmt"
+	"os"
+	"strconv"
+	"sync"
+
+	jsoniter "github.com/json-iterator/go"
+)
+
+type LogSink interface {
+	GetCode() string
+	Write(engine *Engine, value *LogQueueValue)
+}
+
+type mySQLLogSink struct {
+	code string
+	pool string
+}
+
+func NewMySQLLogSink(code, mysqlPool string) LogSink {
+	return &mySQLLogSink{code: code, pool: mysqlPool}
+}
+
+func (s *mySQLLogSink) GetCode() string {
+	return s.code
+}
+
+func (s *mySQLLogSink) Write(engine *Engine, value *LogQueueValue) {
+	/* #nosec */
+	query := "INSERT INTO `" + value.TableName + "`(`entity_id`, `added_at`, `meta`, `before`, `changes`) VALUES(?, ?, ?, ?, ?)"
+	var meta, before, changes interface{}
+	if value.Meta != nil {
+		meta, _ = jsoniter.ConfigFastest.Marshal(value.Meta)
+	}
+	if value.Before != nil {
+		before, _ = jsoniter.ConfigFastest.Marshal(value.Before)
+	}
+	if value.Changes != nil {
+		changes, _ = jsoniter.ConfigFastest.Marshal(value.Changes)
+	}
+	engine.GetMysql(s.pool).Exec(query, value.ID, value.Updated.Format(timeFormat), meta, before, changes)
+}
+
+type fileLogSink struct {
+	code     string
+	path     string
+	maxSize  int64
+	maxFiles int
+	mutex    sync.Mutex
+	file     *os.File
+	size     int64
+}
+
+type fileLogRow struct {
+	Pool     string                 `json:"pool"`
+	Table    string                 `json:"table"`
+	EntityID uint64                 `json:"entity_id"`
+	AddedAt  string                 `json:"added_at"`
+	Meta     map[string]interface{} `json:"meta,omitempty"`
+	Before   map[string]interface{} `json:"before,omitempty"`
+	Changes  map[string]interface{} `json:"changes,omitempty"`
+}
+
+func NewFileLogSink(code, path string, maxSize int64, maxFiles int) LogSink {
+	return &fileLogSink{code: code, path: path, maxSize: maxSize, maxFiles: maxFiles}
+}
+
+func (s *fileLogSink) GetCode() string {
+	return s.code
+}
+
+func (s *fileLogSink) Write(_ *Engine, value *LogQueueValue) {
+	row := &fileLogRow{Pool: value.PoolName, Table: value.TableName, EntityID: value.ID, AddedAt: value.Updated.Format(timeFormat),
+		Meta: value.Meta, Before: value.Before, Changes: value.Changes}
+	line, err := jsoniter.ConfigFastest.Marshal(row)
+	checkError(err)
+	line = append(line, '\n')
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	if s.file != nil && s.maxSize > 0 && s.size+int64(len(line)) > s.maxSize {
+		s.rotate()
+	}
+	if s.file == nil {
+		s.open()
+	}
+	n, err := s.file.Write(line)
+	checkError(err)
+	s.size += int64(n)
+}
+
+func (s *fileLogSink) open() {
+	/* #nosec */
+	file, err := os.OpenFile(s.path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0640)
+	checkError(err)
+	info, err := file.Stat()
+	checkError(err)
+	s.file = file
+	s.size = info.Size()
+}
+
+func (s *fileLogSink) rotate() {
+	checkError(s.file.Close())
+	s.file = nil
+	if s.maxFiles <= 0 {
+		checkError(os.Remove(s.path))
+		return
+	}
+	_ = os.Remove(s.path + "." + strconv.Itoa(s.maxFiles))
+	for i := s.maxFiles - 1; i > 0; i-- {
+		_ = os.Rename(s.path+"."+strconv.Itoa(i), s.path+"."+strconv.Itoa(i+1))
+	}
+	checkError(os.Rename(s.path, s.path+".1"))
+}
+
+type redisStreamLogSink struct {
+	code   string
+	stream string
+}
+
+func NewRedisStreamLogSink(code, stream string) LogSink {
+	return &redisStreamLogSink{code: code, stream: stream}
+}
+
+func (s *redisStreamLogSink) GetCode() string {
+	return s.code
+}
+
+func (s *redisStreamLogSink) Write(engine *Engine, value *LogQueueValue) {
+	engine.GetEventBroker().Publish(s.stream, value)
+}
+
+func getLogSink(engine *Engine, code string) LogSink {
+	sink, has := engine.registry.logSinks[code]
+	if !has {
+		panic(fmt.Errorf("log sink '%s' not found", code))
+	}
+	return sink
+}
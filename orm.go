@@ -41,6 +41,7 @@ type ORM struct {
 	inDB                 bool
 	delete               bool
 	fakeDelete           bool
+	restore              bool
 	value                reflect.Value
 	elem                 reflect.Value
 	idElem               reflect.Value
@@ -106,7 +107,13 @@ func (orm *ORM) GetDirtyBind() (bind Bind, has bool) {
 
 func (orm *ORM) buildDirtyBind(serializer *serializer) (bindBuilder *bindBuilder, has bool) {
 	if orm.fakeDelete {
-		if orm.tableSchema.hasFakeDelete {
+		if orm.tableSchema.hasDeletedAt {
+			deletedAt := orm.elem.FieldByName("DeletedAt")
+			if deletedAt.IsNil() {
+				now := time.Now()
+				deletedAt.Set(reflect.ValueOf(&now))
+			}
+		} else if orm.tableSchema.hasFakeDelete {
 			orm.elem.FieldByName("FakeDelete").SetBool(true)
 		} else {
 			orm.delete = true
@@ -13,39 +13,79 @@ import (
 	"github.com/go-redis/redis_rate/v9"
 )
 
+type redisClient interface {
+	redis.Cmdable
+	Context() context.Context
+	Process(ctx context.Context, cmd redis.Cmder) error
+}
+
 type RedisCache struct {
 	engine  *Engine
-	client  *redis.Client
+	client  redisClient
 	limiter *redis_rate.Limiter
 	locker  *Locker
 	config  RedisPoolConfig
 }
 
-func (r *RedisCache) RateLimit(key string, period time.Duration, limit int) bool {
-	if r.limiter == nil {
-		r.limiter = redis_rate.NewLimiter(r.client)
-	}
-	key = r.addNamespacePrefix(key)
-	start := getNow(r.engine.hasRedisLogger)
-	res, err := r.limiter.Allow(r.client.Context(), key, redis_rate.Limit{
-		Rate:   limit,
-		Period: period,
-		Burst:  limit,
-	})
-	if r.engine.hasRedisLogger {
-		r.fillLogFields("RATE", "RATE "+key+" "+period.String(), start, false, err)
-	}
-	checkError(err)
-	return res.Allowed > 0
+type redisGetSetValue struct {
+	V interface{}
+	E int64
+	C int64
 }
 
 func (r *RedisCache) GetSet(key string, ttlSeconds int, provider func() interface{}) interface{} {
+	early := r.engine.registry.stampede != nil && r.engine.registry.stampede.Beta > 0
 	val, has := r.Get(key)
-	if !has {
+	if has {
+		if !early {
+			return r.decodeGetSetValue(val, false)
+		}
+		value := &redisGetSetValue{}
+		if msgpack.Unmarshal([]byte(val), value) == nil && !r.engine.shouldRecomputeEarly(time.Duration(value.C), value.E) {
+			return value.V
+		}
+	}
+	flightKey := "redis:" + r.config.GetCode() + ":" + key
+	if has && r.engine.registry.singleFlight.running(flightKey) {
+		return r.decodeGetSetValue(val, early)
+	}
+	userVal, _ := r.engine.registry.singleFlight.do(flightKey, func() interface{} {
+		lock, enabled := r.engine.obtainStampedeLock(r.config.GetCode()+":"+key, !has)
+		if lock != nil {
+			defer lock.Release()
+		} else if enabled && has {
+			return r.decodeGetSetValue(val, early)
+		}
+		if enabled && !has {
+			val, has = r.Get(key)
+			if has {
+				return r.decodeGetSetValue(val, early)
+			}
+		}
+		start := time.Now()
 		userVal := provider()
-		encoded, _ := msgpack.Marshal(userVal)
+		var encoded []byte
+		if early {
+			value := &redisGetSetValue{V: userVal, C: int64(time.Since(start))}
+			if ttlSeconds > 0 {
+				value.E = time.Now().Add(time.Duration(ttlSeconds) * time.Second).UnixNano()
+			}
+			encoded, _ = msgpack.Marshal(value)
+		} else {
+			encoded, _ = msgpack.Marshal(userVal)
+		}
 		r.Set(key, string(encoded), ttlSeconds)
 		return userVal
+	})
+	return userVal
+}
+
+func (r *RedisCache) decodeGetSetValue(val string, early bool) interface{} {
+	if early {
+		value := &redisGetSetValue{}
+		if msgpack.Unmarshal([]byte(val), value) == nil {
+			return value.V
+		}
 	}
 	var data interface{}
 	_ = msgpack.Unmarshal([]byte(val), &data)
@@ -56,6 +96,10 @@ func (r *RedisCache) PipeLine() *RedisPipeLine {
 	return &RedisPipeLine{ctx: r.client.Context(), pool: r.config.GetCode(), r: r, pipeLine: r.client.Pipeline()}
 }
 
+func (r *RedisCache) TxPipeline() *RedisPipeLine {
+	return &RedisPipeLine{ctx: r.client.Context(), pool: r.config.GetCode(), r: r, pipeLine: r.client.TxPipeline(), tx: true}
+}
+
 func (r *RedisCache) Info(section ...string) string {
 	start := getNow(r.engine.hasRedisLogger)
 	val, err := r.client.Info(context.Background(), section...).Result()
@@ -94,6 +138,31 @@ func (r *RedisCache) Get(key string) (value string, has bool) {
 	return val, true
 }
 
+func (r *RedisCache) GetDel(key string) (value string, has bool) {
+	start := getNow(r.engine.hasRedisLogger)
+	key = r.addNamespacePrefix(key)
+	// MULTI instead of GETDEL which requires redis 6.2
+	pipeline := r.client.TxPipeline()
+	get := pipeline.Get(context.Background(), key)
+	pipeline.Del(context.Background(), key)
+	_, err := pipeline.Exec(context.Background())
+	val := get.Val()
+	if err != nil {
+		if err == redis.Nil {
+			err = nil
+		}
+		if r.engine.hasRedisLogger {
+			r.fillLogFields("GETDEL", "GETDEL "+key, start, true, err)
+		}
+		checkError(err)
+		return "", false
+	}
+	if r.engine.hasRedisLogger {
+		r.fillLogFields("GETDEL", "GETDEL "+key, start, false, err)
+	}
+	return val, true
+}
+
 func (r *RedisCache) Eval(script string, keys []string, args ...interface{}) interface{} {
 	start := getNow(r.engine.hasRedisLogger)
 	res, err := r.client.Eval(context.Background(), script, keys, args...).Result()
@@ -197,7 +266,15 @@ func (r *RedisCache) Exists(keys ...string) int64 {
 		}
 	}
 	start := getNow(r.engine.hasRedisLogger)
-	val, err := r.client.Exists(context.Background(), keys...).Result()
+	var val int64
+	var err error
+	if r.config.IsCluster() {
+		val, err = r.clusterKeysCommand(context.Background(), keys, func(client redis.Cmdable, keys ...string) *redis.IntCmd {
+			return client.Exists(context.Background(), keys...)
+		})
+	} else {
+		val, err = r.client.Exists(context.Background(), keys...).Result()
+	}
 	if r.engine.hasRedisLogger {
 		r.fillLogFields("EXISTS", "EXISTS "+strings.Join(keys, " "), start, false, err)
 	}
@@ -439,6 +516,28 @@ func (r *RedisCache) Expire(key string, expiration time.Duration) bool {
 	return val
 }
 
+func (r *RedisCache) TTL(key string) time.Duration {
+	key = r.addNamespacePrefix(key)
+	start := getNow(r.engine.hasRedisLogger)
+	val, err := r.client.TTL(context.Background(), key).Result()
+	if r.engine.hasRedisLogger {
+		r.fillLogFields("TTL", "TTL "+key, start, false, err)
+	}
+	checkError(err)
+	return val
+}
+
+func (r *RedisCache) Persist(key string) bool {
+	key = r.addNamespacePrefix(key)
+	start := getNow(r.engine.hasRedisLogger)
+	val, err := r.client.Persist(context.Background(), key).Result()
+	if r.engine.hasRedisLogger {
+		r.fillLogFields("PERSIST", "PERSIST "+key, start, false, err)
+	}
+	checkError(err)
+	return val
+}
+
 func (r *RedisCache) ZAdd(key string, members ...*redis.Z) int64 {
 	key = r.addNamespacePrefix(key)
 	start := getNow(r.engine.hasRedisLogger)
@@ -525,6 +624,69 @@ func (r *RedisCache) ZScore(key, member string) float64 {
 	return val
 }
 
+func (r *RedisCache) ZRem(key string, members ...interface{}) int64 {
+	key = r.addNamespacePrefix(key)
+	start := getNow(r.engine.hasRedisLogger)
+	val, err := r.client.ZRem(context.Background(), key, members...).Result()
+	if r.engine.hasRedisLogger {
+		message := "ZREM " + key
+		for _, v := range members {
+			message += fmt.Sprintf(" %v", v)
+		}
+		r.fillLogFields("ZREM", message, start, false, err)
+	}
+	checkError(err)
+	return val
+}
+
+func (r *RedisCache) ZRangeByScore(key string, opt *redis.ZRangeBy) []string {
+	key = r.addNamespacePrefix(key)
+	start := getNow(r.engine.hasRedisLogger)
+	val, err := r.client.ZRangeByScore(context.Background(), key, opt).Result()
+	if r.engine.hasRedisLogger {
+		message := fmt.Sprintf("ZRANGEBYSCORE %s %s %s LIMIT %d %d", key, opt.Min, opt.Max, opt.Offset, opt.Count)
+		r.fillLogFields("ZRANGEBYSCORE", message, start, false, err)
+	}
+	checkError(err)
+	return val
+}
+
+func (r *RedisCache) ZRangeByScoreWithScores(key string, opt *redis.ZRangeBy) []redis.Z {
+	key = r.addNamespacePrefix(key)
+	start := getNow(r.engine.hasRedisLogger)
+	val, err := r.client.ZRangeByScoreWithScores(context.Background(), key, opt).Result()
+	if r.engine.hasRedisLogger {
+		message := fmt.Sprintf("ZRANGEBYSCORE %s %s %s WITHSCORES LIMIT %d %d", key, opt.Min, opt.Max, opt.Offset, opt.Count)
+		r.fillLogFields("ZRANGEBYSCORE", message, start, false, err)
+	}
+	checkError(err)
+	return val
+}
+
+func (r *RedisCache) ZIncrBy(key string, increment float64, member string) float64 {
+	key = r.addNamespacePrefix(key)
+	start := getNow(r.engine.hasRedisLogger)
+	val, err := r.client.ZIncrBy(context.Background(), key, increment, member).Result()
+	if r.engine.hasRedisLogger {
+		message := fmt.Sprintf("ZINCRBY %s %f %s", key, increment, member)
+		r.fillLogFields("ZINCRBY", message, start, false, err)
+	}
+	checkError(err)
+	return val
+}
+
+func (r *RedisCache) ZPopMin(key string, count int64) []redis.Z {
+	key = r.addNamespacePrefix(key)
+	start := getNow(r.engine.hasRedisLogger)
+	val, err := r.client.ZPopMin(context.Background(), key, count).Result()
+	if r.engine.hasRedisLogger {
+		message := fmt.Sprintf("ZPOPMIN %s %d", key, count)
+		r.fillLogFields("ZPOPMIN", message, start, false, err)
+	}
+	checkError(err)
+	return val
+}
+
 func (r *RedisCache) MSet(pairs ...interface{}) {
 	if r.config.HasNamespace() {
 		for i := 0; i < len(pairs); i = i + 2 {
@@ -532,7 +694,12 @@ func (r *RedisCache) MSet(pairs ...interface{}) {
 		}
 	}
 	start := getNow(r.engine.hasRedisLogger)
-	_, err := r.client.MSet(context.Background(), pairs...).Result()
+	var err error
+	if r.config.IsCluster() {
+		err = r.clusterMSet(context.Background(), pairs)
+	} else {
+		_, err = r.client.MSet(context.Background(), pairs...).Result()
+	}
 	if r.engine.hasRedisLogger {
 		message := "MSET"
 		for _, v := range pairs {
@@ -543,6 +710,18 @@ func (r *RedisCache) MSet(pairs ...interface{}) {
 	checkError(err)
 }
 
+func (r *RedisCache) mSetWithTTL(ttlSeconds int, pairs ...interface{}) {
+	if ttlSeconds <= 0 {
+		r.MSet(pairs...)
+		return
+	}
+	pipeLine := r.PipeLine()
+	for i := 0; i < len(pairs); i = i + 2 {
+		pipeLine.Set(pairs[i].(string), pairs[i+1], time.Duration(ttlSeconds)*time.Second)
+	}
+	pipeLine.Exec()
+}
+
 func (r *RedisCache) MGet(keys ...string) []interface{} {
 	if r.config.HasNamespace() {
 		for i, key := range keys {
@@ -550,7 +729,13 @@ func (r *RedisCache) MGet(keys ...string) []interface{} {
 		}
 	}
 	start := getNow(r.engine.hasRedisLogger)
-	val, err := r.client.MGet(context.Background(), keys...).Result()
+	var val []interface{}
+	var err error
+	if r.config.IsCluster() {
+		val, err = r.clusterMGet(context.Background(), keys)
+	} else {
+		val, err = r.client.MGet(context.Background(), keys...).Result()
+	}
 	results := make([]interface{}, len(keys))
 	misses := 0
 	for i, v := range val {
@@ -620,6 +805,44 @@ func (r *RedisCache) SPopN(key string, max int64) []string {
 	return val
 }
 
+func (r *RedisCache) SMembers(key string) []string {
+	key = r.addNamespacePrefix(key)
+	start := getNow(r.engine.hasRedisLogger)
+	val, err := r.client.SMembers(context.Background(), key).Result()
+	if r.engine.hasRedisLogger {
+		r.fillLogFields("SMEMBERS", "SMEMBERS "+key, start, false, err)
+	}
+	checkError(err)
+	return val
+}
+
+func (r *RedisCache) SIsMember(key string, member interface{}) bool {
+	key = r.addNamespacePrefix(key)
+	start := getNow(r.engine.hasRedisLogger)
+	val, err := r.client.SIsMember(context.Background(), key, member).Result()
+	if r.engine.hasRedisLogger {
+		message := fmt.Sprintf("SISMEMBER %s %v", key, member)
+		r.fillLogFields("SISMEMBER", message, start, false, err)
+	}
+	checkError(err)
+	return val
+}
+
+func (r *RedisCache) SRem(key string, members ...interface{}) int64 {
+	key = r.addNamespacePrefix(key)
+	start := getNow(r.engine.hasRedisLogger)
+	val, err := r.client.SRem(context.Background(), key, members...).Result()
+	if r.engine.hasRedisLogger {
+		message := "SREM " + key
+		for _, v := range members {
+			message += fmt.Sprintf(" %v", v)
+		}
+		r.fillLogFields("SREM", message, start, false, err)
+	}
+	checkError(err)
+	return val
+}
+
 func (r *RedisCache) Del(keys ...string) {
 	if r.config.HasNamespace() {
 		for i, key := range keys {
@@ -627,7 +850,14 @@ func (r *RedisCache) Del(keys ...string) {
 		}
 	}
 	start := getNow(r.engine.hasRedisLogger)
-	_, err := r.client.Del(context.Background(), keys...).Result()
+	var err error
+	if r.config.IsCluster() {
+		_, err = r.clusterKeysCommand(context.Background(), keys, func(client redis.Cmdable, keys ...string) *redis.IntCmd {
+			return client.Del(context.Background(), keys...)
+		})
+	} else {
+		_, err = r.client.Del(context.Background(), keys...).Result()
+	}
 	if r.engine.hasRedisLogger {
 		r.fillLogFields("DEL", "DEL "+strings.Join(keys, " "), start, false, err)
 	}
@@ -635,7 +865,7 @@ func (r *RedisCache) Del(keys ...string) {
 }
 
 func (r *RedisCache) XTrim(stream string, maxLen int64) (deleted int64) {
-	stream = r.addNamespacePrefix(stream)
+	stream = r.addStreamPrefix(stream)
 	start := getNow(r.engine.hasRedisLogger)
 	var err error
 	deleted, err = r.client.XTrimMaxLen(context.Background(), stream, maxLen).Result()
@@ -648,7 +878,7 @@ func (r *RedisCache) XTrim(stream string, maxLen int64) (deleted int64) {
 }
 
 func (r *RedisCache) XRange(stream, start, stop string, count int64) []redis.XMessage {
-	stream = r.addNamespacePrefix(stream)
+	stream = r.addStreamPrefix(stream)
 	s := getNow(r.engine.hasRedisLogger)
 	deleted, err := r.client.XRangeN(context.Background(), stream, start, stop, count).Result()
 	if r.engine.hasRedisLogger {
@@ -660,7 +890,7 @@ func (r *RedisCache) XRange(stream, start, stop string, count int64) []redis.XMe
 }
 
 func (r *RedisCache) XRevRange(stream, start, stop string, count int64) []redis.XMessage {
-	stream = r.addNamespacePrefix(stream)
+	stream = r.addStreamPrefix(stream)
 	s := getNow(r.engine.hasRedisLogger)
 	deleted, err := r.client.XRevRangeN(context.Background(), stream, start, stop, count).Result()
 	if r.engine.hasRedisLogger {
@@ -672,7 +902,7 @@ func (r *RedisCache) XRevRange(stream, start, stop string, count int64) []redis.
 }
 
 func (r *RedisCache) XInfoStream(stream string) *redis.XInfoStream {
-	stream = r.addNamespacePrefix(stream)
+	stream = r.addStreamPrefix(stream)
 	start := getNow(r.engine.hasRedisLogger)
 	info, err := r.client.XInfoStream(context.Background(), stream).Result()
 	if r.engine.hasRedisLogger {
@@ -683,7 +913,7 @@ func (r *RedisCache) XInfoStream(stream string) *redis.XInfoStream {
 }
 
 func (r *RedisCache) XInfoGroups(stream string) []redis.XInfoGroup {
-	stream = r.addNamespacePrefix(stream)
+	stream = r.addStreamPrefix(stream)
 	start := getNow(r.engine.hasRedisLogger)
 	info, err := r.client.XInfoGroups(context.Background(), stream).Result()
 	if err != nil && err.Error() == "ERR no such key" {
@@ -705,7 +935,7 @@ func (r *RedisCache) XInfoGroups(stream string) []redis.XInfoGroup {
 }
 
 func (r *RedisCache) XGroupCreate(stream, group, start string) (key string, exists bool) {
-	stream = r.addNamespacePrefix(stream)
+	stream = r.addStreamPrefix(stream)
 	group = r.addNamespacePrefix(group)
 	s := getNow(r.engine.hasRedisLogger)
 	res, err := r.client.XGroupCreate(context.Background(), stream, group, start).Result()
@@ -725,7 +955,7 @@ func (r *RedisCache) XGroupCreate(stream, group, start string) (key string, exis
 }
 
 func (r *RedisCache) XGroupCreateMkStream(stream, group, start string) (key string, exists bool) {
-	stream = r.addNamespacePrefix(stream)
+	stream = r.addStreamPrefix(stream)
 	group = r.addNamespacePrefix(group)
 	s := getNow(r.engine.hasRedisLogger)
 	res, err := r.client.XGroupCreateMkStream(context.Background(), stream, group, start).Result()
@@ -744,7 +974,7 @@ func (r *RedisCache) XGroupCreateMkStream(stream, group, start string) (key stri
 }
 
 func (r *RedisCache) XGroupDestroy(stream, group string) int64 {
-	stream = r.addNamespacePrefix(stream)
+	stream = r.addStreamPrefix(stream)
 	group = r.addNamespacePrefix(group)
 	start := getNow(r.engine.hasRedisLogger)
 	res, err := r.client.XGroupDestroy(context.Background(), stream, group).Result()
@@ -757,10 +987,8 @@ func (r *RedisCache) XGroupDestroy(stream, group string) int64 {
 }
 
 func (r *RedisCache) XRead(a *redis.XReadArgs) []redis.XStream {
-	if r.config.HasNamespace() {
-		for i, stream := range a.Streams {
-			a.Streams[i] = r.addNamespacePrefix(stream)
-		}
+	for i := 0; i < len(a.Streams)/2; i++ {
+		a.Streams[i] = r.addStreamPrefix(a.Streams[i])
 	}
 	start := getNow(r.engine.hasRedisLogger)
 	info, err := r.client.XRead(context.Background(), a).Result()
@@ -773,7 +1001,7 @@ func (r *RedisCache) XRead(a *redis.XReadArgs) []redis.XStream {
 }
 
 func (r *RedisCache) XDel(stream string, ids ...string) int64 {
-	stream = r.addNamespacePrefix(stream)
+	stream = r.addStreamPrefix(stream)
 	start := getNow(r.engine.hasRedisLogger)
 	deleted, err := r.client.XDel(context.Background(), stream, ids...).Result()
 	if r.engine.hasRedisLogger {
@@ -784,7 +1012,7 @@ func (r *RedisCache) XDel(stream string, ids ...string) int64 {
 }
 
 func (r *RedisCache) XGroupDelConsumer(stream, group, consumer string) int64 {
-	stream = r.addNamespacePrefix(stream)
+	stream = r.addStreamPrefix(stream)
 	group = r.addNamespacePrefix(group)
 	start := getNow(r.engine.hasRedisLogger)
 	deleted, err := r.client.XGroupDelConsumer(context.Background(), stream, group, consumer).Result()
@@ -797,13 +1025,11 @@ func (r *RedisCache) XGroupDelConsumer(stream, group, consumer string) int64 {
 }
 
 func (r *RedisCache) XReadGroup(ctx context.Context, a *redis.XReadGroupArgs) (streams []redis.XStream) {
-	if r.config.HasNamespace() {
-		if a.Group != "" {
-			a.Group = r.addNamespacePrefix(a.Group)
-		}
-		for i := 0; i < len(a.Streams)/2; i++ {
-			a.Streams[i] = r.addNamespacePrefix(a.Streams[i])
-		}
+	if a.Group != "" {
+		a.Group = r.addNamespacePrefix(a.Group)
+	}
+	for i := 0; i < len(a.Streams)/2; i++ {
+		a.Streams[i] = r.addStreamPrefix(a.Streams[i])
 	}
 	start := getNow(r.engine.hasRedisLogger)
 	if r.engine.hasRedisLogger && a.Block >= 0 {
@@ -824,16 +1050,16 @@ func (r *RedisCache) XReadGroup(ctx context.Context, a *redis.XReadGroupArgs) (s
 		err = nil
 	}
 	checkError(err)
-	if r.config.HasNamespace() {
+	if r.config.HasNamespace() || r.config.IsCluster() {
 		for i := range streams {
-			streams[i].Stream = r.removeNamespacePrefix(streams[i].Stream)
+			streams[i].Stream = r.removeStreamPrefix(streams[i].Stream)
 		}
 	}
 	return streams
 }
 
 func (r *RedisCache) XPending(stream, group string) *redis.XPending {
-	stream = r.addNamespacePrefix(stream)
+	stream = r.addStreamPrefix(stream)
 	group = r.addNamespacePrefix(group)
 	start := getNow(r.engine.hasRedisLogger)
 	res, err := r.client.XPending(context.Background(), stream, group).Result()
@@ -846,13 +1072,11 @@ func (r *RedisCache) XPending(stream, group string) *redis.XPending {
 }
 
 func (r *RedisCache) XPendingExt(a *redis.XPendingExtArgs) []redis.XPendingExt {
-	if r.config.HasNamespace() {
-		if a.Group != "" {
-			a.Group = r.addNamespacePrefix(a.Group)
-		}
-		if a.Stream != "" {
-			a.Stream = r.addNamespacePrefix(a.Stream)
-		}
+	if a.Group != "" {
+		a.Group = r.addNamespacePrefix(a.Group)
+	}
+	if a.Stream != "" {
+		a.Stream = r.addStreamPrefix(a.Stream)
 	}
 
 	start := getNow(r.engine.hasRedisLogger)
@@ -867,7 +1091,7 @@ func (r *RedisCache) XPendingExt(a *redis.XPendingExtArgs) []redis.XPendingExt {
 }
 
 func (r *RedisCache) xAdd(stream string, values interface{}) (id string) {
-	stream = r.addNamespacePrefix(stream)
+	stream = r.addStreamPrefix(stream)
 	a := &redis.XAddArgs{Stream: stream, ID: "*", Values: values}
 	start := getNow(r.engine.hasRedisLogger)
 	id, err := r.client.XAdd(context.Background(), a).Result()
@@ -880,7 +1104,7 @@ func (r *RedisCache) xAdd(stream string, values interface{}) (id string) {
 }
 
 func (r *RedisCache) XLen(stream string) int64 {
-	stream = r.addNamespacePrefix(stream)
+	stream = r.addStreamPrefix(stream)
 	start := getNow(r.engine.hasRedisLogger)
 	l, err := r.client.XLen(context.Background(), stream).Result()
 	if r.engine.hasRedisLogger {
@@ -891,10 +1115,8 @@ func (r *RedisCache) XLen(stream string) int64 {
 }
 
 func (r *RedisCache) XClaim(a *redis.XClaimArgs) []redis.XMessage {
-	if r.config.HasNamespace() {
-		a.Stream = r.addNamespacePrefix(a.Stream)
-		a.Group = r.addNamespacePrefix(a.Group)
-	}
+	a.Stream = r.addStreamPrefix(a.Stream)
+	a.Group = r.addNamespacePrefix(a.Group)
 	start := getNow(r.engine.hasRedisLogger)
 	res, err := r.client.XClaim(context.Background(), a).Result()
 	if r.engine.hasRedisLogger {
@@ -907,10 +1129,8 @@ func (r *RedisCache) XClaim(a *redis.XClaimArgs) []redis.XMessage {
 }
 
 func (r *RedisCache) XClaimJustID(a *redis.XClaimArgs) []string {
-	if r.config.HasNamespace() {
-		a.Stream = r.addNamespacePrefix(a.Stream)
-		a.Group = r.addNamespacePrefix(a.Group)
-	}
+	a.Stream = r.addStreamPrefix(a.Stream)
+	a.Group = r.addNamespacePrefix(a.Group)
 	start := getNow(r.engine.hasRedisLogger)
 	res, err := r.client.XClaimJustID(context.Background(), a).Result()
 	if r.engine.hasRedisLogger {
@@ -924,7 +1144,7 @@ func (r *RedisCache) XClaimJustID(a *redis.XClaimArgs) []string {
 }
 
 func (r *RedisCache) XAck(stream, group string, ids ...string) int64 {
-	stream = r.addNamespacePrefix(stream)
+	stream = r.addStreamPrefix(stream)
 	group = r.addNamespacePrefix(group)
 	start := getNow(r.engine.hasRedisLogger)
 	res, err := r.client.XAck(context.Background(), stream, group, ids...).Result()
@@ -938,7 +1158,9 @@ func (r *RedisCache) XAck(stream, group string, ids ...string) int64 {
 
 func (r *RedisCache) FlushAll() {
 	start := getNow(r.engine.hasRedisLogger)
-	_, err := r.client.FlushAll(context.Background()).Result()
+	err := r.forEachMaster(context.Background(), func(client redis.Cmdable) error {
+		return client.FlushAll(context.Background()).Err()
+	})
 	if r.engine.hasRedisLogger {
 		r.fillLogFields("FLUSHALL", "FLUSHALL", start, false, err)
 	}
@@ -949,24 +1171,57 @@ func (r *RedisCache) FlushDB() {
 	start := getNow(r.engine.hasRedisLogger)
 	if r.config.HasNamespace() {
 		script := "for _,k in ipairs(redis.call('keys','" + r.config.GetNamespace() + ":*')) do redis.call('del',k) end return 1"
-		_, err := r.client.Eval(context.Background(), script, nil).Result()
+		err := r.forEachMaster(context.Background(), func(client redis.Cmdable) error {
+			return client.Eval(context.Background(), script, nil).Err()
+		})
 		if r.engine.hasRedisLogger {
 			r.fillLogFields("FLUSHDB EVAL", "EVAL REMOVE KEYS WITH PREFIX "+r.config.GetNamespace(), start, false, err)
 		}
 		checkError(err)
+		if r.config.IsCluster() {
+			return
+		}
 		s := r.engine.GetRedisSearch(r.config.GetCode())
 		for _, indexName := range s.ListIndices() {
 			s.dropIndex(indexName, false)
 		}
 		return
 	}
-	_, err := r.client.FlushDB(context.Background()).Result()
+	err := r.forEachMaster(context.Background(), func(client redis.Cmdable) error {
+		return client.FlushDB(context.Background()).Err()
+	})
 	if r.engine.hasRedisLogger {
 		r.fillLogFields("FLUSHDB", "FLUSHDB", start, false, err)
 	}
 	checkError(err)
 }
 
+func (r *RedisCache) scanKeys(match string, handler func(keys []string)) {
+	match = r.addNamespacePrefix(match)
+	start := getNow(r.engine.hasRedisLogger)
+	err := r.forEachMaster(context.Background(), func(client redis.Cmdable) error {
+		cursor := uint64(0)
+		for {
+			keys, next, err := client.Scan(context.Background(), cursor, match, 1000).Result()
+			if err != nil {
+				return err
+			}
+			for i, key := range keys {
+				keys[i] = r.removeNamespacePrefix(key)
+			}
+			handler(keys)
+			if next == 0 {
+				return nil
+			}
+			cursor = next
+		}
+	})
+	if r.engine.hasRedisLogger {
+		r.fillLogFields("SCAN", "SCAN "+match, start, false, err)
+	}
+	checkError(err)
+}
+
 func (r *RedisCache) fillLogFields(operation, query string, start *time.Time, cacheMiss bool, err error) {
 	fillLogFields(r.engine.queryLoggersRedis, r.config.GetCode(), sourceRedis, operation, query, start, cacheMiss, err)
 }
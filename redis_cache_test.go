@@ -160,6 +160,31 @@ func testRedis(t *testing.T, namespace string) {
 	r.Del("test_z")
 	assert.Equal(t, int64(0), r.ZCount("test_z", "10", "20"))
 
+	r.ZAdd("test_z", &redis.Z{Member: "a", Score: 10}, &redis.Z{Member: "b", Score: 20}, &redis.Z{Member: "c", Score: 30})
+	assert.Equal(t, []string{"a", "b"}, r.ZRangeByScore("test_z", &redis.ZRangeBy{Min: "10", Max: "20"}))
+	assert.Equal(t, []string{"b"}, r.ZRangeByScore("test_z", &redis.ZRangeBy{Min: "(10", Max: "+inf", Count: 1}))
+	resZRange = r.ZRangeByScoreWithScores("test_z", &redis.ZRangeBy{Min: "25", Max: "+inf"})
+	assert.Len(t, resZRange, 1)
+	assert.Equal(t, "c", resZRange[0].Member)
+	assert.Equal(t, float64(15), r.ZIncrBy("test_z", 5, "a"))
+	assert.Equal(t, int64(1), r.ZRem("test_z", "c", "missing"))
+	resZRange = r.ZPopMin("test_z", 1)
+	assert.Len(t, resZRange, 1)
+	assert.Equal(t, "a", resZRange[0].Member)
+	assert.Equal(t, []string{"b"}, r.ZRevRange("test_z", 0, 3))
+	r.Del("test_z")
+
+	r.Set("test_ttl", "a", 10)
+	assert.Greater(t, r.TTL("test_ttl").Seconds(), float64(8))
+	assert.True(t, r.Persist("test_ttl"))
+	assert.Equal(t, time.Duration(-1), r.TTL("test_ttl"))
+	assert.Equal(t, time.Duration(-2), r.TTL("test_missing"))
+	val2, has := r.GetDel("test_ttl")
+	assert.True(t, has)
+	assert.Equal(t, "a", val2)
+	_, has = r.GetDel("test_ttl")
+	assert.False(t, has)
+
 	r.MSet("key_1", "a", "key_2", "b")
 	assert.Equal(t, []interface{}{"a", "b", nil}, r.MGet("key_1", "key_2", "missing"))
 
@@ -175,6 +200,36 @@ func testRedis(t *testing.T, namespace string) {
 	assert.Equal(t, "", val)
 	assert.False(t, has)
 
+	r.SAdd("test_s", "a", "b", "c")
+	assert.ElementsMatch(t, []string{"a", "b", "c"}, r.SMembers("test_s"))
+	assert.True(t, r.SIsMember("test_s", "a"))
+	assert.False(t, r.SIsMember("test_s", "d"))
+	assert.Equal(t, int64(1), r.SRem("test_s", "a", "d"))
+	members := make([]string, 0)
+	sScan := r.SScan("test_s", "", 1)
+	for sScan.Next() {
+		members = append(members, sScan.Val())
+	}
+	assert.ElementsMatch(t, []string{"b", "c"}, members)
+
+	r.HSet("test_scan_hash", "a", "1", "b", "2")
+	fields := make(map[string]string)
+	hScan := r.HScan("test_scan_hash", "", 10)
+	for hScan.Next() {
+		fields[hScan.Val()] = hScan.HashValue()
+	}
+	assert.Equal(t, map[string]string{"a": "1", "b": "2"}, fields)
+
+	r.Set("scan_key_1", "a", 10)
+	r.Set("scan_key_2", "a", 10)
+	keys := make([]string, 0)
+	scan := r.Scan("scan_key_*", 1)
+	for scan.Next() {
+		keys = append(keys, scan.Val())
+	}
+	assert.ElementsMatch(t, []string{"scan_key_1", "scan_key_2"}, keys)
+	assert.False(t, r.Scan("missing_*", 100).Next())
+
 	assert.NotEmpty(t, r.Info("modules"))
 
 	id := engine.GetEventBroker().Publish("test-stream", "a")
@@ -0,0 +1,148 @@
+package beeorm
+
+import (
+	"context"
+	"strings"
+
+	"github.com/go-redis/redis/v8"
+)
+
+const redisClusterSlots = 16384
+
+// all streams share one hash slot so a consumer can read many of them with a single XREADGROUP
+const redisClusterStreamTag = "{streams}"
+
+func redisClusterSlot(key string) int {
+	if start := strings.IndexByte(key, '{'); start >= 0 {
+		if end := strings.IndexByte(key[start+1:], '}'); end > 0 {
+			key = key[start+1 : start+1+end]
+		}
+	}
+	crc := uint16(0)
+	for i := 0; i < len(key); i++ {
+		crc ^= uint16(key[i]) << 8
+		for j := 0; j < 8; j++ {
+			if crc&0x8000 != 0 {
+				crc = crc<<1 ^ 0x1021
+			} else {
+				crc <<= 1
+			}
+		}
+	}
+	return int(crc) % redisClusterSlots
+}
+
+func groupRedisKeysBySlot(keys []string) [][]int {
+	slots := make(map[int]int)
+	groups := make([][]int, 0)
+	for i, key := range keys {
+		slot := redisClusterSlot(key)
+		group, has := slots[slot]
+		if !has {
+			group = len(groups)
+			slots[slot] = group
+			groups = append(groups, nil)
+		}
+		groups[group] = append(groups[group], i)
+	}
+	return groups
+}
+
+func selectRedisKeys(keys []string, indexes []int) []string {
+	selected := make([]string, len(indexes))
+	for i, index := range indexes {
+		selected[i] = keys[index]
+	}
+	return selected
+}
+
+func (r *RedisCache) clusterMGet(ctx context.Context, keys []string) ([]interface{}, error) {
+	groups := groupRedisKeysBySlot(keys)
+	if len(groups) == 1 {
+		return r.client.MGet(ctx, keys...).Result()
+	}
+	pipeline := r.client.Pipeline()
+	commands := make([]*redis.SliceCmd, len(groups))
+	for i, group := range groups {
+		commands[i] = pipeline.MGet(ctx, selectRedisKeys(keys, group)...)
+	}
+	_, err := pipeline.Exec(ctx)
+	if err != nil {
+		return nil, err
+	}
+	results := make([]interface{}, len(keys))
+	for i, group := range groups {
+		for j, value := range commands[i].Val() {
+			results[group[j]] = value
+		}
+	}
+	return results, nil
+}
+
+func (r *RedisCache) clusterMSet(ctx context.Context, pairs []interface{}) error {
+	keys := make([]string, len(pairs)/2)
+	for i := range keys {
+		keys[i] = pairs[i*2].(string)
+	}
+	groups := groupRedisKeysBySlot(keys)
+	if len(groups) == 1 {
+		return r.client.MSet(ctx, pairs...).Err()
+	}
+	pipeline := r.client.Pipeline()
+	for _, group := range groups {
+		groupPairs := make([]interface{}, 0, len(group)*2)
+		for _, index := range group {
+			groupPairs = append(groupPairs, pairs[index*2], pairs[index*2+1])
+		}
+		pipeline.MSet(ctx, groupPairs...)
+	}
+	_, err := pipeline.Exec(ctx)
+	return err
+}
+
+func (r *RedisCache) clusterKeysCommand(ctx context.Context, keys []string,
+	command func(client redis.Cmdable, keys ...string) *redis.IntCmd) (int64, error) {
+	groups := groupRedisKeysBySlot(keys)
+	if len(groups) == 1 {
+		return command(r.client, keys...).Result()
+	}
+	pipeline := r.client.Pipeline()
+	commands := make([]*redis.IntCmd, len(groups))
+	for i, group := range groups {
+		commands[i] = command(pipeline, selectRedisKeys(keys, group)...)
+	}
+	_, err := pipeline.Exec(ctx)
+	if err != nil {
+		return 0, err
+	}
+	total := int64(0)
+	for _, cmd := range commands {
+		total += cmd.Val()
+	}
+	return total, nil
+}
+
+func (r *RedisCache) forEachMaster(ctx context.Context, fn func(client redis.Cmdable) error) error {
+	cluster, isCluster := r.client.(*redis.ClusterClient)
+	if !isCluster {
+		return fn(r.client)
+	}
+	return cluster.ForEachMaster(ctx, func(ctx context.Context, client *redis.Client) error {
+		return fn(client)
+	})
+}
+
+func (r *RedisCache) addStreamPrefix(stream string) string {
+	if r.config.IsCluster() {
+		return r.addNamespacePrefix(redisClusterStreamTag + stream)
+	}
+	return r.addNamespacePrefix(stream)
+}
+
+func (r *RedisCache) removeStreamPrefix(stream string) string {
+	stream = r.removeNamespacePrefix(stream)
+	if r.config.IsCluster() {
+		return strings.TrimPrefix(stream, redisClusterStreamTag)
+	}
+	return stream
+}
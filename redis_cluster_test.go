@@ -0,0 +1,118 @@
+package beeorm
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+var redisClusterAddresses = []string{"127.0.0.1:7000", "127.0.0.1:7001", "127.0.0.1:7002", "127.0.0.1:7003", "127.0.0.1:7004", "127.0.0.1:7005"}
+
+func TestRedisClusterSlot(t *testing.T) {
+	assert.Equal(t, 12739, redisClusterSlot("123456789"))
+	assert.Equal(t, 12182, redisClusterSlot("foo"))
+	assert.Equal(t, redisClusterSlot("user1000"), redisClusterSlot("{user1000}.following"))
+	assert.Equal(t, redisClusterSlot("{user1000}.followers"), redisClusterSlot("{user1000}.following"))
+	assert.Equal(t, redisClusterSlot("foo{}{bar}"), redisClusterSlot("foo{}{bar}"))
+	assert.NotEqual(t, redisClusterSlot("bar"), redisClusterSlot("foo{}{bar}"))
+	assert.Equal(t, redisClusterSlot("{bar"), redisClusterSlot("foo{{bar}}zap"))
+
+	groups := groupRedisKeysBySlot([]string{"{a}1", "{b}1", "{a}2", "{b}2", "{c}1"})
+	assert.Equal(t, [][]int{{0, 2}, {1, 3}, {4}}, groups)
+}
+
+func TestRedisCluster(t *testing.T) {
+	testRedisCluster(t, "")
+}
+
+func TestRedisClusterNamespace(t *testing.T) {
+	testRedisCluster(t, "test")
+}
+
+func testRedisCluster(t *testing.T, namespace string) {
+	registry := &Registry{}
+	registry.RegisterRedisCluster(redisClusterAddresses, namespace)
+	registry.RegisterRedisStream("test-stream-a", "default", []string{"test-group"})
+	registry.RegisterRedisStream("test-stream-b", "default", []string{"test-group"})
+	validatedRegistry, def, err := registry.Validate()
+	assert.NoError(t, err)
+	defer def()
+	engine := validatedRegistry.CreateEngine()
+	r := engine.GetRedis()
+	assert.True(t, r.GetPoolConfig().IsCluster())
+	r.FlushDB()
+
+	pairs := make([]interface{}, 0)
+	keys := make([]string, 0)
+	for i := 1; i <= 20; i++ {
+		key := fmt.Sprintf("cluster_key_%d", i)
+		keys = append(keys, key)
+		pairs = append(pairs, key, fmt.Sprintf("value_%d", i))
+	}
+	assert.Greater(t, len(groupRedisKeysBySlot(keys)), 1)
+	r.MSet(pairs...)
+	values := r.MGet(append(keys, "cluster_missing")...)
+	assert.Len(t, values, 21)
+	for i := 0; i < 20; i++ {
+		assert.Equal(t, fmt.Sprintf("value_%d", i+1), values[i])
+	}
+	assert.Nil(t, values[20])
+	assert.Equal(t, int64(20), r.Exists(keys...))
+	r.Del(keys[0:10]...)
+	assert.Equal(t, int64(10), r.Exists(keys...))
+
+	pipeLine := r.PipeLine()
+	pipeLine.Del(keys[10:]...)
+	pipeLine.Set("cluster_pipeline", "ok", time.Minute)
+	pipeLine.Exec()
+	assert.Equal(t, int64(0), r.Exists(keys...))
+	val, has := r.Get("cluster_pipeline")
+	assert.True(t, has)
+	assert.Equal(t, "ok", val)
+
+	lock, has := r.GetLocker().Obtain("cluster_lock", time.Second, 0)
+	assert.True(t, has)
+	_, has = r.GetLocker().Obtain("cluster_lock", time.Second, time.Millisecond)
+	assert.False(t, has)
+	lock.Release()
+
+	type testEvent struct {
+		Name string
+	}
+	broker := engine.GetEventBroker()
+	eventFlusher := broker.NewFlusher()
+	for i := 1; i <= 10; i++ {
+		eventFlusher.Publish("test-stream-a", testEvent{fmt.Sprintf("a%d", i)})
+		eventFlusher.Publish("test-stream-b", testEvent{fmt.Sprintf("b%d", i)})
+	}
+	eventFlusher.Flush()
+	assert.Equal(t, int64(10), r.XLen("test-stream-a"))
+	assert.Equal(t, int64(10), r.XLen("test-stream-b"))
+
+	consumer := broker.Consumer("test-group")
+	consumer.(*eventsConsumer).blockTime = time.Millisecond
+	consumer.DisableLoop()
+	consumed := make(map[string]int)
+	consumer.Consume(context.Background(), 100, func(events []Event) {
+		for _, event := range events {
+			consumed[event.Stream()]++
+		}
+	})
+	assert.Equal(t, map[string]int{"test-stream-a": 10, "test-stream-b": 10}, consumed)
+
+	consumer.(*eventsConsumer).garbageLastTick = 0
+	consumer.(*eventsConsumer).garbage()
+	backgroundConsumer := NewBackgroundConsumer(engine)
+	backgroundConsumer.DisableLoop()
+	backgroundConsumer.blockTime = time.Millisecond
+	backgroundConsumer.Digest(context.Background())
+	assert.Equal(t, int64(0), r.XLen("test-stream-a"))
+	assert.Equal(t, int64(0), r.XLen("test-stream-b"))
+
+	r.FlushDB()
+	_, has = r.Get("cluster_pipeline")
+	assert.False(t, has)
+}
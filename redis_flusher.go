@@ -4,6 +4,7 @@ const (
 	commandDelete = iota
 	commandXAdd   = iota
 	commandHSet   = iota
+	commandListUpdate
 )
 
 type redisFlusherCommands struct {
@@ -12,6 +13,7 @@ type redisFlusherCommands struct {
 	deletes []string
 	hSets   map[string][]interface{}
 	events  map[string][][]string
+	lists   []*cachedListUpdate
 }
 
 type redisFlusher struct {
@@ -80,6 +82,23 @@ func (f *redisFlusher) HSet(redisPool, key string, values ...interface{}) {
 	commands.hSets[key] = append(commands.hSets[key], values...)
 }
 
+func (f *redisFlusher) UpdateCachedList(redisPool string, updates ...*cachedListUpdate) {
+	if len(updates) == 0 {
+		return
+	}
+	if f.pipelines == nil {
+		f.pipelines = make(map[string]*redisFlusherCommands)
+	}
+	commands, has := f.pipelines[redisPool]
+	if !has {
+		commands = &redisFlusherCommands{lists: updates, diffs: map[int]bool{commandListUpdate: true}}
+		f.pipelines[redisPool] = commands
+		return
+	}
+	commands.diffs[commandListUpdate] = true
+	commands.lists = append(commands.lists, updates...)
+}
+
 func (f *redisFlusher) Flush() {
 	for poolCode, commands := range f.pipelines {
 		usePool := commands.usePool || len(commands.diffs) > 1 || len(commands.events) > 1 || len(commands.hSets) > 1
@@ -113,6 +132,12 @@ func (f *redisFlusher) Flush() {
 				}
 			}
 		}
+		if len(commands.lists) > 0 {
+			r := f.engine.GetRedis(poolCode)
+			for _, update := range commands.lists {
+				update.apply(r)
+			}
+		}
 	}
 	f.pipelines = nil
 }
@@ -10,6 +10,8 @@ import (
 	"github.com/go-redis/redis/v8"
 )
 
+// RedisPipeLine supports RedisCache commands except GetSet, which calls provider between commands,
+// and XRead and XReadGroup, which block all other commands in pipeline
 type RedisPipeLine struct {
 	r        *RedisCache
 	pool     string
@@ -17,109 +19,780 @@ type RedisPipeLine struct {
 	ctx      context.Context
 	commands int
 	log      []string
+	tx       bool
+	ignored  map[redis.Cmder]string
 }
 
+const pipeLineGetDelScript = "local v = redis.call('GET', KEYS[1]) redis.call('DEL', KEYS[1]) return v"
+
 func (rp *RedisPipeLine) Del(key ...string) {
 	for i, v := range key {
 		key[i] = rp.r.addNamespacePrefix(v)
 	}
 	rp.commands++
 	if rp.r.engine.hasRedisLogger {
-		rp.log = append(rp.log, "DEL")
-		rp.log = append(rp.log, key...)
+		rp.log = append(rp.log, "DEL")
+		rp.log = append(rp.log, key...)
+	}
+	if rp.r.config.IsCluster() {
+		for _, k := range key {
+			rp.pipeLine.Del(rp.ctx, k)
+		}
+		return
+	}
+	rp.pipeLine.Del(rp.ctx, key...)
+}
+
+func (rp *RedisPipeLine) Get(key string) *PipeLineGet {
+	key = rp.r.addNamespacePrefix(key)
+	rp.commands++
+	if rp.r.engine.hasRedisLogger {
+		rp.log = append(rp.log, "GET", key)
+	}
+	return &PipeLineGet{p: rp, cmd: rp.pipeLine.Get(rp.ctx, key)}
+}
+
+func (rp *RedisPipeLine) Set(key string, value interface{}, expiration time.Duration) {
+	key = rp.r.addNamespacePrefix(key)
+	rp.commands++
+	if rp.r.engine.hasRedisLogger {
+		rp.log = append(rp.log, "SET", key, expiration.String())
+	}
+	rp.pipeLine.Set(rp.ctx, key, value, expiration)
+}
+
+func (rp *RedisPipeLine) Expire(key string, expiration time.Duration) *PipeLineBool {
+	key = rp.r.addNamespacePrefix(key)
+	rp.commands++
+	if rp.r.engine.hasRedisLogger {
+		rp.log = append(rp.log, "EXPIRE", key, expiration.String())
+	}
+	return &PipeLineBool{p: rp, cmd: rp.pipeLine.Expire(rp.ctx, key, expiration)}
+}
+
+func (rp *RedisPipeLine) HIncrBy(key, field string, incr int64) *PipeLineInt {
+	key = rp.r.addNamespacePrefix(key)
+	rp.commands++
+	if rp.r.engine.hasRedisLogger {
+		rp.log = append(rp.log, "HINCRBY", key, field, strconv.Itoa(int(incr)))
+	}
+	return &PipeLineInt{p: rp, cmd: rp.pipeLine.HIncrBy(rp.ctx, key, field, incr)}
+}
+
+func (rp *RedisPipeLine) HSet(key string, values ...interface{}) {
+	key = rp.r.addNamespacePrefix(key)
+	rp.commands++
+	if rp.r.engine.hasRedisLogger {
+		rp.log = append(rp.log, "HSET", key)
+		for _, v := range values {
+			rp.log = append(rp.log, fmt.Sprintf("%v", v))
+		}
+	}
+	rp.pipeLine.HSet(rp.ctx, key, values...)
+}
+
+func (rp *RedisPipeLine) HDel(key string, values ...string) {
+	key = rp.r.addNamespacePrefix(key)
+	rp.commands++
+	if rp.r.engine.hasRedisLogger {
+		rp.log = append(rp.log, "HDEL", key)
+		rp.log = append(rp.log, values...)
+	}
+	rp.pipeLine.HDel(rp.ctx, key, values...)
+}
+
+func (rp *RedisPipeLine) XAdd(stream string, values []string) *PipeLineString {
+	stream = rp.r.addStreamPrefix(stream)
+	rp.commands++
+	if rp.r.engine.hasRedisLogger {
+		rp.log = append(rp.log, "XADD", stream)
+		rp.log = append(rp.log, values...)
+	}
+	return &PipeLineString{p: rp, cmd: rp.pipeLine.XAdd(rp.ctx, &redis.XAddArgs{Stream: stream, Values: values})}
+}
+
+func (rp *RedisPipeLine) SetNX(key string, value interface{}, expiration time.Duration) *PipeLineBool {
+	key = rp.r.addNamespacePrefix(key)
+	rp.commands++
+	if rp.r.engine.hasRedisLogger {
+		rp.log = append(rp.log, "SETNX", key, expiration.String())
+	}
+	return &PipeLineBool{p: rp, cmd: rp.pipeLine.SetNX(rp.ctx, key, value, expiration)}
+}
+
+func (rp *RedisPipeLine) MSet(pairs ...interface{}) {
+	for i := 0; i < len(pairs); i = i + 2 {
+		pairs[i] = rp.r.addNamespacePrefix(pairs[i].(string))
+	}
+	rp.commands++
+	if rp.r.engine.hasRedisLogger {
+		rp.log = append(rp.log, "MSET")
+		for _, v := range pairs {
+			rp.log = append(rp.log, fmt.Sprintf("%v", v))
+		}
+	}
+	if rp.r.config.IsCluster() {
+		for i := 0; i < len(pairs); i = i + 2 {
+			rp.pipeLine.Set(rp.ctx, pairs[i].(string), pairs[i+1], 0)
+		}
+		return
+	}
+	rp.pipeLine.MSet(rp.ctx, pairs...)
+}
+
+func (rp *RedisPipeLine) MGet(keys ...string) *PipeLineSlice {
+	for i, key := range keys {
+		keys[i] = rp.r.addNamespacePrefix(key)
+	}
+	rp.commands++
+	if rp.r.engine.hasRedisLogger {
+		rp.log = append(rp.log, "MGET")
+		rp.log = append(rp.log, keys...)
+	}
+	if rp.r.config.IsCluster() {
+		result := &PipeLineSlice{p: rp, gets: make([]*redis.StringCmd, len(keys))}
+		for i, key := range keys {
+			result.gets[i] = rp.pipeLine.Get(rp.ctx, key)
+		}
+		return result
+	}
+	return &PipeLineSlice{p: rp, cmd: rp.pipeLine.MGet(rp.ctx, keys...)}
+}
+
+func (rp *RedisPipeLine) Exists(keys ...string) *PipeLineInt {
+	for i, key := range keys {
+		keys[i] = rp.r.addNamespacePrefix(key)
+	}
+	rp.commands++
+	if rp.r.engine.hasRedisLogger {
+		rp.log = append(rp.log, "EXISTS")
+		rp.log = append(rp.log, keys...)
+	}
+	if rp.r.config.IsCluster() {
+		result := &PipeLineInt{p: rp, cmds: make([]*redis.IntCmd, len(keys))}
+		for i, key := range keys {
+			result.cmds[i] = rp.pipeLine.Exists(rp.ctx, key)
+		}
+		return result
+	}
+	return &PipeLineInt{p: rp, cmd: rp.pipeLine.Exists(rp.ctx, keys...)}
+}
+
+func (rp *RedisPipeLine) Type(key string) *PipeLineStatus {
+	key = rp.r.addNamespacePrefix(key)
+	rp.commands++
+	if rp.r.engine.hasRedisLogger {
+		rp.log = append(rp.log, "TYPE", key)
+	}
+	return &PipeLineStatus{p: rp, cmd: rp.pipeLine.Type(rp.ctx, key)}
+}
+
+func (rp *RedisPipeLine) TTL(key string) *PipeLineDuration {
+	key = rp.r.addNamespacePrefix(key)
+	rp.commands++
+	if rp.r.engine.hasRedisLogger {
+		rp.log = append(rp.log, "TTL", key)
+	}
+	return &PipeLineDuration{p: rp, cmd: rp.pipeLine.TTL(rp.ctx, key)}
+}
+
+func (rp *RedisPipeLine) Persist(key string) *PipeLineBool {
+	key = rp.r.addNamespacePrefix(key)
+	rp.commands++
+	if rp.r.engine.hasRedisLogger {
+		rp.log = append(rp.log, "PERSIST", key)
+	}
+	return &PipeLineBool{p: rp, cmd: rp.pipeLine.Persist(rp.ctx, key)}
+}
+
+func (rp *RedisPipeLine) Incr(key string) *PipeLineInt {
+	key = rp.r.addNamespacePrefix(key)
+	rp.commands++
+	if rp.r.engine.hasRedisLogger {
+		rp.log = append(rp.log, "INCR", key)
+	}
+	return &PipeLineInt{p: rp, cmd: rp.pipeLine.Incr(rp.ctx, key)}
+}
+
+func (rp *RedisPipeLine) IncrBy(key string, incr int64) *PipeLineInt {
+	key = rp.r.addNamespacePrefix(key)
+	rp.commands++
+	if rp.r.engine.hasRedisLogger {
+		rp.log = append(rp.log, "INCRBY", key, strconv.FormatInt(incr, 10))
+	}
+	return &PipeLineInt{p: rp, cmd: rp.pipeLine.IncrBy(rp.ctx, key, incr)}
+}
+
+func (rp *RedisPipeLine) LPush(key string, values ...interface{}) *PipeLineInt {
+	key = rp.r.addNamespacePrefix(key)
+	rp.commands++
+	if rp.r.engine.hasRedisLogger {
+		rp.log = append(rp.log, "LPUSH", key)
+		for _, v := range values {
+			rp.log = append(rp.log, fmt.Sprintf("%v", v))
+		}
+	}
+	return &PipeLineInt{p: rp, cmd: rp.pipeLine.LPush(rp.ctx, key, values...)}
+}
+
+func (rp *RedisPipeLine) RPush(key string, values ...interface{}) *PipeLineInt {
+	key = rp.r.addNamespacePrefix(key)
+	rp.commands++
+	if rp.r.engine.hasRedisLogger {
+		rp.log = append(rp.log, "RPUSH", key)
+		for _, v := range values {
+			rp.log = append(rp.log, fmt.Sprintf("%v", v))
+		}
+	}
+	return &PipeLineInt{p: rp, cmd: rp.pipeLine.RPush(rp.ctx, key, values...)}
+}
+
+func (rp *RedisPipeLine) LLen(key string) *PipeLineInt {
+	key = rp.r.addNamespacePrefix(key)
+	rp.commands++
+	if rp.r.engine.hasRedisLogger {
+		rp.log = append(rp.log, "LLEN", key)
+	}
+	return &PipeLineInt{p: rp, cmd: rp.pipeLine.LLen(rp.ctx, key)}
+}
+
+func (rp *RedisPipeLine) LRange(key string, start, stop int64) *PipeLineStrings {
+	key = rp.r.addNamespacePrefix(key)
+	rp.commands++
+	if rp.r.engine.hasRedisLogger {
+		rp.log = append(rp.log, "LRANGE", key, strconv.FormatInt(start, 10), strconv.FormatInt(stop, 10))
+	}
+	return &PipeLineStrings{p: rp, cmd: rp.pipeLine.LRange(rp.ctx, key, start, stop)}
+}
+
+func (rp *RedisPipeLine) LSet(key string, index int64, value interface{}) {
+	key = rp.r.addNamespacePrefix(key)
+	rp.commands++
+	if rp.r.engine.hasRedisLogger {
+		rp.log = append(rp.log, "LSET", key, strconv.FormatInt(index, 10), fmt.Sprintf("%v", value))
+	}
+	rp.pipeLine.LSet(rp.ctx, key, index, value)
+}
+
+func (rp *RedisPipeLine) RPop(key string) *PipeLineGet {
+	key = rp.r.addNamespacePrefix(key)
+	rp.commands++
+	if rp.r.engine.hasRedisLogger {
+		rp.log = append(rp.log, "RPOP", key)
+	}
+	return &PipeLineGet{p: rp, cmd: rp.pipeLine.RPop(rp.ctx, key)}
+}
+
+func (rp *RedisPipeLine) LRem(key string, count int64, value interface{}) {
+	key = rp.r.addNamespacePrefix(key)
+	rp.commands++
+	if rp.r.engine.hasRedisLogger {
+		rp.log = append(rp.log, "LREM", key, strconv.FormatInt(count, 10), fmt.Sprintf("%v", value))
+	}
+	rp.pipeLine.LRem(rp.ctx, key, count, value)
+}
+
+func (rp *RedisPipeLine) Ltrim(key string, start, stop int64) {
+	key = rp.r.addNamespacePrefix(key)
+	rp.commands++
+	if rp.r.engine.hasRedisLogger {
+		rp.log = append(rp.log, "LTRIM", key, strconv.FormatInt(start, 10), strconv.FormatInt(stop, 10))
+	}
+	rp.pipeLine.LTrim(rp.ctx, key, start, stop)
+}
+
+func (rp *RedisPipeLine) HSetNx(key, field string, value interface{}) *PipeLineBool {
+	key = rp.r.addNamespacePrefix(key)
+	rp.commands++
+	if rp.r.engine.hasRedisLogger {
+		rp.log = append(rp.log, "HSETNX", key, field, fmt.Sprintf("%v", value))
+	}
+	return &PipeLineBool{p: rp, cmd: rp.pipeLine.HSetNX(rp.ctx, key, field, value)}
+}
+
+func (rp *RedisPipeLine) HMGet(key string, fields ...string) *PipeLineHMGet {
+	key = rp.r.addNamespacePrefix(key)
+	rp.commands++
+	if rp.r.engine.hasRedisLogger {
+		rp.log = append(rp.log, "HMGET", key)
+		rp.log = append(rp.log, fields...)
+	}
+	return &PipeLineHMGet{p: rp, fields: fields, cmd: rp.pipeLine.HMGet(rp.ctx, key, fields...)}
+}
+
+func (rp *RedisPipeLine) HGetAll(key string) *PipeLineStringMap {
+	key = rp.r.addNamespacePrefix(key)
+	rp.commands++
+	if rp.r.engine.hasRedisLogger {
+		rp.log = append(rp.log, "HGETALL", key)
+	}
+	return &PipeLineStringMap{p: rp, cmd: rp.pipeLine.HGetAll(rp.ctx, key)}
+}
+
+func (rp *RedisPipeLine) HGet(key, field string) *PipeLineGet {
+	key = rp.r.addNamespacePrefix(key)
+	rp.commands++
+	if rp.r.engine.hasRedisLogger {
+		rp.log = append(rp.log, "HGET", key, field)
+	}
+	return &PipeLineGet{p: rp, cmd: rp.pipeLine.HGet(rp.ctx, key, field)}
+}
+
+func (rp *RedisPipeLine) HLen(key string) *PipeLineInt {
+	key = rp.r.addNamespacePrefix(key)
+	rp.commands++
+	if rp.r.engine.hasRedisLogger {
+		rp.log = append(rp.log, "HLEN", key)
+	}
+	return &PipeLineInt{p: rp, cmd: rp.pipeLine.HLen(rp.ctx, key)}
+}
+
+func (rp *RedisPipeLine) ZAdd(key string, members ...*redis.Z) *PipeLineInt {
+	key = rp.r.addNamespacePrefix(key)
+	rp.commands++
+	if rp.r.engine.hasRedisLogger {
+		rp.log = append(rp.log, "ZADD", key)
+		for _, v := range members {
+			rp.log = append(rp.log, fmt.Sprintf("%f %v", v.Score, v.Member))
+		}
+	}
+	return &PipeLineInt{p: rp, cmd: rp.pipeLine.ZAdd(rp.ctx, key, members...)}
+}
+
+func (rp *RedisPipeLine) ZRem(key string, members ...interface{}) *PipeLineInt {
+	key = rp.r.addNamespacePrefix(key)
+	rp.commands++
+	if rp.r.engine.hasRedisLogger {
+		rp.log = append(rp.log, "ZREM", key)
+		for _, v := range members {
+			rp.log = append(rp.log, fmt.Sprintf("%v", v))
+		}
+	}
+	return &PipeLineInt{p: rp, cmd: rp.pipeLine.ZRem(rp.ctx, key, members...)}
+}
+
+func (rp *RedisPipeLine) ZRevRange(key string, start, stop int64) *PipeLineStrings {
+	key = rp.r.addNamespacePrefix(key)
+	rp.commands++
+	if rp.r.engine.hasRedisLogger {
+		rp.log = append(rp.log, "ZREVRANGE", key, strconv.FormatInt(start, 10), strconv.FormatInt(stop, 10))
+	}
+	return &PipeLineStrings{p: rp, cmd: rp.pipeLine.ZRevRange(rp.ctx, key, start, stop)}
+}
+
+func (rp *RedisPipeLine) ZRevRangeWithScores(key string, start, stop int64) *PipeLineZ {
+	key = rp.r.addNamespacePrefix(key)
+	rp.commands++
+	if rp.r.engine.hasRedisLogger {
+		rp.log = append(rp.log, "ZREVRANGESCORE", key, strconv.FormatInt(start, 10), strconv.FormatInt(stop, 10))
+	}
+	return &PipeLineZ{p: rp, cmd: rp.pipeLine.ZRevRangeWithScores(rp.ctx, key, start, stop)}
+}
+
+func (rp *RedisPipeLine) ZRangeWithScores(key string, start, stop int64) *PipeLineZ {
+	key = rp.r.addNamespacePrefix(key)
+	rp.commands++
+	if rp.r.engine.hasRedisLogger {
+		rp.log = append(rp.log, "ZRANGESCORE", key, strconv.FormatInt(start, 10), strconv.FormatInt(stop, 10))
+	}
+	return &PipeLineZ{p: rp, cmd: rp.pipeLine.ZRangeWithScores(rp.ctx, key, start, stop)}
+}
+
+func (rp *RedisPipeLine) ZRangeByScore(key string, opt *redis.ZRangeBy) *PipeLineStrings {
+	key = rp.r.addNamespacePrefix(key)
+	rp.commands++
+	if rp.r.engine.hasRedisLogger {
+		rp.log = append(rp.log, "ZRANGEBYSCORE", key, opt.Min, opt.Max)
+	}
+	return &PipeLineStrings{p: rp, cmd: rp.pipeLine.ZRangeByScore(rp.ctx, key, opt)}
+}
+
+func (rp *RedisPipeLine) ZRangeByScoreWithScores(key string, opt *redis.ZRangeBy) *PipeLineZ {
+	key = rp.r.addNamespacePrefix(key)
+	rp.commands++
+	if rp.r.engine.hasRedisLogger {
+		rp.log = append(rp.log, "ZRANGEBYSCORE", key, opt.Min, opt.Max, "WITHSCORES")
+	}
+	return &PipeLineZ{p: rp, cmd: rp.pipeLine.ZRangeByScoreWithScores(rp.ctx, key, opt)}
+}
+
+func (rp *RedisPipeLine) ZPopMin(key string, count int64) *PipeLineZ {
+	key = rp.r.addNamespacePrefix(key)
+	rp.commands++
+	if rp.r.engine.hasRedisLogger {
+		rp.log = append(rp.log, "ZPOPMIN", key, strconv.FormatInt(count, 10))
+	}
+	return &PipeLineZ{p: rp, cmd: rp.pipeLine.ZPopMin(rp.ctx, key, count)}
+}
+
+func (rp *RedisPipeLine) ZCard(key string) *PipeLineInt {
+	key = rp.r.addNamespacePrefix(key)
+	rp.commands++
+	if rp.r.engine.hasRedisLogger {
+		rp.log = append(rp.log, "ZCARD", key)
+	}
+	return &PipeLineInt{p: rp, cmd: rp.pipeLine.ZCard(rp.ctx, key)}
+}
+
+func (rp *RedisPipeLine) ZCount(key string, min, max string) *PipeLineInt {
+	key = rp.r.addNamespacePrefix(key)
+	rp.commands++
+	if rp.r.engine.hasRedisLogger {
+		rp.log = append(rp.log, "ZCOUNT", key, min, max)
+	}
+	return &PipeLineInt{p: rp, cmd: rp.pipeLine.ZCount(rp.ctx, key, min, max)}
+}
+
+func (rp *RedisPipeLine) ZScore(key, member string) *PipeLineFloat {
+	key = rp.r.addNamespacePrefix(key)
+	rp.commands++
+	if rp.r.engine.hasRedisLogger {
+		rp.log = append(rp.log, "ZSCORE", key, member)
+	}
+	return &PipeLineFloat{p: rp, cmd: rp.pipeLine.ZScore(rp.ctx, key, member)}
+}
+
+func (rp *RedisPipeLine) ZIncrBy(key string, increment float64, member string) *PipeLineFloat {
+	key = rp.r.addNamespacePrefix(key)
+	rp.commands++
+	if rp.r.engine.hasRedisLogger {
+		rp.log = append(rp.log, "ZINCRBY", key, strconv.FormatFloat(increment, 'f', -1, 64), member)
+	}
+	return &PipeLineFloat{p: rp, cmd: rp.pipeLine.ZIncrBy(rp.ctx, key, increment, member)}
+}
+
+func (rp *RedisPipeLine) SAdd(key string, members ...interface{}) *PipeLineInt {
+	key = rp.r.addNamespacePrefix(key)
+	rp.commands++
+	if rp.r.engine.hasRedisLogger {
+		rp.log = append(rp.log, "SADD", key)
+		for _, v := range members {
+			rp.log = append(rp.log, fmt.Sprintf("%v", v))
+		}
+	}
+	return &PipeLineInt{p: rp, cmd: rp.pipeLine.SAdd(rp.ctx, key, members...)}
+}
+
+func (rp *RedisPipeLine) SRem(key string, members ...interface{}) *PipeLineInt {
+	key = rp.r.addNamespacePrefix(key)
+	rp.commands++
+	if rp.r.engine.hasRedisLogger {
+		rp.log = append(rp.log, "SREM", key)
+		for _, v := range members {
+			rp.log = append(rp.log, fmt.Sprintf("%v", v))
+		}
+	}
+	return &PipeLineInt{p: rp, cmd: rp.pipeLine.SRem(rp.ctx, key, members...)}
+}
+
+func (rp *RedisPipeLine) SCard(key string) *PipeLineInt {
+	key = rp.r.addNamespacePrefix(key)
+	rp.commands++
+	if rp.r.engine.hasRedisLogger {
+		rp.log = append(rp.log, "SCARD", key)
+	}
+	return &PipeLineInt{p: rp, cmd: rp.pipeLine.SCard(rp.ctx, key)}
+}
+
+func (rp *RedisPipeLine) SPop(key string) *PipeLineGet {
+	key = rp.r.addNamespacePrefix(key)
+	rp.commands++
+	if rp.r.engine.hasRedisLogger {
+		rp.log = append(rp.log, "SPOP", key)
 	}
-	rp.pipeLine.Del(rp.ctx, key...)
+	return &PipeLineGet{p: rp, cmd: rp.pipeLine.SPop(rp.ctx, key)}
 }
 
-func (rp *RedisPipeLine) Get(key string) *PipeLineGet {
+func (rp *RedisPipeLine) SPopN(key string, max int64) *PipeLineStrings {
 	key = rp.r.addNamespacePrefix(key)
 	rp.commands++
 	if rp.r.engine.hasRedisLogger {
-		rp.log = append(rp.log, "GET", key)
+		rp.log = append(rp.log, "SPOPN", key, strconv.FormatInt(max, 10))
 	}
-	return &PipeLineGet{p: rp, cmd: rp.pipeLine.Get(rp.ctx, key)}
+	return &PipeLineStrings{p: rp, cmd: rp.pipeLine.SPopN(rp.ctx, key, max)}
 }
 
-func (rp *RedisPipeLine) Set(key string, value interface{}, expiration time.Duration) {
+func (rp *RedisPipeLine) SMembers(key string) *PipeLineStrings {
 	key = rp.r.addNamespacePrefix(key)
 	rp.commands++
 	if rp.r.engine.hasRedisLogger {
-		rp.log = append(rp.log, "SET", key, expiration.String())
+		rp.log = append(rp.log, "SMEMBERS", key)
 	}
-	rp.pipeLine.Set(rp.ctx, key, value, expiration)
+	return &PipeLineStrings{p: rp, cmd: rp.pipeLine.SMembers(rp.ctx, key)}
 }
 
-func (rp *RedisPipeLine) Expire(key string, expiration time.Duration) *PipeLineBool {
+func (rp *RedisPipeLine) SIsMember(key string, member interface{}) *PipeLineBool {
 	key = rp.r.addNamespacePrefix(key)
 	rp.commands++
 	if rp.r.engine.hasRedisLogger {
-		rp.log = append(rp.log, "EXPIRE", key, expiration.String())
+		rp.log = append(rp.log, "SISMEMBER", key, fmt.Sprintf("%v", member))
 	}
-	return &PipeLineBool{p: rp, cmd: rp.pipeLine.Expire(rp.ctx, key, expiration)}
+	return &PipeLineBool{p: rp, cmd: rp.pipeLine.SIsMember(rp.ctx, key, member)}
 }
 
-func (rp *RedisPipeLine) HIncrBy(key, field string, incr int64) *PipeLineInt {
-	key = rp.r.addNamespacePrefix(key)
+func (rp *RedisPipeLine) XLen(stream string) *PipeLineInt {
+	stream = rp.r.addStreamPrefix(stream)
 	rp.commands++
 	if rp.r.engine.hasRedisLogger {
-		rp.log = append(rp.log, "HINCRBY", key, field, strconv.Itoa(int(incr)))
+		rp.log = append(rp.log, "XLEN", stream)
 	}
-	return &PipeLineInt{p: rp, cmd: rp.pipeLine.HIncrBy(rp.ctx, key, field, incr)}
+	return &PipeLineInt{p: rp, cmd: rp.pipeLine.XLen(rp.ctx, stream)}
 }
 
-func (rp *RedisPipeLine) HSet(key string, values ...interface{}) {
+func (rp *RedisPipeLine) XDel(stream string, ids ...string) *PipeLineInt {
+	stream = rp.r.addStreamPrefix(stream)
+	rp.commands++
+	if rp.r.engine.hasRedisLogger {
+		rp.log = append(rp.log, "XDEL", stream)
+		rp.log = append(rp.log, ids...)
+	}
+	return &PipeLineInt{p: rp, cmd: rp.pipeLine.XDel(rp.ctx, stream, ids...)}
+}
+
+func (rp *RedisPipeLine) XAck(stream, group string, ids ...string) *PipeLineInt {
+	stream = rp.r.addStreamPrefix(stream)
+	group = rp.r.addNamespacePrefix(group)
+	rp.commands++
+	if rp.r.engine.hasRedisLogger {
+		rp.log = append(rp.log, "XACK", stream, group)
+		rp.log = append(rp.log, ids...)
+	}
+	return &PipeLineInt{p: rp, cmd: rp.pipeLine.XAck(rp.ctx, stream, group, ids...)}
+}
+
+func (rp *RedisPipeLine) XTrim(stream string, maxLen int64) *PipeLineInt {
+	stream = rp.r.addStreamPrefix(stream)
+	rp.commands++
+	if rp.r.engine.hasRedisLogger {
+		rp.log = append(rp.log, "XTRIM", stream, strconv.FormatInt(maxLen, 10))
+	}
+	return &PipeLineInt{p: rp, cmd: rp.pipeLine.XTrimMaxLen(rp.ctx, stream, maxLen)}
+}
+
+func (rp *RedisPipeLine) GetDel(key string) *PipeLineGet {
 	key = rp.r.addNamespacePrefix(key)
 	rp.commands++
 	if rp.r.engine.hasRedisLogger {
-		rp.log = append(rp.log, "HSET", key)
-		for _, v := range values {
-			rp.log = append(rp.log, fmt.Sprintf("%v", v))
-		}
+		rp.log = append(rp.log, "GETDEL", key)
 	}
-	rp.pipeLine.HSet(rp.ctx, key, values...)
+	return &PipeLineGet{p: rp, eval: rp.pipeLine.Eval(rp.ctx, pipeLineGetDelScript, []string{key})}
 }
 
-func (rp *RedisPipeLine) HDel(key string, values ...string) {
+func (rp *RedisPipeLine) IncrWithExpire(key string, expire time.Duration) *PipeLineInt {
 	key = rp.r.addNamespacePrefix(key)
 	rp.commands++
 	if rp.r.engine.hasRedisLogger {
-		rp.log = append(rp.log, "HDEL", key)
-		rp.log = append(rp.log, values...)
+		rp.log = append(rp.log, "INCR EXP", key, expire.String())
 	}
-	rp.pipeLine.HDel(rp.ctx, key, values...)
+	cmd := rp.pipeLine.Incr(rp.ctx, key)
+	rp.pipeLine.Expire(rp.ctx, key, expire)
+	return &PipeLineInt{p: rp, cmd: cmd}
 }
 
-func (rp *RedisPipeLine) XAdd(stream string, values []string) *PipeLineString {
-	stream = rp.r.addNamespacePrefix(stream)
+func (rp *RedisPipeLine) XRange(stream, start, stop string, count int64) *PipeLineXMessages {
+	stream = rp.r.addStreamPrefix(stream)
 	rp.commands++
 	if rp.r.engine.hasRedisLogger {
-		rp.log = append(rp.log, "XADD", stream)
-		rp.log = append(rp.log, values...)
+		rp.log = append(rp.log, "XRANGE", stream, start, stop, strconv.FormatInt(count, 10))
 	}
-	return &PipeLineString{p: rp, cmd: rp.pipeLine.XAdd(rp.ctx, &redis.XAddArgs{Stream: stream, Values: values})}
+	return &PipeLineXMessages{p: rp, cmd: rp.pipeLine.XRangeN(rp.ctx, stream, start, stop, count)}
+}
+
+func (rp *RedisPipeLine) XRevRange(stream, start, stop string, count int64) *PipeLineXMessages {
+	stream = rp.r.addStreamPrefix(stream)
+	rp.commands++
+	if rp.r.engine.hasRedisLogger {
+		rp.log = append(rp.log, "XREVRANGE", stream, start, stop, strconv.FormatInt(count, 10))
+	}
+	return &PipeLineXMessages{p: rp, cmd: rp.pipeLine.XRevRangeN(rp.ctx, stream, start, stop, count)}
+}
+
+func (rp *RedisPipeLine) XInfoStream(stream string) *PipeLineXInfoStream {
+	stream = rp.r.addStreamPrefix(stream)
+	rp.commands++
+	if rp.r.engine.hasRedisLogger {
+		rp.log = append(rp.log, "XINFOSTREAM", stream)
+	}
+	return &PipeLineXInfoStream{p: rp, cmd: rp.pipeLine.XInfoStream(rp.ctx, stream)}
+}
+
+func (rp *RedisPipeLine) XInfoGroups(stream string) *PipeLineXInfoGroups {
+	stream = rp.r.addStreamPrefix(stream)
+	rp.commands++
+	if rp.r.engine.hasRedisLogger {
+		rp.log = append(rp.log, "XINFOGROUPS", stream)
+	}
+	cmd := rp.pipeLine.XInfoGroups(rp.ctx, stream)
+	rp.ignore(cmd, "ERR no such key")
+	return &PipeLineXInfoGroups{p: rp, cmd: cmd}
+}
+
+func (rp *RedisPipeLine) XGroupCreate(stream, group, start string) *PipeLineXGroupCreate {
+	stream = rp.r.addStreamPrefix(stream)
+	group = rp.r.addNamespacePrefix(group)
+	rp.commands++
+	if rp.r.engine.hasRedisLogger {
+		rp.log = append(rp.log, "XGROUPCREATE", stream, group, start)
+	}
+	cmd := rp.pipeLine.XGroupCreate(rp.ctx, stream, group, start)
+	rp.ignore(cmd, "BUSYGROUP")
+	return &PipeLineXGroupCreate{p: rp, cmd: cmd}
+}
+
+func (rp *RedisPipeLine) XGroupCreateMkStream(stream, group, start string) *PipeLineXGroupCreate {
+	stream = rp.r.addStreamPrefix(stream)
+	group = rp.r.addNamespacePrefix(group)
+	rp.commands++
+	if rp.r.engine.hasRedisLogger {
+		rp.log = append(rp.log, "XGROUPCRMKSM", stream, group, start)
+	}
+	cmd := rp.pipeLine.XGroupCreateMkStream(rp.ctx, stream, group, start)
+	rp.ignore(cmd, "BUSYGROUP")
+	return &PipeLineXGroupCreate{p: rp, cmd: cmd}
+}
+
+func (rp *RedisPipeLine) XGroupDestroy(stream, group string) *PipeLineInt {
+	stream = rp.r.addStreamPrefix(stream)
+	group = rp.r.addNamespacePrefix(group)
+	rp.commands++
+	if rp.r.engine.hasRedisLogger {
+		rp.log = append(rp.log, "XGROUPCDESTROY", stream, group)
+	}
+	return &PipeLineInt{p: rp, cmd: rp.pipeLine.XGroupDestroy(rp.ctx, stream, group)}
+}
+
+func (rp *RedisPipeLine) XGroupDelConsumer(stream, group, consumer string) *PipeLineInt {
+	stream = rp.r.addStreamPrefix(stream)
+	group = rp.r.addNamespacePrefix(group)
+	rp.commands++
+	if rp.r.engine.hasRedisLogger {
+		rp.log = append(rp.log, "XGROUPDELCONSUMER", stream, group, consumer)
+	}
+	return &PipeLineInt{p: rp, cmd: rp.pipeLine.XGroupDelConsumer(rp.ctx, stream, group, consumer)}
+}
+
+func (rp *RedisPipeLine) XPending(stream, group string) *PipeLineXPending {
+	stream = rp.r.addStreamPrefix(stream)
+	group = rp.r.addNamespacePrefix(group)
+	rp.commands++
+	if rp.r.engine.hasRedisLogger {
+		rp.log = append(rp.log, "XPENDING", stream, group)
+	}
+	return &PipeLineXPending{p: rp, cmd: rp.pipeLine.XPending(rp.ctx, stream, group)}
+}
+
+func (rp *RedisPipeLine) XPendingExt(a *redis.XPendingExtArgs) *PipeLineXPendingExt {
+	if a.Group != "" {
+		a.Group = rp.r.addNamespacePrefix(a.Group)
+	}
+	if a.Stream != "" {
+		a.Stream = rp.r.addStreamPrefix(a.Stream)
+	}
+	rp.commands++
+	if rp.r.engine.hasRedisLogger {
+		rp.log = append(rp.log, "XPENDINGEXT", a.Stream, a.Group, a.Consumer, "START", a.Start, "END", a.End,
+			"COUNT", strconv.FormatInt(a.Count, 10), "IDLE", a.Idle.String())
+	}
+	return &PipeLineXPendingExt{p: rp, cmd: rp.pipeLine.XPendingExt(rp.ctx, a)}
+}
+
+func (rp *RedisPipeLine) XClaim(a *redis.XClaimArgs) *PipeLineXMessages {
+	a.Stream = rp.r.addStreamPrefix(a.Stream)
+	a.Group = rp.r.addNamespacePrefix(a.Group)
+	rp.commands++
+	if rp.r.engine.hasRedisLogger {
+		rp.log = append(rp.log, "XCLAIM", a.Stream, a.Group, a.Consumer, "MINIDLE", a.MinIdle.String(), "MESSAGES")
+		rp.log = append(rp.log, a.Messages...)
+	}
+	return &PipeLineXMessages{p: rp, cmd: rp.pipeLine.XClaim(rp.ctx, a)}
+}
+
+func (rp *RedisPipeLine) XClaimJustID(a *redis.XClaimArgs) *PipeLineStrings {
+	a.Stream = rp.r.addStreamPrefix(a.Stream)
+	a.Group = rp.r.addNamespacePrefix(a.Group)
+	rp.commands++
+	if rp.r.engine.hasRedisLogger {
+		rp.log = append(rp.log, "XCLAIMJUSTID", a.Stream, a.Group, a.Consumer, "MINIDLE", a.MinIdle.String(), "MESSAGES")
+		rp.log = append(rp.log, a.Messages...)
+	}
+	return &PipeLineStrings{p: rp, cmd: rp.pipeLine.XClaimJustID(rp.ctx, a)}
+}
+
+func (rp *RedisPipeLine) ignore(cmd redis.Cmder, errorPrefix string) {
+	if rp.ignored == nil {
+		rp.ignored = make(map[redis.Cmder]string)
+	}
+	rp.ignored[cmd] = errorPrefix
+}
+
+func (rp *RedisPipeLine) Eval(script string, keys []string, args ...interface{}) *PipeLineCmd {
+	rp.commands++
+	if rp.r.engine.hasRedisLogger {
+		rp.log = append(rp.log, "EVAL", script)
+		rp.log = append(rp.log, keys...)
+	}
+	return &PipeLineCmd{p: rp, cmd: rp.pipeLine.Eval(rp.ctx, script, keys, args...)}
+}
+
+func (rp *RedisPipeLine) EvalSha(sha1 string, keys []string, args ...interface{}) *PipeLineCmd {
+	rp.commands++
+	if rp.r.engine.hasRedisLogger {
+		rp.log = append(rp.log, "EVALSHA", sha1)
+		rp.log = append(rp.log, keys...)
+	}
+	return &PipeLineCmd{p: rp, cmd: rp.pipeLine.EvalSha(rp.ctx, sha1, keys, args...)}
 }
 
 func (rp *RedisPipeLine) Exec() {
+	checkError(rp.exec())
+}
+
+func (rp *RedisPipeLine) exec() error {
 	start := getNow(rp.r.engine.hasRedisLogger)
-	_, err := rp.pipeLine.Exec(rp.ctx)
-	rp.pipeLine = rp.r.client.Pipeline()
-	if err != nil && err == redis.Nil {
+	cmds, err := rp.pipeLine.Exec(rp.ctx)
+	if rp.tx {
+		rp.pipeLine = rp.r.client.TxPipeline()
+	} else {
+		rp.pipeLine = rp.r.client.Pipeline()
+	}
+	if err != nil {
 		err = nil
+		for _, cmd := range cmds {
+			cmdErr := cmd.Err()
+			if cmdErr == nil || cmdErr == redis.Nil {
+				continue
+			}
+			prefix, ignored := rp.ignored[cmd]
+			if !ignored || !strings.HasPrefix(cmdErr.Error(), prefix) {
+				err = cmdErr
+				break
+			}
+		}
 	}
+	rp.ignored = nil
 	if rp.r.engine.hasRedisLogger {
 		rp.fillLogFields(start, err)
 	}
 	rp.log = nil
 	rp.commands = 0
-	checkError(err)
+	return err
 }
 
 type PipeLineGet struct {
-	p   *RedisPipeLine
-	cmd *redis.StringCmd
+	p    *RedisPipeLine
+	cmd  *redis.StringCmd
+	eval *redis.Cmd
 }
 
 func (c *PipeLineGet) Result() (value string, has bool) {
+	if c.eval != nil {
+		val, err := c.eval.Text()
+		if err == redis.Nil {
+			return val, false
+		}
+		checkError(err)
+		return val, true
+	}
 	val, err := c.cmd.Result()
 	if err == redis.Nil {
 		return val, false
@@ -140,11 +813,21 @@ func (c *PipeLineString) Result() string {
 }
 
 type PipeLineInt struct {
-	p   *RedisPipeLine
-	cmd *redis.IntCmd
+	p    *RedisPipeLine
+	cmd  *redis.IntCmd
+	cmds []*redis.IntCmd
 }
 
 func (c *PipeLineInt) Result() int64 {
+	if c.cmd == nil {
+		total := int64(0)
+		for _, cmd := range c.cmds {
+			val, err := cmd.Result()
+			checkError(err)
+			total += val
+		}
+		return total
+	}
 	val, err := c.cmd.Result()
 	checkError(err)
 	return val
@@ -161,7 +844,208 @@ func (c *PipeLineBool) Result() bool {
 	return val
 }
 
+type PipeLineStatus struct {
+	p   *RedisPipeLine
+	cmd *redis.StatusCmd
+}
+
+func (c *PipeLineStatus) Result() string {
+	val, err := c.cmd.Result()
+	checkError(err)
+	return val
+}
+
+type PipeLineFloat struct {
+	p   *RedisPipeLine
+	cmd *redis.FloatCmd
+}
+
+func (c *PipeLineFloat) Result() float64 {
+	val, err := c.cmd.Result()
+	checkError(err)
+	return val
+}
+
+type PipeLineDuration struct {
+	p   *RedisPipeLine
+	cmd *redis.DurationCmd
+}
+
+func (c *PipeLineDuration) Result() time.Duration {
+	val, err := c.cmd.Result()
+	checkError(err)
+	return val
+}
+
+type PipeLineStrings struct {
+	p   *RedisPipeLine
+	cmd *redis.StringSliceCmd
+}
+
+func (c *PipeLineStrings) Result() []string {
+	val, err := c.cmd.Result()
+	checkError(err)
+	return val
+}
+
+type PipeLineStringMap struct {
+	p   *RedisPipeLine
+	cmd *redis.StringStringMapCmd
+}
+
+func (c *PipeLineStringMap) Result() map[string]string {
+	val, err := c.cmd.Result()
+	checkError(err)
+	return val
+}
+
+type PipeLineZ struct {
+	p   *RedisPipeLine
+	cmd *redis.ZSliceCmd
+}
+
+func (c *PipeLineZ) Result() []redis.Z {
+	val, err := c.cmd.Result()
+	checkError(err)
+	return val
+}
+
+type PipeLineSlice struct {
+	p    *RedisPipeLine
+	cmd  *redis.SliceCmd
+	gets []*redis.StringCmd
+}
+
+func (c *PipeLineSlice) Result() []interface{} {
+	if c.cmd == nil {
+		results := make([]interface{}, len(c.gets))
+		for i, get := range c.gets {
+			val, err := get.Result()
+			if err == redis.Nil {
+				continue
+			}
+			checkError(err)
+			results[i] = val
+		}
+		return results
+	}
+	val, err := c.cmd.Result()
+	checkError(err)
+	return val
+}
+
+type PipeLineHMGet struct {
+	p      *RedisPipeLine
+	fields []string
+	cmd    *redis.SliceCmd
+}
+
+func (c *PipeLineHMGet) Result() map[string]interface{} {
+	val, err := c.cmd.Result()
+	checkError(err)
+	results := make(map[string]interface{}, len(c.fields))
+	for index, v := range val {
+		results[c.fields[index]] = v
+	}
+	return results
+}
+
+type PipeLineCmd struct {
+	p   *RedisPipeLine
+	cmd *redis.Cmd
+}
+
+func (c *PipeLineCmd) Result() interface{} {
+	val, err := c.cmd.Result()
+	if err == redis.Nil {
+		return nil
+	}
+	checkError(err)
+	return val
+}
+
+type PipeLineXMessages struct {
+	p   *RedisPipeLine
+	cmd *redis.XMessageSliceCmd
+}
+
+func (c *PipeLineXMessages) Result() []redis.XMessage {
+	val, err := c.cmd.Result()
+	checkError(err)
+	return val
+}
+
+type PipeLineXInfoStream struct {
+	p   *RedisPipeLine
+	cmd *redis.XInfoStreamCmd
+}
+
+func (c *PipeLineXInfoStream) Result() *redis.XInfoStream {
+	val, err := c.cmd.Result()
+	checkError(err)
+	return val
+}
+
+type PipeLineXInfoGroups struct {
+	p   *RedisPipeLine
+	cmd *redis.XInfoGroupsCmd
+}
+
+func (c *PipeLineXInfoGroups) Result() []redis.XInfoGroup {
+	val, err := c.cmd.Result()
+	if err != nil && err.Error() == "ERR no such key" {
+		return make([]redis.XInfoGroup, 0)
+	}
+	checkError(err)
+	if c.p.r.config.HasNamespace() {
+		for i := range val {
+			val[i].Name = c.p.r.removeNamespacePrefix(val[i].Name)
+		}
+	}
+	return val
+}
+
+type PipeLineXGroupCreate struct {
+	p   *RedisPipeLine
+	cmd *redis.StatusCmd
+}
+
+func (c *PipeLineXGroupCreate) Result() (key string, exists bool) {
+	val, err := c.cmd.Result()
+	if err != nil && strings.HasPrefix(err.Error(), "BUSYGROUP") {
+		return "OK", true
+	}
+	checkError(err)
+	return val, false
+}
+
+type PipeLineXPending struct {
+	p   *RedisPipeLine
+	cmd *redis.XPendingCmd
+}
+
+func (c *PipeLineXPending) Result() *redis.XPending {
+	val, err := c.cmd.Result()
+	checkError(err)
+	return val
+}
+
+type PipeLineXPendingExt struct {
+	p   *RedisPipeLine
+	cmd *redis.XPendingExtCmd
+}
+
+func (c *PipeLineXPendingExt) Result() []redis.XPendingExt {
+	val, err := c.cmd.Result()
+	checkError(err)
+	return val
+}
+
 func (rp *RedisPipeLine) fillLogFields(start *time.Time, err error) {
 	query := strings.Join(rp.log, " ")
-	fillLogFields(rp.r.engine.queryLoggersRedis, rp.pool, sourceRedis, "PIPELINE EXEC", query, start, false, err)
+	operation := "PIPELINE EXEC"
+	if rp.tx {
+		operation = "MULTI EXEC"
+	}
+	fillLogFields(rp.r.engine.queryLoggersRedis, rp.pool, sourceRedis, operation, query, start, false, err)
 }
@@ -1,6 +1,7 @@
 package beeorm
 
 import (
+	"context"
 	"testing"
 	"time"
 
@@ -99,4 +100,182 @@ func TestRedisPipeline(t *testing.T) {
 	assert.True(t, hasVal)
 	assert.Equal(t, "2", returnedVal)
 	assert.Equal(t, int64(2), intRes.Result())
+
+	r.FlushDB()
+	pipeLine = r.PipeLine()
+	setNX := pipeLine.SetNX("nx", "a", time.Minute)
+	pipeLine.MSet("m1", "a", "m2", "b")
+	mGet := pipeLine.MGet("m1", "m2", "m3")
+	exists := pipeLine.Exists("m1", "m2", "m3")
+	keyType := pipeLine.Type("m1")
+	ttl := pipeLine.TTL("nx")
+	persist := pipeLine.Persist("nx")
+	incr := pipeLine.Incr("counter")
+	incrBy := pipeLine.IncrBy("counter", 5)
+	pipeLine.Exec()
+	assert.True(t, setNX.Result())
+	assert.Equal(t, []interface{}{"a", "b", nil}, mGet.Result())
+	assert.Equal(t, int64(2), exists.Result())
+	assert.Equal(t, "string", keyType.Result())
+	assert.Equal(t, time.Minute, ttl.Result())
+	assert.True(t, persist.Result())
+	assert.Equal(t, int64(1), incr.Result())
+	assert.Equal(t, int64(6), incrBy.Result())
+
+	pipeLine = r.PipeLine()
+	pipeLine.LPush("list", "b", "a")
+	pipeLine.RPush("list", "c", "d")
+	pipeLine.LSet("list", 3, "e")
+	pipeLine.LRem("list", 1, "c")
+	pipeLine.Ltrim("list", 0, 2)
+	lLen := pipeLine.LLen("list")
+	lRange := pipeLine.LRange("list", 0, 10)
+	rPop := pipeLine.RPop("list")
+	rPopMissing := pipeLine.RPop("list_missing")
+	pipeLine.Exec()
+	assert.Equal(t, int64(3), lLen.Result())
+	assert.Equal(t, []string{"a", "b", "e"}, lRange.Result())
+	val, has = rPop.Result()
+	assert.True(t, has)
+	assert.Equal(t, "e", val)
+	_, has = rPopMissing.Result()
+	assert.False(t, has)
+
+	pipeLine = r.PipeLine()
+	hSetNx := pipeLine.HSetNx("hash", "a", "1")
+	pipeLine.HSet("hash", "b", "2")
+	hMGet := pipeLine.HMGet("hash", "a", "c")
+	hGetAll := pipeLine.HGetAll("hash")
+	hGet := pipeLine.HGet("hash", "b")
+	hLen := pipeLine.HLen("hash")
+	pipeLine.Exec()
+	assert.True(t, hSetNx.Result())
+	assert.Equal(t, map[string]interface{}{"a": "1", "c": nil}, hMGet.Result())
+	assert.Equal(t, map[string]string{"a": "1", "b": "2"}, hGetAll.Result())
+	val, has = hGet.Result()
+	assert.True(t, has)
+	assert.Equal(t, "2", val)
+	assert.Equal(t, int64(2), hLen.Result())
+
+	pipeLine = r.PipeLine()
+	zAdd := pipeLine.ZAdd("z", &redis.Z{Member: "a", Score: 10}, &redis.Z{Member: "b", Score: 20}, &redis.Z{Member: "c", Score: 30})
+	zRem := pipeLine.ZRem("z", "c")
+	zIncr := pipeLine.ZIncrBy("z", 1, "a")
+	zScore := pipeLine.ZScore("z", "b")
+	zCard := pipeLine.ZCard("z")
+	zCount := pipeLine.ZCount("z", "15", "+inf")
+	zRevRange := pipeLine.ZRevRange("z", 0, -1)
+	zRangeByScore := pipeLine.ZRangeByScore("z", &redis.ZRangeBy{Min: "-inf", Max: "15"})
+	zRange := pipeLine.ZRangeWithScores("z", 0, -1)
+	zRevRangeScores := pipeLine.ZRevRangeWithScores("z", 0, 0)
+	zRangeByScoreWithScores := pipeLine.ZRangeByScoreWithScores("z", &redis.ZRangeBy{Min: "15", Max: "+inf"})
+	zPop := pipeLine.ZPopMin("z", 1)
+	pipeLine.Exec()
+	assert.Equal(t, int64(3), zAdd.Result())
+	assert.Equal(t, int64(1), zRem.Result())
+	assert.Equal(t, float64(11), zIncr.Result())
+	assert.Equal(t, float64(20), zScore.Result())
+	assert.Equal(t, int64(2), zCard.Result())
+	assert.Equal(t, int64(1), zCount.Result())
+	assert.Equal(t, []string{"b", "a"}, zRevRange.Result())
+	assert.Equal(t, []string{"a"}, zRangeByScore.Result())
+	assert.Len(t, zRange.Result(), 2)
+	assert.Equal(t, "b", zRevRangeScores.Result()[0].Member)
+	assert.Equal(t, "b", zRangeByScoreWithScores.Result()[0].Member)
+	assert.Equal(t, "a", zPop.Result()[0].Member)
+
+	pipeLine = r.PipeLine()
+	sAdd := pipeLine.SAdd("s", "a", "b", "c")
+	sRem := pipeLine.SRem("s", "c")
+	sCard := pipeLine.SCard("s")
+	sIsMember := pipeLine.SIsMember("s", "a")
+	sMembers := pipeLine.SMembers("s")
+	pipeLine.Exec()
+	assert.Equal(t, int64(3), sAdd.Result())
+	assert.Equal(t, int64(1), sRem.Result())
+	assert.Equal(t, int64(2), sCard.Result())
+	assert.True(t, sIsMember.Result())
+	assert.ElementsMatch(t, []string{"a", "b"}, sMembers.Result())
+	pipeLine = r.PipeLine()
+	sPop := pipeLine.SPop("s")
+	sPopN := pipeLine.SPopN("s", 10)
+	pipeLine.Exec()
+	_, has = sPop.Result()
+	assert.True(t, has)
+	assert.Len(t, sPopN.Result(), 1)
+
+	pipeLine = r.PipeLine()
+	xAdd := pipeLine.XAdd("test-stream", []string{"key", "a"})
+	pipeLine.XAdd("test-stream", []string{"key", "b"})
+	xLen := pipeLine.XLen("test-stream")
+	pipeLine.Exec()
+	assert.Equal(t, int64(2), xLen.Result())
+	pipeLine = r.PipeLine()
+	xDel := pipeLine.XDel("test-stream", xAdd.Result())
+	xTrim := pipeLine.XTrim("test-stream", 0)
+	pipeLine.Exec()
+	assert.Equal(t, int64(1), xDel.Result())
+	assert.Equal(t, int64(1), xTrim.Result())
+
+	pipeLine = r.PipeLine()
+	eval := pipeLine.Eval("return ARGV[1]", nil, "ok")
+	pipeLine.Exec()
+	assert.Equal(t, "ok", eval.Result())
+
+	r.Set("get-del", "value", 10)
+	pipeLine = r.PipeLine()
+	getDel := pipeLine.GetDel("get-del")
+	getDelMissing := pipeLine.GetDel("get-del-missing")
+	incrWithExpire := pipeLine.IncrWithExpire("incr", time.Minute)
+	xAdd = pipeLine.XAdd("test-stream", []string{"key", "c"})
+	pipeLine.XAdd("test-stream", []string{"key", "d"})
+	xRange := pipeLine.XRange("test-stream", "-", "+", 10)
+	xRevRange := pipeLine.XRevRange("test-stream", "+", "-", 1)
+	xInfoStream := pipeLine.XInfoStream("test-stream")
+	groupCreate := pipeLine.XGroupCreate("test-stream", "test-group-2", "0")
+	groupExists := pipeLine.XGroupCreate("test-stream", "test-group-2", "0")
+	groupCreateMkStream := pipeLine.XGroupCreateMkStream("test-stream-2", "test-group-2", "0")
+	xInfoGroups := pipeLine.XInfoGroups("test-stream")
+	xInfoGroupsMissing := pipeLine.XInfoGroups("test-stream-missing")
+	pipeLine.Exec()
+	val, has = getDel.Result()
+	assert.True(t, has)
+	assert.Equal(t, "value", val)
+	_, has = getDelMissing.Result()
+	assert.False(t, has)
+	_, has = r.Get("get-del")
+	assert.False(t, has)
+	assert.Equal(t, int64(1), incrWithExpire.Result())
+	assert.True(t, r.TTL("incr") > 0)
+	assert.Len(t, xRange.Result(), 2)
+	assert.Equal(t, "d", xRevRange.Result()[0].Values["key"])
+	assert.Equal(t, int64(2), xInfoStream.Result().Length)
+	_, groupFound := groupCreate.Result()
+	assert.False(t, groupFound)
+	_, groupFound = groupExists.Result()
+	assert.True(t, groupFound)
+	_, groupFound = groupCreateMkStream.Result()
+	assert.False(t, groupFound)
+	assert.Len(t, xInfoGroups.Result(), 1)
+	assert.Equal(t, "test-group-2", xInfoGroups.Result()[0].Name)
+	assert.Len(t, xInfoGroupsMissing.Result(), 0)
+
+	r.XReadGroup(context.Background(), &redis.XReadGroupArgs{Group: "test-group-2", Consumer: "c1", Streams: []string{"test-stream", ">"},
+		Count: 10, Block: time.Millisecond})
+	pipeLine = r.PipeLine()
+	xPending := pipeLine.XPending("test-stream", "test-group-2")
+	xPendingExt := pipeLine.XPendingExt(&redis.XPendingExtArgs{Stream: "test-stream", Group: "test-group-2", Start: "-", End: "+", Count: 10})
+	xClaimJustID := pipeLine.XClaimJustID(&redis.XClaimArgs{Stream: "test-stream", Group: "test-group-2", Consumer: "c2",
+		Messages: []string{xAdd.Result()}})
+	xClaim := pipeLine.XClaim(&redis.XClaimArgs{Stream: "test-stream", Group: "test-group-2", Consumer: "c3",
+		Messages: []string{xAdd.Result()}})
+	xGroupDelConsumer := pipeLine.XGroupDelConsumer("test-stream", "test-group-2", "c1")
+	xGroupDestroy := pipeLine.XGroupDestroy("test-stream", "test-group-2")
+	pipeLine.Exec()
+	assert.Equal(t, int64(2), xPending.Result().Count)
+	assert.Len(t, xPendingExt.Result(), 2)
+	assert.Equal(t, []string{xAdd.Result()}, xClaimJustID.Result())
+	assert.Len(t, xClaim.Result(), 1)
+	assert.Equal(t, int64(1), xGroupDelConsumer.Result())
+	assert.Equal(t, int64(1), xGroupDestroy.Result())
 }
@@ -0,0 +1,110 @@
+package beeorm
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"time"
+
+	"github.com/go-redis/redis/v8"
+	jsoniter "github.com/json-iterator/go"
+	"github.com/shamaton/msgpack"
+)
+
+const pubSubReconnectDelay = time.Second
+
+type PubSubMessage struct {
+	Channel string
+	Pattern string
+	Payload string
+}
+
+// Unserialize decodes msgpack payload published by RedisCache.Publish, JSON is used as a fallback
+func (m *PubSubMessage) Unserialize(value interface{}) {
+	err := msgpack.Unmarshal([]byte(m.Payload), &value)
+	if err != nil {
+		err = jsoniter.ConfigFastest.UnmarshalFromString(m.Payload, value)
+	}
+	checkError(err)
+}
+
+// Publish sends string and []byte messages as they are, other values are serialized with msgpack
+func (r *RedisCache) Publish(channel string, message interface{}) (receivers int64) {
+	channel = r.addNamespacePrefix(channel)
+	switch message.(type) {
+	case string, []byte:
+	default:
+		body, err := msgpack.Marshal(message)
+		checkError(err)
+		message = body
+	}
+	start := getNow(r.engine.hasRedisLogger)
+	receivers, err := r.client.Publish(context.Background(), channel, message).Result()
+	if r.engine.hasRedisLogger {
+		r.fillLogFields("PUBLISH", "PUBLISH "+channel, start, false, err)
+	}
+	checkError(err)
+	return receivers
+}
+
+// Subscribe blocks until ctx is cancelled
+func (r *RedisCache) Subscribe(ctx context.Context, handler func(message *PubSubMessage), channels ...string) {
+	r.subscribe(ctx, "SUBSCRIBE", handler, channels)
+}
+
+// PSubscribe blocks until ctx is cancelled
+func (r *RedisCache) PSubscribe(ctx context.Context, handler func(message *PubSubMessage), patterns ...string) {
+	r.subscribe(ctx, "PSUBSCRIBE", handler, patterns)
+}
+
+func (r *RedisCache) subscribe(ctx context.Context, operation string, handler func(message *PubSubMessage), channels []string) {
+	client, isClient := r.client.(redis.UniversalClient)
+	if !isClient {
+		panic(errors.New("subscribe is not supported in redis transaction"))
+	}
+	prefixed := make([]string, len(channels))
+	for i, channel := range channels {
+		prefixed[i] = r.addNamespacePrefix(channel)
+	}
+	start := getNow(r.engine.hasRedisLogger)
+	var pubSub *redis.PubSub
+	if operation == "PSUBSCRIBE" {
+		pubSub = client.PSubscribe(ctx, prefixed...)
+	} else {
+		pubSub = client.Subscribe(ctx, prefixed...)
+	}
+	defer func() {
+		_ = pubSub.Close()
+	}()
+	_, err := pubSub.Receive(ctx)
+	if r.engine.hasRedisLogger {
+		r.fillLogFields(operation, operation+" "+strings.Join(prefixed, " "), start, false, err)
+	}
+	if ctx.Err() != nil {
+		return
+	}
+	checkError(err)
+	for {
+		// connection is restored by go-redis with all subscriptions on next receive
+		message, err := pubSub.ReceiveMessage(ctx)
+		if err != nil {
+			if ctx.Err() != nil {
+				return
+			}
+			if r.engine.hasRedisLogger {
+				r.fillLogFields(operation, operation+" RECONNECT "+strings.Join(prefixed, " "), nil, false, err)
+			}
+			select {
+			case <-ctx.Done():
+				return
+			case <-time.After(pubSubReconnectDelay):
+			}
+			continue
+		}
+		received := &PubSubMessage{Channel: r.removeNamespacePrefix(message.Channel), Payload: message.Payload}
+		if message.Pattern != "" {
+			received.Pattern = r.removeNamespacePrefix(message.Pattern)
+		}
+		handler(received)
+	}
+}
@@ -0,0 +1,97 @@
+package beeorm
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type pubSubTestMessage struct {
+	Name string
+	Age  int
+}
+
+func TestRedisPubSub(t *testing.T) {
+	testRedisPubSub(t, "")
+}
+
+func TestRedisPubSubNamespace(t *testing.T) {
+	testRedisPubSub(t, "test")
+}
+
+func testRedisPubSub(t *testing.T, namespace string) {
+	registry := &Registry{}
+	registry.RegisterRedis("localhost:6382", namespace, 15)
+	validatedRegistry, def, err := registry.Validate()
+	assert.NoError(t, err)
+	defer def()
+	engine := validatedRegistry.CreateEngine()
+	r := engine.GetRedis()
+	testLogger := &testLogHandler{}
+	engine.RegisterQueryLogger(testLogger, false, true, false)
+
+	assert.Equal(t, int64(0), r.Publish("test-channel", "nobody"))
+	assert.Len(t, testLogger.Logs, 1)
+	assert.Equal(t, "PUBLISH", testLogger.Logs[0]["operation"])
+
+	subscriber := validatedRegistry.CreateEngine().GetRedis()
+	m := sync.Mutex{}
+	received := make([]*PubSubMessage, 0)
+	receivedPattern := make([]*PubSubMessage, 0)
+	ctx, cancel := context.WithCancel(context.Background())
+	wg := sync.WaitGroup{}
+	wg.Add(2)
+	go func() {
+		defer wg.Done()
+		subscriber.Subscribe(ctx, func(message *PubSubMessage) {
+			m.Lock()
+			defer m.Unlock()
+			received = append(received, message)
+		}, "test-channel")
+	}()
+	go func() {
+		defer wg.Done()
+		subscriber.PSubscribe(ctx, func(message *PubSubMessage) {
+			m.Lock()
+			defer m.Unlock()
+			receivedPattern = append(receivedPattern, message)
+		}, "test-pattern-*")
+	}()
+	for i := 0; i < 100; i++ {
+		if r.Publish("test-pattern-ping", "ping") > 0 && r.Publish("test-channel", "ping") > 0 {
+			break
+		}
+		time.Sleep(time.Millisecond * 10)
+	}
+	time.Sleep(time.Millisecond * 50)
+	m.Lock()
+	received = received[0:0]
+	receivedPattern = receivedPattern[0:0]
+	m.Unlock()
+
+	assert.Equal(t, int64(1), r.Publish("test-channel", pubSubTestMessage{Name: "Tom", Age: 18}))
+	assert.Equal(t, int64(1), r.Publish("test-channel", `{"Name":"John","Age":20}`))
+	assert.Equal(t, int64(1), r.Publish("test-pattern-a", []byte("raw")))
+	time.Sleep(time.Millisecond * 100)
+	cancel()
+	wg.Wait()
+
+	assert.Len(t, received, 2)
+	assert.Len(t, receivedPattern, 1)
+	assert.Equal(t, "test-channel", received[0].Channel)
+	assert.Equal(t, "", received[0].Pattern)
+	value := &pubSubTestMessage{}
+	received[0].Unserialize(value)
+	assert.Equal(t, "Tom", value.Name)
+	assert.Equal(t, 18, value.Age)
+	value = &pubSubTestMessage{}
+	received[1].Unserialize(value)
+	assert.Equal(t, "John", value.Name)
+	assert.Equal(t, 20, value.Age)
+	assert.Equal(t, "test-pattern-a", receivedPattern[0].Channel)
+	assert.Equal(t, "test-pattern-*", receivedPattern[0].Pattern)
+	assert.Equal(t, "raw", receivedPattern[0].Payload)
+}
@@ -0,0 +1,266 @@
+package beeorm
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"math"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/go-redis/redis/v8"
+	"github.com/go-redis/redis_rate/v9"
+)
+
+type RateLimitAlgorithm int
+
+const (
+	RateLimitGCRA RateLimitAlgorithm = iota
+	RateLimitSlidingWindowLog
+	RateLimitTokenBucket
+)
+
+func (a RateLimitAlgorithm) String() string {
+	switch a {
+	case RateLimitSlidingWindowLog:
+		return "SLIDING_WINDOW_LOG"
+	case RateLimitTokenBucket:
+		return "TOKEN_BUCKET"
+	default:
+		return "GCRA"
+	}
+}
+
+const localRateLimiterMaxKeys = 10000
+
+var rateLimitSlidingWindowScript = newRedisScript("orm-rate-limit-sliding-window", lockScriptNow+`
+local limit = tonumber(ARGV[1])
+local window = tonumber(ARGV[2])
+local n = tonumber(ARGV[3])
+local member = t[1] .. string.format('%06d', tonumber(t[2])) .. '-' .. ARGV[4]
+redis.call('ZREMRANGEBYSCORE', KEYS[1], '-inf', now - window)
+local count = redis.call('ZCARD', KEYS[1])
+local allowed = 0
+local retry = 0
+if count + n <= limit then
+	for i = 1, n do
+		redis.call('ZADD', KEYS[1], now, member .. '-' .. i)
+	end
+	count = count + n
+	allowed = 1
+elseif n > limit then
+	retry = -1
+else
+	local index = count + n - limit - 1
+	local oldest = redis.call('ZRANGE', KEYS[1], index, index, 'WITHSCORES')
+	retry = tonumber(oldest[2]) + window - now
+end
+local reset = 0
+if count > 0 then
+	local newest = redis.call('ZRANGE', KEYS[1], -1, -1, 'WITHSCORES')
+	reset = tonumber(newest[2]) + window - now
+	redis.call('PEXPIRE', KEYS[1], window)
+end
+return {allowed, limit - count, retry, reset}
+`)
+
+var rateLimitTokenBucketScript = newRedisScript("orm-rate-limit-token-bucket", lockScriptNow+`
+local capacity = tonumber(ARGV[1])
+local rate = tonumber(ARGV[2])
+local n = tonumber(ARGV[3])
+local tokens = capacity
+local state = redis.call('HMGET', KEYS[1], 'tokens', 'ts')
+if state[1] then
+	tokens = math.min(capacity, tonumber(state[1]) + math.max(0, now - tonumber(state[2])) * rate)
+end
+local allowed = 0
+local retry = 0
+if tokens >= n then
+	tokens = tokens - n
+	allowed = 1
+elseif n > capacity then
+	retry = -1
+else
+	retry = math.ceil((n - tokens) / rate)
+end
+local reset = math.ceil((capacity - tokens) / rate)
+if reset > 0 then
+	redis.call('HMSET', KEYS[1], 'tokens', tokens, 'ts', now)
+	redis.call('PEXPIRE', KEYS[1], reset)
+else
+	redis.call('DEL', KEYS[1])
+end
+return {allowed, math.floor(tokens), retry, reset}
+`)
+
+type RateLimitOptions struct {
+	Algorithm RateLimitAlgorithm
+	// Burst is equal to limit by default, sliding window log ignores it
+	Burst int
+	// LocalFallback approximates limit in memory of current process when redis is not reachable
+	LocalFallback bool
+}
+
+type RateLimitResult struct {
+	Allowed   bool
+	Limit     int
+	Remaining int
+	// RetryAfter is zero when request is allowed and -1 when it can never be allowed
+	RetryAfter time.Duration
+	ResetAfter time.Duration
+	Fallback   bool
+}
+
+// Headers returns X-RateLimit-* headers, Retry-After is added when request is not allowed
+func (r *RateLimitResult) Headers() map[string]string {
+	headers := map[string]string{
+		"X-RateLimit-Limit":     strconv.Itoa(r.Limit),
+		"X-RateLimit-Remaining": strconv.Itoa(r.Remaining),
+		"X-RateLimit-Reset":     strconv.FormatInt(int64(math.Ceil(r.ResetAfter.Seconds())), 10),
+	}
+	if !r.Allowed && r.RetryAfter > 0 {
+		headers["Retry-After"] = strconv.FormatInt(int64(math.Ceil(r.RetryAfter.Seconds())), 10)
+	}
+	return headers
+}
+
+func (r *RedisCache) RateLimit(key string, period time.Duration, limit int) bool {
+	return r.RateLimitN(key, period, limit, 1, nil).Allowed
+}
+
+// RateLimitN consumes n units at once
+func (r *RedisCache) RateLimitN(key string, period time.Duration, limit, n int, options *RateLimitOptions) *RateLimitResult {
+	if options == nil {
+		options = &RateLimitOptions{}
+	}
+	burst := options.Burst
+	if burst <= 0 {
+		burst = limit
+	}
+	start := getNow(r.engine.hasRedisLogger)
+	var res *RateLimitResult
+	var err error
+	switch options.Algorithm {
+	case RateLimitSlidingWindowLog:
+		key = r.addNamespacePrefix("rate_window:" + key)
+		// random part keeps members unique when many requests are handled in the same microsecond
+		token := make([]byte, 8)
+		_, err = rand.Read(token)
+		checkError(err)
+		res, err = r.rateLimitScript(rateLimitSlidingWindowScript, key, limit, period.Milliseconds(), n, hex.EncodeToString(token))
+	case RateLimitTokenBucket:
+		key = r.addNamespacePrefix("rate_bucket:" + key)
+		res, err = r.rateLimitScript(rateLimitTokenBucketScript, key, burst, float64(limit)/float64(period.Milliseconds()), n)
+	default:
+		key = r.addNamespacePrefix(key)
+		res, err = r.rateLimitGCRA(key, period, limit, burst, n)
+	}
+	if r.engine.hasRedisLogger {
+		message := fmt.Sprintf("RATE %s %s %s LIMIT %d BURST %d N %d", options.Algorithm, key, period.String(), limit, burst, n)
+		r.fillLogFields("RATE", message, start, false, err)
+	}
+	if err != nil && options.LocalFallback {
+		// only connection problems are handled, errors returned by redis server are not
+		_, isServerError := err.(redis.Error)
+		if !isServerError {
+			res = r.config.getRateFallback().allow(key, period, limit, burst, n)
+			err = nil
+		}
+	}
+	checkError(err)
+	res.Limit = limit
+	return res
+}
+
+func (r *RedisCache) rateLimitGCRA(key string, period time.Duration, limit, burst, n int) (*RateLimitResult, error) {
+	if r.limiter == nil {
+		r.limiter = redis_rate.NewLimiter(r.client)
+	}
+	res, err := r.limiter.AllowN(r.client.Context(), key, redis_rate.Limit{
+		Rate:   limit,
+		Period: period,
+		Burst:  burst,
+	}, n)
+	if err != nil {
+		return nil, err
+	}
+	result := &RateLimitResult{Allowed: res.Allowed > 0, Remaining: res.Remaining, ResetAfter: res.ResetAfter}
+	if !result.Allowed {
+		result.RetryAfter = res.RetryAfter
+	}
+	return result, nil
+}
+
+func (r *RedisCache) rateLimitScript(script *redisScript, key string, args ...interface{}) (*RateLimitResult, error) {
+	res, err := r.evalScript(script, []string{key}, args...)
+	if err != nil {
+		return nil, err
+	}
+	values := res.([]interface{})
+	retry := values[2].(int64)
+	result := &RateLimitResult{Allowed: values[0].(int64) == 1, Remaining: int(values[1].(int64)),
+		RetryAfter: time.Duration(retry) * time.Millisecond, ResetAfter: time.Duration(values[3].(int64)) * time.Millisecond}
+	if retry < 0 {
+		result.RetryAfter = -1
+	}
+	return result, nil
+}
+
+type localRateLimiter struct {
+	m       sync.Mutex
+	buckets map[string]*localRateBucket
+}
+
+type localRateBucket struct {
+	tokens   float64
+	capacity float64
+	rate     float64
+	updated  time.Time
+}
+
+func (b *localRateBucket) refill(now time.Time) {
+	b.tokens = math.Min(b.capacity, b.tokens+now.Sub(b.updated).Seconds()*b.rate)
+	b.updated = now
+}
+
+// allow uses token bucket for all algorithms, limits are counted separately in every process
+func (l *localRateLimiter) allow(key string, period time.Duration, limit, burst, n int) *RateLimitResult {
+	l.m.Lock()
+	defer l.m.Unlock()
+	now := time.Now()
+	if l.buckets == nil {
+		l.buckets = make(map[string]*localRateBucket)
+	}
+	bucket, has := l.buckets[key]
+	if !has {
+		if len(l.buckets) >= localRateLimiterMaxKeys {
+			l.removeFullBuckets(now)
+		}
+		bucket = &localRateBucket{tokens: float64(burst), capacity: float64(burst), updated: now}
+		l.buckets[key] = bucket
+	}
+	bucket.rate = float64(limit) / period.Seconds()
+	bucket.refill(now)
+	result := &RateLimitResult{Fallback: true}
+	if bucket.tokens >= float64(n) {
+		bucket.tokens -= float64(n)
+		result.Allowed = true
+	} else if float64(n) > bucket.capacity {
+		result.RetryAfter = -1
+	} else {
+		result.RetryAfter = time.Duration((float64(n) - bucket.tokens) / bucket.rate * float64(time.Second))
+	}
+	result.Remaining = int(bucket.tokens)
+	result.ResetAfter = time.Duration((bucket.capacity - bucket.tokens) / bucket.rate * float64(time.Second))
+	return result
+}
+
+func (l *localRateLimiter) removeFullBuckets(now time.Time) {
+	for key, bucket := range l.buckets {
+		bucket.refill(now)
+		if bucket.tokens >= bucket.capacity {
+			delete(l.buckets, key)
+		}
+	}
+}
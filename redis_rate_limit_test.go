@@ -0,0 +1,129 @@
+package beeorm
+
+import (
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRateLimit(t *testing.T) {
+	testRateLimit(t, "")
+}
+
+func TestRateLimitNamespace(t *testing.T) {
+	testRateLimit(t, "test")
+}
+
+func testRateLimit(t *testing.T, namespace string) {
+	registry := &Registry{}
+	registry.RegisterRedis("localhost:6382", namespace, 15)
+	validatedRegistry, def, err := registry.Validate()
+	assert.Nil(t, err)
+	defer def()
+	engine := validatedRegistry.CreateEngine()
+	r := engine.GetRedis()
+	r.FlushDB()
+
+	for _, algorithm := range []RateLimitAlgorithm{RateLimitGCRA, RateLimitSlidingWindowLog, RateLimitTokenBucket} {
+		options := &RateLimitOptions{Algorithm: algorithm}
+		key := "test_" + algorithm.String()
+		res := r.RateLimitN(key, time.Second, 5, 3, options)
+		assert.True(t, res.Allowed, algorithm.String())
+		assert.Equal(t, 5, res.Limit)
+		assert.Equal(t, 2, res.Remaining, algorithm.String())
+		assert.Equal(t, time.Duration(0), res.RetryAfter)
+		assert.Greater(t, res.ResetAfter, time.Duration(0))
+		assert.LessOrEqual(t, res.ResetAfter, time.Second)
+		assert.False(t, res.Fallback)
+
+		res = r.RateLimitN(key, time.Second, 5, 3, options)
+		assert.False(t, res.Allowed, algorithm.String())
+		assert.Equal(t, 2, res.Remaining, algorithm.String())
+		assert.Greater(t, res.RetryAfter, time.Duration(0), algorithm.String())
+		assert.LessOrEqual(t, res.RetryAfter, time.Second)
+		assert.Equal(t, "1", res.Headers()["Retry-After"])
+
+		assert.True(t, r.RateLimitN(key, time.Second, 5, 2, options).Allowed)
+		assert.False(t, r.RateLimitN(key, time.Second, 5, 1, options).Allowed)
+		time.Sleep(time.Second)
+		assert.True(t, r.RateLimitN(key, time.Second, 5, 5, options).Allowed, algorithm.String())
+	}
+
+	options := &RateLimitOptions{Algorithm: RateLimitTokenBucket, Burst: 2}
+	assert.True(t, r.RateLimitN("test_burst", time.Second, 10, 2, options).Allowed)
+	res := r.RateLimitN("test_burst", time.Second, 10, 3, options)
+	assert.False(t, res.Allowed)
+	assert.Equal(t, time.Duration(-1), res.RetryAfter)
+	time.Sleep(time.Millisecond * 150)
+	assert.True(t, r.RateLimitN("test_burst", time.Second, 10, 1, options).Allowed)
+
+	res = r.RateLimitN("test_window", time.Second, 2, 3, &RateLimitOptions{Algorithm: RateLimitSlidingWindowLog})
+	assert.False(t, res.Allowed)
+	assert.Equal(t, time.Duration(-1), res.RetryAfter)
+}
+
+func TestRateLimitSlidingWindowConcurrent(t *testing.T) {
+	registry := &Registry{}
+	registry.RegisterRedis("localhost:6382", "", 15)
+	validatedRegistry, def, err := registry.Validate()
+	assert.Nil(t, err)
+	defer def()
+	engine := validatedRegistry.CreateEngine()
+	r := engine.GetRedis()
+	r.FlushDB()
+
+	options := &RateLimitOptions{Algorithm: RateLimitSlidingWindowLog}
+	var allowed int32
+	wg := &sync.WaitGroup{}
+	start := make(chan struct{})
+	for i := 0; i < 100; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			<-start
+			if validatedRegistry.CreateEngine().GetRedis().RateLimitN("test_concurrent", time.Minute, 50, 2, options).Allowed {
+				atomic.AddInt32(&allowed, 1)
+			}
+		}()
+	}
+	close(start)
+	wg.Wait()
+	assert.Equal(t, int32(25), allowed)
+	assert.Equal(t, int64(50), r.ZCard("rate_window:test_concurrent"))
+}
+
+func TestRateLimitLocalFallback(t *testing.T) {
+	registry := &Registry{}
+	registry.RegisterRedis("localhost:1", "", 0)
+	validatedRegistry, def, err := registry.Validate()
+	assert.Nil(t, err)
+	defer def()
+	engine := validatedRegistry.CreateEngine()
+	r := engine.GetRedis()
+
+	assert.Panics(t, func() {
+		r.RateLimit("test", time.Second, 2)
+	})
+
+	options := &RateLimitOptions{LocalFallback: true}
+	res := r.RateLimitN("test", time.Minute, 2, 1, options)
+	assert.True(t, res.Allowed)
+	assert.True(t, res.Fallback)
+	assert.Equal(t, 1, res.Remaining)
+	res = r.RateLimitN("test", time.Minute, 2, 1, options)
+	assert.True(t, res.Allowed)
+	res = r.RateLimitN("test", time.Minute, 2, 1, options)
+	assert.False(t, res.Allowed)
+	assert.Greater(t, res.RetryAfter, time.Second*29)
+	headers := res.Headers()
+	assert.Equal(t, "2", headers["X-RateLimit-Limit"])
+	assert.Equal(t, "0", headers["X-RateLimit-Remaining"])
+	assert.Equal(t, "60", headers["X-RateLimit-Reset"])
+	assert.Equal(t, "30", headers["Retry-After"])
+
+	assert.True(t, engine.GetRedis().RateLimitN("other", time.Minute, 2, 2, options).Allowed)
+	assert.False(t, validatedRegistry.CreateEngine().GetRedis().RateLimitN("other", time.Minute, 2, 1, options).Allowed)
+}
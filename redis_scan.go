@@ -0,0 +1,125 @@
+package beeorm
+
+import (
+	"context"
+	"fmt"
+	"sync"
+
+	"github.com/go-redis/redis/v8"
+)
+
+type RedisScanIterator struct {
+	r         *RedisCache
+	operation string
+	key       string
+	match     string
+	count     int64
+	step      int
+	strip     bool
+	clients   []redis.Cmdable
+	client    int
+	cursor    uint64
+	page      []string
+	position  int
+	finished  bool
+	scan      func(client redis.Cmdable, cursor uint64) ([]string, uint64, error)
+}
+
+func (r *RedisCache) Scan(match string, count int64) *RedisScanIterator {
+	if match == "" {
+		match = "*"
+	}
+	match = r.addNamespacePrefix(match)
+	iterator := &RedisScanIterator{r: r, operation: "SCAN", match: match, count: count, step: 1, strip: r.config.HasNamespace()}
+	iterator.scan = func(client redis.Cmdable, cursor uint64) ([]string, uint64, error) {
+		return client.Scan(context.Background(), cursor, match, count).Result()
+	}
+	if cluster, isCluster := r.client.(*redis.ClusterClient); isCluster {
+		// every master holds a different part of the keyspace
+		m := sync.Mutex{}
+		err := cluster.ForEachMaster(context.Background(), func(ctx context.Context, client *redis.Client) error {
+			m.Lock()
+			defer m.Unlock()
+			iterator.clients = append(iterator.clients, client)
+			return nil
+		})
+		checkError(err)
+	} else {
+		iterator.clients = []redis.Cmdable{r.client}
+	}
+	return iterator
+}
+
+func (r *RedisCache) HScan(key, match string, count int64) *RedisScanIterator {
+	key = r.addNamespacePrefix(key)
+	iterator := &RedisScanIterator{r: r, operation: "HSCAN", key: key, match: match, count: count, step: 2,
+		clients: []redis.Cmdable{r.client}}
+	iterator.scan = func(client redis.Cmdable, cursor uint64) ([]string, uint64, error) {
+		return client.HScan(context.Background(), key, cursor, match, count).Result()
+	}
+	return iterator
+}
+
+func (r *RedisCache) SScan(key, match string, count int64) *RedisScanIterator {
+	key = r.addNamespacePrefix(key)
+	iterator := &RedisScanIterator{r: r, operation: "SSCAN", key: key, match: match, count: count, step: 1,
+		clients: []redis.Cmdable{r.client}}
+	iterator.scan = func(client redis.Cmdable, cursor uint64) ([]string, uint64, error) {
+		return client.SScan(context.Background(), key, cursor, match, count).Result()
+	}
+	return iterator
+}
+
+func (i *RedisScanIterator) Next() bool {
+	i.position += i.step
+	for i.position >= len(i.page) {
+		if i.finished {
+			return false
+		}
+		i.fetch()
+	}
+	return true
+}
+
+// Val returns key for SCAN, member for SSCAN and field for HSCAN
+func (i *RedisScanIterator) Val() string {
+	if i.strip {
+		return i.r.removeNamespacePrefix(i.page[i.position])
+	}
+	return i.page[i.position]
+}
+
+// HashValue returns value of current field for HSCAN
+func (i *RedisScanIterator) HashValue() string {
+	if i.step < 2 {
+		return ""
+	}
+	return i.page[i.position+1]
+}
+
+func (i *RedisScanIterator) fetch() {
+	start := getNow(i.r.engine.hasRedisLogger)
+	page, cursor, err := i.scan(i.clients[i.client], i.cursor)
+	if i.r.engine.hasRedisLogger {
+		message := i.operation
+		if i.key != "" {
+			message += " " + i.key
+		}
+		message += fmt.Sprintf(" %d", i.cursor)
+		if i.match != "" {
+			message += " MATCH " + i.match
+		}
+		if i.count > 0 {
+			message += fmt.Sprintf(" COUNT %d", i.count)
+		}
+		i.r.fillLogFields(i.operation, message, start, false, err)
+	}
+	checkError(err)
+	i.page = page
+	i.position = 0
+	i.cursor = cursor
+	if cursor == 0 {
+		i.client++
+		i.finished = i.client >= len(i.clients)
+	}
+}
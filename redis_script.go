@@ -0,0 +1,72 @@
+package beeorm
+
+import (
+	"context"
+	"crypto/sha1"
+	"encoding/hex"
+	"fmt"
+	"strings"
+
+	"github.com/go-redis/redis/v8"
+)
+
+type redisScript struct {
+	name   string
+	source string
+	sha1   string
+}
+
+func newRedisScript(name, source string) *redisScript {
+	hash := sha1.Sum([]byte(source))
+	return &redisScript{name: name, source: source, sha1: hex.EncodeToString(hash[:])}
+}
+
+func (r *Registry) RegisterRedisScript(name, source string) {
+	if r.redisScripts == nil {
+		r.redisScripts = make(map[string]*redisScript)
+	}
+	_, has := r.redisScripts[name]
+	if has {
+		panic(fmt.Errorf("redis script with name %s already exists", name))
+	}
+	r.redisScripts[name] = newRedisScript(name, source)
+}
+
+func (r *RedisCache) RunScript(name string, keys []string, args ...interface{}) interface{} {
+	script, has := r.engine.registry.redisScripts[name]
+	if !has {
+		panic(fmt.Errorf("unregistered redis script '%s'", name))
+	}
+	prefixed := make([]string, len(keys))
+	for i, key := range keys {
+		prefixed[i] = r.addNamespacePrefix(key)
+	}
+	return r.runScript(script, prefixed, args...)
+}
+
+func (r *RedisCache) runScript(script *redisScript, keys []string, args ...interface{}) interface{} {
+	start := getNow(r.engine.hasRedisLogger)
+	res, err := r.evalScript(script, keys, args...)
+	if r.engine.hasRedisLogger {
+		message := fmt.Sprintf("SCRIPT "+script.name+" %v %v", keys, args)
+		r.fillLogFields("SCRIPT", message, start, false, err)
+	}
+	checkError(err)
+	return res
+}
+
+func (r *RedisCache) evalScript(script *redisScript, keys []string, args ...interface{}) (interface{}, error) {
+	res, err := r.client.EvalSha(context.Background(), script.sha1, keys, args...).Result()
+	if err != nil && strings.HasPrefix(err.Error(), "NOSCRIPT") {
+		// script cache is empty after redis restart or failover
+		err = r.client.ScriptLoad(context.Background(), script.source).Err()
+		if err == nil {
+			res, err = r.client.EvalSha(context.Background(), script.sha1, keys, args...).Result()
+		}
+	}
+	if err == redis.Nil {
+		// script returned nil
+		return nil, nil
+	}
+	return res, err
+}
@@ -0,0 +1,53 @@
+package beeorm
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRedisScript(t *testing.T) {
+	testRedisScript(t, "")
+}
+
+func TestRedisScriptNamespace(t *testing.T) {
+	testRedisScript(t, "test")
+}
+
+func testRedisScript(t *testing.T, namespace string) {
+	registry := &Registry{}
+	registry.RegisterRedis("localhost:6382", namespace, 15)
+	registry.RegisterRedisScript("incr-by", "return redis.call('INCRBY', KEYS[1], ARGV[1])")
+	registry.RegisterRedisScript("get", "return redis.call('GET', KEYS[1])")
+	assert.PanicsWithError(t, "redis script with name incr-by already exists", func() {
+		registry.RegisterRedisScript("incr-by", "return 1")
+	})
+	validatedRegistry, def, err := registry.Validate()
+	assert.NoError(t, err)
+	defer def()
+	engine := validatedRegistry.CreateEngine()
+	r := engine.GetRedis()
+	r.FlushDB()
+	testLogger := &testLogHandler{}
+	engine.RegisterQueryLogger(testLogger, false, true, false)
+
+	err = r.client.ScriptFlush(context.Background()).Err()
+	assert.NoError(t, err)
+	assert.Equal(t, int64(2), r.RunScript("incr-by", []string{"script_counter"}, 2))
+	assert.Equal(t, int64(5), r.RunScript("incr-by", []string{"script_counter"}, 3))
+	val, has := r.Get("script_counter")
+	assert.True(t, has)
+	assert.Equal(t, "5", val)
+	assert.Len(t, testLogger.Logs, 3)
+	assert.Equal(t, "SCRIPT", testLogger.Logs[0]["operation"])
+	assert.Contains(t, testLogger.Logs[0]["query"], "SCRIPT incr-by [")
+	assert.Contains(t, testLogger.Logs[0]["query"], r.addNamespacePrefix("script_counter"))
+
+	assert.Equal(t, "5", r.RunScript("get", []string{"script_counter"}))
+	assert.Nil(t, r.RunScript("get", []string{"script_missing"}))
+
+	assert.PanicsWithError(t, "unregistered redis script 'missing'", func() {
+		r.RunScript("missing", nil)
+	})
+}
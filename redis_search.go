@@ -217,6 +217,7 @@ type RedisSearchQuery struct {
 	summarizeFrags     int
 	summarizeLen       int
 	withFakeDelete     bool
+	onlyFakeDelete     bool
 	hasFakeDelete      bool
 }
 
@@ -367,6 +368,12 @@ func (q *RedisSearchQuery) WithFakeDeleteRows() *RedisSearchQuery {
 	return q
 }
 
+func (q *RedisSearchQuery) OnlyFakeDeleteRows() *RedisSearchQuery {
+	q.withFakeDelete = true
+	q.onlyFakeDelete = true
+	return q
+}
+
 func (q *RedisSearchQuery) QueryRaw(query string) *RedisSearchQuery {
 	q.query = query
 	return q
@@ -1057,8 +1064,16 @@ func (r *RedisSearch) buildQueryArgs(query *RedisSearchQuery, args []interface{}
 			q += "-@" + field + ":( " + strings.Join(v, " | ") + " )"
 		}
 	}
+	if query.onlyFakeDelete && !query.hasFakeDelete {
+		panic(errors.New("only fake deleted rows can't be searched without searchable FakeDelete field"))
+	}
 	if query.hasFakeDelete && !query.withFakeDelete {
 		q += "-@FakeDelete:{true}"
+	} else if query.hasFakeDelete && query.onlyFakeDelete {
+		if q != "" {
+			q += " "
+		}
+		q += "@FakeDelete:{true}"
 	}
 	if q == "" {
 		q = "*"
@@ -0,0 +1,60 @@
+package beeorm
+
+import (
+	"context"
+	"errors"
+	"strings"
+
+	"github.com/go-redis/redis/v8"
+)
+
+type RedisTx struct {
+	*RedisCache
+	multi *RedisPipeLine
+}
+
+// Multi returns pipeline executed with MULTI/EXEC when Watch callback returns
+func (tx *RedisTx) Multi() *RedisPipeLine {
+	if tx.multi == nil {
+		tx.multi = tx.TxPipeline()
+	}
+	return tx.multi
+}
+
+// Watch returns false when one of watched keys was changed before transaction was executed
+func (r *RedisCache) Watch(fn func(tx *RedisTx), keys ...string) (committed bool) {
+	client, isClient := r.client.(redis.UniversalClient)
+	if !isClient {
+		panic(errors.New("nested redis transactions are not supported"))
+	}
+	watched := make([]string, len(keys))
+	for i, key := range keys {
+		watched[i] = r.addNamespacePrefix(key)
+	}
+	start := getNow(r.engine.hasRedisLogger)
+	err := client.Watch(context.Background(), func(t *redis.Tx) error {
+		tx := &RedisTx{RedisCache: &RedisCache{engine: r.engine, config: r.config, client: t}}
+		fn(tx)
+		if tx.multi == nil || tx.multi.commands == 0 {
+			return nil
+		}
+		return tx.multi.exec()
+	}, watched...)
+	if r.engine.hasRedisLogger {
+		r.fillLogFields("WATCH", "WATCH "+strings.Join(watched, " "), start, false, err)
+	}
+	if err == redis.TxFailedErr {
+		return false
+	}
+	checkError(err)
+	return true
+}
+
+func (r *RedisCache) WatchWithRetry(maxRetries int, fn func(tx *RedisTx), keys ...string) (committed bool) {
+	for i := 0; i <= maxRetries; i++ {
+		if r.Watch(fn, keys...) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,80 @@
+package beeorm
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRedisTx(t *testing.T) {
+	testRedisTx(t, "")
+}
+
+func TestRedisTxNamespace(t *testing.T) {
+	testRedisTx(t, "test")
+}
+
+func testRedisTx(t *testing.T, namespace string) {
+	registry := &Registry{}
+	registry.RegisterRedis("localhost:6382", namespace, 15)
+	validatedRegistry, def, err := registry.Validate()
+	assert.NoError(t, err)
+	defer def()
+	engine := validatedRegistry.CreateEngine()
+	r := engine.GetRedis()
+	r.FlushDB()
+	testLogger := &testLogHandler{}
+	engine.RegisterQueryLogger(testLogger, false, true, false)
+
+	pipeLine := r.TxPipeline()
+	incr := pipeLine.Incr("tx_counter")
+	pipeLine.Set("tx_value", "a", 0)
+	pipeLine.Exec()
+	assert.Equal(t, int64(1), incr.Result())
+	assert.Len(t, testLogger.Logs, 1)
+	assert.Equal(t, "MULTI EXEC", testLogger.Logs[0]["operation"])
+
+	committed := r.Watch(func(tx *RedisTx) {
+		val, has := tx.Get("tx_value")
+		assert.True(t, has)
+		tx.Multi().Set("tx_value", val+"b", 0)
+	}, "tx_value")
+	assert.True(t, committed)
+	val, _ := r.Get("tx_value")
+	assert.Equal(t, "ab", val)
+
+	committed = r.Watch(func(tx *RedisTx) {
+		tx.Get("tx_value")
+		r.Set("tx_value", "changed", 0)
+		tx.Multi().Set("tx_value", "lost", 0)
+	}, "tx_value")
+	assert.False(t, committed)
+	val, _ = r.Get("tx_value")
+	assert.Equal(t, "changed", val)
+
+	attempts := 0
+	committed = r.WatchWithRetry(3, func(tx *RedisTx) {
+		attempts++
+		current, _ := tx.Get("tx_value")
+		if attempts == 1 {
+			r.Set("tx_value", "changed again", 0)
+		}
+		tx.Multi().Set("tx_value", current+"!", 0)
+	}, "tx_value")
+	assert.True(t, committed)
+	assert.Equal(t, 2, attempts)
+	val, _ = r.Get("tx_value")
+	assert.Equal(t, "changed again!", val)
+
+	assert.False(t, r.WatchWithRetry(1, func(tx *RedisTx) {
+		tx.Get("tx_value")
+		r.Set("tx_value", "x", 0)
+		tx.Multi().Set("tx_value", "y", 0)
+	}, "tx_value"))
+
+	assert.PanicsWithError(t, "nested redis transactions are not supported", func() {
+		r.Watch(func(tx *RedisTx) {
+			tx.Watch(func(tx *RedisTx) {}, "tx_value")
+		}, "tx_value")
+	})
+}
@@ -0,0 +1,95 @@
+package beeorm
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type restoreEntity struct {
+	ORM        `orm:"localCache;redisCache"`
+	ID         uint
+	Name       string       `orm:"index=NameIndex"`
+	IndexName  *CachedQuery `query:":Name = ?"`
+	IndexAll   *CachedQuery `query:""`
+	FakeDelete bool
+}
+
+type restoreDeletedAtEntity struct {
+	ORM       `orm:"localCache;redisCache"`
+	ID        uint
+	Name      string       `orm:"index=NameIndex"`
+	IndexName *CachedQuery `query:":Name = ?"`
+	DeletedAt *time.Time
+}
+
+func TestRestore(t *testing.T) {
+	var entity *restoreEntity
+	var reference *searchEntityReference
+	engine, def := prepareTables(t, &Registry{}, 5, "", "2.0", entity, reference)
+	defer def()
+
+	engine.FlushMany(&restoreEntity{Name: "a"}, &restoreEntity{Name: "a"}, &restoreEntity{Name: "b"})
+	var rows []*restoreEntity
+	assert.Equal(t, 2, engine.CachedSearch(&rows, "IndexName", nil, "a"))
+	assert.Equal(t, 3, engine.CachedSearch(&rows, "IndexAll", nil))
+
+	entity = &restoreEntity{}
+	engine.LoadByID(1, entity)
+	engine.Delete(entity)
+	assert.Equal(t, 1, engine.CachedSearch(&rows, "IndexName", nil, "a"))
+	assert.Equal(t, 2, engine.CachedSearch(&rows, "IndexAll", nil))
+
+	engine.SearchOnlyFakeDeleted(NewWhere("1"), nil, &rows)
+	assert.Len(t, rows, 1)
+	assert.Equal(t, uint(1), rows[0].ID)
+
+	engine.Restore(entity)
+	assert.False(t, entity.FakeDelete)
+	assert.Equal(t, 2, engine.CachedSearch(&rows, "IndexName", nil, "a"))
+	assert.Equal(t, 3, engine.CachedSearch(&rows, "IndexAll", nil))
+	engine.SearchOnlyFakeDeleted(NewWhere("1"), nil, &rows)
+	assert.Len(t, rows, 0)
+	entity = &restoreEntity{}
+	assert.True(t, engine.LoadByID(1, entity))
+	assert.False(t, entity.FakeDelete)
+
+	assert.PanicsWithError(t, "entity 'beeorm.searchEntityReference' has no FakeDelete or DeletedAt field", func() {
+		engine.Restore(&searchEntityReference{})
+	})
+	var references []*searchEntityReference
+	assert.PanicsWithError(t, "entity 'beeorm.searchEntityReference' has no FakeDelete or DeletedAt field", func() {
+		engine.SearchOnlyFakeDeleted(NewWhere("1"), nil, &references)
+	})
+}
+
+func TestRestoreDeletedAt(t *testing.T) {
+	var entity *restoreDeletedAtEntity
+	engine, def := prepareTables(t, &Registry{}, 5, "", "2.0", entity)
+	defer def()
+
+	engine.FlushMany(&restoreDeletedAtEntity{Name: "a"}, &restoreDeletedAtEntity{Name: "a"})
+	var rows []*restoreDeletedAtEntity
+	assert.Equal(t, 2, engine.CachedSearch(&rows, "IndexName", nil, "a"))
+
+	entity = &restoreDeletedAtEntity{}
+	engine.LoadByID(2, entity)
+	engine.Delete(entity)
+	assert.NotNil(t, entity.DeletedAt)
+	assert.Equal(t, 1, engine.CachedSearch(&rows, "IndexName", nil, "a"))
+	engine.Search(NewWhere("1"), nil, &rows)
+	assert.Len(t, rows, 1)
+	engine.SearchWithFakeDeleted(NewWhere("1"), nil, &rows)
+	assert.Len(t, rows, 2)
+	engine.SearchOnlyFakeDeleted(NewWhere("1"), nil, &rows)
+	assert.Len(t, rows, 1)
+	assert.Equal(t, uint(2), rows[0].ID)
+	assert.NotNil(t, rows[0].DeletedAt)
+
+	engine.Restore(entity)
+	assert.Nil(t, entity.DeletedAt)
+	assert.Equal(t, 2, engine.CachedSearch(&rows, "IndexName", nil, "a"))
+	engine.Search(NewWhere("1"), nil, &rows)
+	assert.Len(t, rows, 2)
+}
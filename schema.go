@@ -8,6 +8,7 @@ import (
 	"sort"
 	"strconv"
 	"strings"
+	"time"
 )
 
 type Alter struct {
@@ -71,39 +72,13 @@ func getAlters(engine *Engine) (alters []Alter) {
 			tableSchema := getTableSchema(engine.registry, t)
 			tablesInEntities[tableSchema.mysqlPoolName][tableSchema.tableName] = true
 			has, newAlters := tableSchema.GetSchemaChanges(engine)
-			if tableSchema.hasLog {
-				logPool := engine.GetMysql(tableSchema.logPoolName)
-				var tableDef string
-				hasLogTable := logPool.QueryRow(NewWhere(fmt.Sprintf("SHOW TABLES LIKE '%s'", tableSchema.logTableName)), &tableDef)
-				var logTableSchema string
-				if logPool.GetPoolConfig().GetVersion() == 5 {
-					logTableSchema = fmt.Sprintf("CREATE TABLE `%s`.`%s` (\n  `id` bigint(11) unsigned NOT NULL AUTO_INCREMENT,\n  "+
-						"`entity_id` int(10) unsigned NOT NULL,\n  `added_at` datetime NOT NULL,\n  `meta` json DEFAULT NULL,\n  `before` json DEFAULT NULL,\n  `changes` json DEFAULT NULL,\n  "+
-						"PRIMARY KEY (`id`),\n  KEY `entity_id` (`entity_id`)\n) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 ROW_FORMAT=COMPRESSED KEY_BLOCK_SIZE=8;",
-						logPool.GetPoolConfig().GetDatabase(), tableSchema.logTableName)
-				} else {
-					logTableSchema = fmt.Sprintf("CREATE TABLE `%s`.`%s` (\n  `id` bigint unsigned NOT NULL AUTO_INCREMENT,\n  "+
-						"`entity_id` int unsigned NOT NULL,\n  `added_at` datetime NOT NULL,\n  `meta` json DEFAULT NULL,\n  `before` json DEFAULT NULL,\n  `changes` json DEFAULT NULL,\n  "+
-						"PRIMARY KEY (`id`),\n  KEY `entity_id` (`entity_id`)\n) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_%s ROW_FORMAT=COMPRESSED KEY_BLOCK_SIZE=8;",
-						logPool.GetPoolConfig().GetDatabase(), tableSchema.logTableName, engine.registry.registry.defaultCollate)
-				}
-
-				if !hasLogTable {
-					alters = append(alters, Alter{SQL: logTableSchema, Safe: true, Pool: tableSchema.logPoolName, engine: engine})
-				} else {
-					var skip, createTableDB string
-					logPool.QueryRow(NewWhere(fmt.Sprintf("SHOW CREATE TABLE `%s`", tableSchema.logTableName)), &skip, &createTableDB)
-					createTableDB = strings.Replace(createTableDB, "CREATE TABLE ", fmt.Sprintf("CREATE TABLE `%s`.", logPool.GetPoolConfig().GetDatabase()), 1) + ";"
-					re := regexp.MustCompile(" AUTO_INCREMENT=[0-9]+ ")
-					createTableDB = re.ReplaceAllString(createTableDB, " ")
-					if logTableSchema != createTableDB {
-						isEmpty := isTableEmptyInPool(engine, tableSchema.logPoolName, tableSchema.logTableName)
-						dropTableSQL := fmt.Sprintf("DROP TABLE `%s`.`%s`;", logPool.GetPoolConfig().GetDatabase(), tableSchema.logTableName)
-						alters = append(alters, Alter{SQL: dropTableSQL, Safe: isEmpty, Pool: tableSchema.logPoolName, engine: engine})
-						alters = append(alters, Alter{SQL: logTableSchema, Safe: true, Pool: tableSchema.logPoolName, engine: engine})
-					}
-				}
+			if tableSchema.hasLog && tableSchema.logSinkCode == "" {
+				alters = append(alters, getLogTableAlters(engine, tableSchema, tableSchema.logTableName, tableSchema.logPartitioned)...)
 				tablesInEntities[tableSchema.logPoolName][tableSchema.logTableName] = true
+				if tableSchema.logArchive {
+					alters = append(alters, getLogTableAlters(engine, tableSchema, tableSchema.logArchiveTableName, false)...)
+					tablesInEntities[tableSchema.logPoolName][tableSchema.logArchiveTableName] = true
+				}
 			}
 			if !has {
 				continue
@@ -158,6 +133,61 @@ func getAlters(engine *Engine) (alters []Alter) {
 	return final
 }
 
+func getLogTableAlters(engine *Engine, tableSchema *tableSchema, tableName string, partitioned bool) []Alter {
+	logPool := engine.GetMysql(tableSchema.logPoolName)
+	database := logPool.GetPoolConfig().GetDatabase()
+	primaryKey := "`id`"
+	if partitioned {
+		primaryKey = "`id`,`added_at`"
+	}
+	var tableDef string
+	hasLogTable := logPool.QueryRow(NewWhere(fmt.Sprintf("SHOW TABLES LIKE '%s'", tableName)), &tableDef)
+	var logTableSchema string
+	if logPool.GetPoolConfig().GetVersion() == 5 {
+		logTableSchema = fmt.Sprintf("CREATE TABLE `%s`.`%s` (\n  `id` bigint(11) unsigned NOT NULL AUTO_INCREMENT,\n  "+
+			"`entity_id` int(10) unsigned NOT NULL,\n  `added_at` datetime NOT NULL,\n  `meta` json DEFAULT NULL,\n  `before` json DEFAULT NULL,\n  `changes` json DEFAULT NULL,\n  "+
+			"PRIMARY KEY (%s),\n  KEY `entity_id` (`entity_id`)\n) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 ROW_FORMAT=COMPRESSED KEY_BLOCK_SIZE=8;",
+			database, tableName, primaryKey)
+	} else {
+		logTableSchema = fmt.Sprintf("CREATE TABLE `%s`.`%s` (\n  `id` bigint unsigned NOT NULL AUTO_INCREMENT,\n  "+
+			"`entity_id` int unsigned NOT NULL,\n  `added_at` datetime NOT NULL,\n  `meta` json DEFAULT NULL,\n  `before` json DEFAULT NULL,\n  `changes` json DEFAULT NULL,\n  "+
+			"PRIMARY KEY (%s),\n  KEY `entity_id` (`entity_id`)\n) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_%s ROW_FORMAT=COMPRESSED KEY_BLOCK_SIZE=8;",
+			database, tableName, primaryKey, engine.registry.registry.defaultCollate)
+	}
+	createSQL := logTableSchema
+	if partitioned {
+		createSQL = createSQL[0:len(createSQL)-1] + "\n" + buildLogPartitionsSQL(time.Now()) + ";"
+	}
+	if !hasLogTable {
+		return []Alter{{SQL: createSQL, Safe: true, Pool: tableSchema.logPoolName, engine: engine}}
+	}
+	var skip, createTableDB string
+	logPool.QueryRow(NewWhere(fmt.Sprintf("SHOW CREATE TABLE `%s`", tableName)), &skip, &createTableDB)
+	partitionsPosition := strings.Index(createTableDB, "\n/*!50100 PARTITION BY")
+	hasPartitions := partitionsPosition > 0
+	if hasPartitions {
+		createTableDB = createTableDB[0:partitionsPosition]
+	}
+	createTableDB = strings.Replace(createTableDB, "CREATE TABLE ", fmt.Sprintf("CREATE TABLE `%s`.", database), 1) + ";"
+	re := regexp.MustCompile(" AUTO_INCREMENT=[0-9]+ ")
+	createTableDB = re.ReplaceAllString(createTableDB, " ")
+	if logTableSchema != createTableDB || hasPartitions != partitioned {
+		isEmpty := isTableEmptyInPool(engine, tableSchema.logPoolName, tableName)
+		dropTableSQL := fmt.Sprintf("DROP TABLE `%s`.`%s`;", database, tableName)
+		return []Alter{
+			{SQL: dropTableSQL, Safe: isEmpty, Pool: tableSchema.logPoolName, engine: engine},
+			{SQL: createSQL, Safe: true, Pool: tableSchema.logPoolName, engine: engine},
+		}
+	}
+	if partitioned {
+		rollSQL := buildLogPartitionsRollSQL(logPool, tableName, time.Now())
+		if rollSQL != "" {
+			return []Alter{{SQL: rollSQL, Safe: true, Pool: tableSchema.logPoolName, engine: engine}}
+		}
+	}
+	return nil
+}
+
 func isTableEmptyInPool(engine *Engine, poolName string, tableName string) bool {
 	return isTableEmpty(engine.GetMysql(poolName).client, tableName)
 }
@@ -965,7 +995,7 @@ func checkStruct(tableSchema *tableSchema, engine *Engine, t reflect.Type, index
 			columns = append(columns, fieldColumns...)
 		}
 	}
-	if tableSchema.hasFakeDelete && subField == nil {
+	if tableSchema.hasFakeDelete && !tableSchema.hasDeletedAt && subField == nil {
 		def := fmt.Sprintf("`FakeDelete` %s unsigned NOT NULL DEFAULT '0'", strings.Split(columns[0][1], " ")[1])
 		columns = append(columns, [2]string{"FakeDelete", def})
 	}
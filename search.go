@@ -130,7 +130,7 @@ func searchRow(serializer *serializer, skipFakeDelete bool, engine *Engine, wher
 	schema := orm.tableSchema
 	whereQuery := where.String()
 	if skipFakeDelete && schema.hasFakeDelete {
-		whereQuery = "`FakeDelete` = 0 AND " + whereQuery
+		whereQuery = schema.fakeDeleteWhere + " AND " + whereQuery
 	}
 	/* #nosec */
 	query := "SELECT " + schema.fieldsQuery + " FROM `" + schema.tableName + "` WHERE " + whereQuery + " LIMIT 1"
@@ -164,7 +164,7 @@ func search(serializer *serializer, skipFakeDelete bool, engine *Engine, where *
 	schema := getTableSchema(engine.registry, entityType)
 	whereQuery := where.String()
 	if skipFakeDelete && schema.hasFakeDelete {
-		whereQuery = "`FakeDelete` = 0 AND " + whereQuery
+		whereQuery = schema.fakeDeleteWhere + " AND " + whereQuery
 	}
 	/* #nosec */
 	pageStart := strconv.Itoa((pager.CurrentPage - 1) * pager.PageSize)
@@ -207,7 +207,7 @@ func searchIDs(skipFakeDelete bool, engine *Engine, where *Where, pager *Pager,
 	whereQuery := where.String()
 	if skipFakeDelete && schema.hasFakeDelete {
 		/* #nosec */
-		whereQuery = "`FakeDelete` = 0 AND " + whereQuery
+		whereQuery = schema.fakeDeleteWhere + " AND " + whereQuery
 	}
 	/* #nosec */
 	startPage := strconv.Itoa((pager.CurrentPage - 1) * pager.PageSize)
@@ -0,0 +1,104 @@
+package beeorm
+
+import (
+	"math"
+	"math/rand"
+	"sync"
+	"time"
+)
+
+const stampedeLockPrefix = "_stampede:"
+
+type StampedeProtectionOptions struct {
+	LockPool string
+	LockTTL  time.Duration
+	LockWait time.Duration
+	Beta     float64
+}
+
+type singleFlightCall struct {
+	wg    sync.WaitGroup
+	value interface{}
+	panic interface{}
+}
+
+type singleFlight struct {
+	m     sync.Mutex
+	calls map[string]*singleFlightCall
+}
+
+func (g *singleFlight) do(key string, fn func() interface{}) (value interface{}, leader bool) {
+	g.m.Lock()
+	if g.calls == nil {
+		g.calls = make(map[string]*singleFlightCall)
+	}
+	call, has := g.calls[key]
+	if has {
+		g.m.Unlock()
+		call.wg.Wait()
+		if call.panic != nil {
+			panic(call.panic)
+		}
+		return call.value, false
+	}
+	call = &singleFlightCall{}
+	call.wg.Add(1)
+	g.calls[key] = call
+	g.m.Unlock()
+	defer func() {
+		if rec := recover(); rec != nil {
+			call.panic = rec
+		}
+		g.m.Lock()
+		delete(g.calls, key)
+		g.m.Unlock()
+		call.wg.Done()
+		if call.panic != nil {
+			panic(call.panic)
+		}
+	}()
+	call.value = fn()
+	return call.value, true
+}
+
+func (g *singleFlight) running(key string) bool {
+	g.m.Lock()
+	defer g.m.Unlock()
+	_, has := g.calls[key]
+	return has
+}
+
+// EnableStampedeProtection adds redis locks shared by all processes and early cache refresh,
+// concurrent loads of the same key in one process are always merged into one query
+func (r *Registry) EnableStampedeProtection(options StampedeProtectionOptions) {
+	if options.LockTTL == 0 {
+		options.LockTTL = time.Second * 5
+	}
+	if options.LockWait == 0 {
+		options.LockWait = time.Second * 3
+	}
+	r.stampede = &options
+}
+
+func (e *Engine) obtainStampedeLock(key string, wait bool) (lock *Lock, enabled bool) {
+	options := e.registry.stampede
+	if options == nil || options.LockPool == "" {
+		return nil, false
+	}
+	waitTimeout := options.LockWait
+	if !wait {
+		waitTimeout = 0
+	}
+	lock, _ = e.GetRedis(options.LockPool).GetLocker().Obtain(stampedeLockPrefix+key, options.LockTTL, waitTimeout)
+	return lock, true
+}
+
+func (e *Engine) shouldRecomputeEarly(computeTime time.Duration, expireAt int64) bool {
+	options := e.registry.stampede
+	if options == nil || options.Beta <= 0 || computeTime <= 0 || expireAt == 0 {
+		return false
+	}
+	/* #nosec */
+	gap := float64(computeTime) * options.Beta * -math.Log(1-rand.Float64())
+	return float64(time.Now().UnixNano())+gap >= float64(expireAt)
+}
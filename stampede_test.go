@@ -0,0 +1,115 @@
+package beeorm
+
+import (
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type stampedeEntity struct {
+	ORM  `orm:"localCache;redisCache"`
+	ID   uint
+	Name string
+}
+
+type stampedeQueryCounter struct {
+	selects int32
+}
+
+func (h *stampedeQueryCounter) Handle(log map[string]interface{}) {
+	if strings.HasPrefix(log["query"].(string), "SELECT") {
+		atomic.AddInt32(&h.selects, 1)
+	}
+}
+
+func TestSingleFlight(t *testing.T) {
+	group := &singleFlight{}
+	var calls int32
+	wg := &sync.WaitGroup{}
+	start := make(chan struct{})
+	leaders := int32(0)
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			<-start
+			val, leader := group.do("key", func() interface{} {
+				atomic.AddInt32(&calls, 1)
+				time.Sleep(time.Millisecond * 50)
+				return "value"
+			})
+			if leader {
+				atomic.AddInt32(&leaders, 1)
+			}
+			assert.Equal(t, "value", val)
+		}()
+	}
+	close(start)
+	wg.Wait()
+	assert.Equal(t, int32(1), calls)
+	assert.Equal(t, int32(1), leaders)
+	assert.False(t, group.running("key"))
+
+	assert.PanicsWithValue(t, "error", func() {
+		group.do("key", func() interface{} {
+			panic("error")
+		})
+	})
+	assert.False(t, group.running("key"))
+}
+
+func TestStampedeProtection(t *testing.T) {
+	var entity *stampedeEntity
+	registry := &Registry{}
+	registry.EnableStampedeProtection(StampedeProtectionOptions{LockPool: "default", Beta: 1e12})
+	engine, def := prepareTables(t, registry, 5, "", "2.0", entity)
+	defer def()
+
+	engine.Flush(&stampedeEntity{Name: "John"})
+	engine.GetLocalCache().Clear()
+	engine.GetRedis().FlushDB()
+
+	counter := &stampedeQueryCounter{}
+	wg := &sync.WaitGroup{}
+	start := make(chan struct{})
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			e := engine.GetRegistry().CreateEngine()
+			e.RegisterQueryLogger(counter, true, false, false)
+			<-start
+			loaded := &stampedeEntity{}
+			assert.True(t, e.LoadByID(1, loaded))
+			assert.Equal(t, "John", loaded.Name)
+		}()
+	}
+	close(start)
+	wg.Wait()
+	assert.Equal(t, int32(1), atomic.LoadInt32(&counter.selects))
+	entity = &stampedeEntity{}
+	assert.False(t, engine.LoadByID(2, entity))
+	assert.True(t, engine.LoadByID(1, entity))
+
+	var calls int32
+	provider := func() interface{} {
+		atomic.AddInt32(&calls, 1)
+		return "value"
+	}
+	assert.Equal(t, "value", engine.GetRedis().GetSet("stampede_get_set", 10, provider))
+	assert.Equal(t, "value", engine.GetRedis().GetSet("stampede_get_set", 10, provider))
+	assert.Equal(t, int32(2), calls)
+	assert.Equal(t, "value", engine.GetLocalCache().GetSet("stampede_get_set", time.Second*10, provider))
+	assert.Equal(t, "value", engine.GetLocalCache().GetSet("stampede_get_set", time.Second*10, provider))
+	assert.Equal(t, int32(4), calls)
+
+	registry = &Registry{}
+	registry.RegisterMySQLPool("root:root@tcp(localhost:3311)/test")
+	registry.EnableStampedeProtection(StampedeProtectionOptions{LockPool: "invalid"})
+	_, _, err := registry.Validate()
+	assert.EqualError(t, err, "redis pool 'invalid' not found")
+}
@@ -3,6 +3,7 @@ package beeorm
 import (
 	"crypto/sha256"
 	"database/sql"
+	"encoding/binary"
 	"fmt"
 	"hash/fnv"
 	"math"
@@ -21,8 +22,12 @@ var redisSearchStopWords = []string{}
 
 type CachedQuery struct{}
 
+const defaultCachedQueryMax = 50000
+
 type cachedQueryDefinition struct {
 	Max           int
+	TTL           int
+	Incremental   bool
 	Query         string
 	TrackedFields []string
 	QueryFields   []string
@@ -119,15 +124,32 @@ type tableSchema struct {
 	hasLocalCache           bool
 	redisCacheName          string
 	hasRedisCache           bool
+	localCacheTTL           time.Duration
+	redisCacheTTL           int
+	cacheCounters           *entityCacheCounters
+	compression             string
+	compressionThreshold    int
+	versionedCache          bool
+	cacheLayout             []cacheLayoutField
 	searchCacheName         string
 	hasSearchCache          bool
 	cachePrefix             string
 	structureHash           uint64
+	structureHashPrefix     byte
 	hasFakeDelete           bool
 	hasSearchableFakeDelete bool
+	hasDeletedAt            bool
+	fakeDeleteColumn        string
+	fakeDeleteWhere         string
+	fakeDeleteOnlyWhere     string
 	hasLog                  bool
 	logPoolName             string //name of redis
 	logTableName            string
+	logSinkCode             string
+	logRetention            time.Duration
+	logArchive              bool
+	logArchiveTableName     string
+	logPartitioned          bool
 	skipLogs                []string
 	redisSearchPrefix       string
 	redisSearchPrefixLen    int
@@ -284,6 +306,7 @@ func (tableSchema *tableSchema) GetUsage(registry ValidatedRegistry) map[reflect
 func (tableSchema *tableSchema) init(registry *Registry, entityType reflect.Type) error {
 	tableSchema.t = entityType
 	tableSchema.tags = extractTags(registry, entityType, "")
+	tableSchema.cacheCounters = &entityCacheCounters{}
 	oneRefs := make([]string, 0)
 	manyRefs := make([]string, 0)
 	tableSchema.mapBindToScanPointer = mapBindToScanPointer{}
@@ -308,6 +331,15 @@ func (tableSchema *tableSchema) init(registry *Registry, entityType reflect.Type
 			return fmt.Errorf("redis pool '%s' not found", redisCache)
 		}
 	}
+	localTTL, err := tableSchema.getCacheTTL("localTTL")
+	if err != nil {
+		return err
+	}
+	tableSchema.localCacheTTL = time.Duration(localTTL) * time.Second
+	tableSchema.redisCacheTTL, err = tableSchema.getCacheTTL("redisTTL")
+	if err != nil {
+		return err
+	}
 	cachePrefix := ""
 	if tableSchema.mysqlPoolName != "default" {
 		cachePrefix = tableSchema.mysqlPoolName
@@ -322,6 +354,24 @@ func (tableSchema *tableSchema) init(registry *Registry, entityType reflect.Type
 		tableSchema.hasFakeDelete = true
 		searchable := tableSchema.tags["FakeDelete"] != nil && tableSchema.tags["FakeDelete"]["searchable"] == "true"
 		tableSchema.hasSearchableFakeDelete = searchable
+		tableSchema.fakeDeleteColumn = "FakeDelete"
+		tableSchema.fakeDeleteWhere = "`FakeDelete` = 0"
+		tableSchema.fakeDeleteOnlyWhere = "`FakeDelete` > 0"
+	}
+	deletedAtField, has := entityType.FieldByName("DeletedAt")
+	if has && deletedAtField.Type.String() == "*time.Time" {
+		if tableSchema.hasFakeDelete {
+			return fmt.Errorf("entity %s can't have both FakeDelete and DeletedAt fields", entityType.String())
+		}
+		tableSchema.hasFakeDelete = true
+		tableSchema.hasDeletedAt = true
+		tableSchema.fakeDeleteColumn = "DeletedAt"
+		tableSchema.fakeDeleteWhere = "`DeletedAt` IS NULL"
+		tableSchema.fakeDeleteOnlyWhere = "`DeletedAt` IS NOT NULL"
+		if tableSchema.tags["DeletedAt"] == nil {
+			tableSchema.tags["DeletedAt"] = make(map[string]string)
+		}
+		tableSchema.tags["DeletedAt"]["time"] = "true"
 	}
 	for key, values := range tableSchema.tags {
 		isOne := false
@@ -353,16 +403,16 @@ func (tableSchema *tableSchema) init(registry *Registry, entityType reflect.Type
 				query = strings.Replace(query, variable, fmt.Sprintf("`%s`", fieldName), 1)
 			}
 			if tableSchema.hasFakeDelete && len(variables) > 0 {
-				fields = append(fields, "FakeDelete")
+				fields = append(fields, tableSchema.fakeDeleteColumn)
 			}
 			if query == "" {
 				if tableSchema.hasFakeDelete {
-					query = "`FakeDelete` = 0 ORDER BY `ID`"
+					query = tableSchema.fakeDeleteWhere + " ORDER BY `ID`"
 				} else {
 					query = "1 ORDER BY `ID`"
 				}
 			} else if tableSchema.hasFakeDelete {
-				query = "`FakeDelete` = 0 AND " + query
+				query = tableSchema.fakeDeleteWhere + " AND " + query
 			}
 			queryLower := strings.ToLower(queryOrigin)
 			posOrderBy := strings.Index(queryLower, "order by")
@@ -383,12 +433,32 @@ func (tableSchema *tableSchema) init(registry *Registry, entityType reflect.Type
 				}
 			}
 
+			def := &cachedQueryDefinition{Max: 1, Query: query, TrackedFields: fieldsTracked, QueryFields: fieldsQuery, OrderFields: fieldsOrder}
+			ttl, hasTTL := values["ttl"]
+			if hasTTL {
+				def.TTL, err = strconv.Atoi(ttl)
+				if err != nil || def.TTL <= 0 {
+					return fmt.Errorf("invalid ttl '%s' in %s index %s", ttl, entityType.String(), key)
+				}
+			}
 			if !isOne {
-				def := &cachedQueryDefinition{50000, query, fieldsTracked, fieldsQuery, fieldsOrder}
+				def.Max = defaultCachedQueryMax
+				max, hasMax := values["max"]
+				if hasMax {
+					def.Max, err = strconv.Atoi(max)
+					if err != nil || def.Max <= 0 {
+						return fmt.Errorf("invalid max '%s' in %s index %s", max, entityType.String(), key)
+					}
+				}
+				if posOrderBy == -1 {
+					def.Incremental = queryOrigin == ""
+				} else {
+					order := strings.TrimSpace(queryLower[posOrderBy+8:])
+					def.Incremental = order == ":id" || order == ":id asc"
+				}
 				cachedQueries[key] = def
 				cachedQueriesAll[key] = def
 			} else {
-				def := &cachedQueryDefinition{1, query, fieldsTracked, fieldsQuery, fieldsOrder}
 				cachedQueriesOne[key] = def
 				cachedQueriesAll[key] = def
 			}
@@ -409,8 +479,25 @@ func (tableSchema *tableSchema) init(registry *Registry, entityType reflect.Type
 		}
 	}
 	logPoolName := tableSchema.getTag("log", tableSchema.mysqlPoolName, "")
+	logSinkCode := tableSchema.getTag("logSink", "", "")
 	if logPoolName == "" && registry.forcedEntityLog != "" {
 		logPoolName = registry.forcedEntityLog
+		if logSinkCode == "" {
+			logSinkCode = registry.forcedLogSink
+		}
+	}
+	if logSinkCode != "" {
+		sink, has := registry.logSinks[logSinkCode]
+		if !has {
+			return fmt.Errorf("log sink '%s' not found", logSinkCode)
+		}
+		mysqlSink, isMySQL := sink.(*mySQLLogSink)
+		if isMySQL {
+			logPoolName = mysqlSink.pool
+			logSinkCode = ""
+		} else if logPoolName == "" {
+			logPoolName = tableSchema.mysqlPoolName
+		}
 	}
 	uniqueIndices := make(map[string]map[int]string)
 	uniqueIndicesSimple := make(map[string][]string)
@@ -502,12 +589,26 @@ func (tableSchema *tableSchema) init(registry *Registry, entityType reflect.Type
 		columnMapping[name] = i
 	}
 	tableSchema.idIndex = columnMapping["ID"]
+	tablePrefix := cachePrefix
 	cachePrefix = fmt.Sprintf("%x", sha256.Sum256([]byte(cachePrefix+tableSchema.fieldsQuery)))
 	cachePrefix = cachePrefix[0:5]
 	h := fnv.New32a()
 	_, _ = h.Write([]byte(cachePrefix))
 
 	tableSchema.structureHash = uint64(h.Sum32())
+	// cached values start with hash encoded as uvarint, hash below 128 is encoded
+	// in one byte that could be equal to compression header
+	if tableSchema.structureHash < 0x80 {
+		tableSchema.structureHash += 0x80
+	}
+	hashPrefix := make([]byte, binary.MaxVarintLen64)
+	binary.PutUvarint(hashPrefix, tableSchema.structureHash)
+	tableSchema.structureHashPrefix = hashPrefix[0]
+	tableSchema.versionedCache = tableSchema.getTag("versionedCache", "true", "") == "true"
+	if tableSchema.versionedCache {
+		cachePrefix = fmt.Sprintf("%x", sha256.Sum256([]byte(tablePrefix+":versioned")))[0:5]
+		tableSchema.cacheLayout = tableSchema.fields.buildCacheLayout()
+	}
 	tableSchema.columnMapping = columnMapping
 	tableSchema.cachedIndexes = cachedQueries
 	tableSchema.cachedIndexesOne = cachedQueriesOne
@@ -526,11 +627,30 @@ func (tableSchema *tableSchema) init(registry *Registry, entityType reflect.Type
 	tableSchema.hasLog = logPoolName != ""
 	tableSchema.logPoolName = logPoolName
 	tableSchema.logTableName = fmt.Sprintf("_log_%s_%s", tableSchema.mysqlPoolName, tableSchema.tableName)
+	tableSchema.logSinkCode = logSinkCode
 	tableSchema.skipLogs = skipLogs
+	if err := tableSchema.initLogRetention(); err != nil {
+		return err
+	}
+	if err := tableSchema.initCompression(); err != nil {
+		return err
+	}
 
 	return tableSchema.validateIndexes(uniqueIndices, indices)
 }
 
+func (tableSchema *tableSchema) getCacheTTL(tag string) (int, error) {
+	value := tableSchema.getTag(tag, "", "")
+	if value == "" {
+		return 0, nil
+	}
+	ttl, err := strconv.Atoi(value)
+	if err != nil || ttl <= 0 {
+		return 0, fmt.Errorf("invalid %s '%s' in %s", tag, value, tableSchema.t.String())
+	}
+	return ttl, nil
+}
+
 func (tableSchema *tableSchema) validateIndexes(uniqueIndices map[string]map[int]string, indices map[string]map[int]string) error {
 	all := make(map[string]map[int]string)
 	for k, v := range uniqueIndices {
@@ -602,7 +722,7 @@ func (tableSchema *tableSchema) validateIndexes(uniqueIndices map[string]map[int
 				}
 				valid := 0
 				key := len(columns)
-				if columns[len(columns)] == "FakeDelete" {
+				if columns[len(columns)] == tableSchema.fakeDeleteColumn {
 					key--
 				}
 				for i := len(v.OrderFields); i > 0; i-- {
@@ -907,6 +1027,13 @@ func (tableSchema *tableSchema) buildStringSliceField(attributes schemaFieldAttr
 	tableSchema.mapPointerToValue[columnName] = pointerStringNullableScan
 }
 
+func (tableSchema *tableSchema) isFakeDeletedValue(val interface{}) bool {
+	if tableSchema.hasDeletedAt {
+		return val != nil
+	}
+	return val.(uint64) > 0
+}
+
 func (tableSchema *tableSchema) buildBoolField(attributes schemaFieldAttributes) {
 	columnName := attributes.GetColumnName()
 	if attributes.GetColumnName() == "FakeDelete" {
@@ -1140,7 +1267,7 @@ func (tableSchema *tableSchema) buildRedisSearchIndex(registry *Registry) error
 		}
 		indexQuery += " FROM `" + tableSchema.tableName + "` WHERE `ID` > ?"
 		if tableSchema.hasFakeDelete && !tableSchema.hasSearchableFakeDelete {
-			indexQuery += " AND FakeDelete = 0"
+			indexQuery += " AND " + tableSchema.fakeDeleteWhere
 		}
 		indexQuery += " ORDER BY `ID` LIMIT " + strconv.Itoa(entityIndexerPage)
 		tableSchema.redisSearchIndex.Indexer = func(engine *Engine, lastID uint64, pusher RedisSearchIndexPusher) (newID uint64, hasMore bool) {
@@ -1259,6 +1386,27 @@ func extractTag(registry *Registry, field reflect.StructField) map[string]map[st
 	return make(map[string]map[string]string)
 }
 
+func (tableSchema *tableSchema) getRedisNilTTL() int {
+	if tableSchema.redisCacheTTL > 0 && tableSchema.redisCacheTTL < 60 {
+		return tableSchema.redisCacheTTL
+	}
+	return 60
+}
+
+func (definition *cachedQueryDefinition) getRedisTTL(tableSchema *tableSchema) int {
+	if definition.TTL > 0 {
+		return definition.TTL
+	}
+	return tableSchema.redisCacheTTL
+}
+
+func (definition *cachedQueryDefinition) getLocalTTL(tableSchema *tableSchema) time.Duration {
+	if definition.TTL > 0 {
+		return time.Duration(definition.TTL) * time.Second
+	}
+	return tableSchema.localCacheTTL
+}
+
 func (tableSchema *tableSchema) getCacheKey(id uint64) string {
 	return tableSchema.cachePrefix + ":" + strconv.FormatUint(id, 10)
 }
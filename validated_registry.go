@@ -20,16 +20,24 @@ type ValidatedRegistry interface {
 }
 
 type validatedRegistry struct {
-	registry           *Registry
-	tableSchemas       map[reflect.Type]*tableSchema
-	entities           map[string]reflect.Type
-	redisSearchIndexes map[string]map[string]*RedisSearchIndex
-	localCacheServers  map[string]LocalCachePoolConfig
-	mySQLServers       map[string]MySQLPoolConfig
-	redisServers       map[string]RedisPoolConfig
-	redisStreamGroups  map[string]map[string]map[string]bool
-	redisStreamPools   map[string]string
-	enums              map[string]Enum
+	registry                 *Registry
+	tableSchemas             map[reflect.Type]*tableSchema
+	entities                 map[string]reflect.Type
+	redisSearchIndexes       map[string]map[string]*RedisSearchIndex
+	localCacheServers        map[string]LocalCachePoolConfig
+	mySQLServers             map[string]MySQLPoolConfig
+	redisServers             map[string]RedisPoolConfig
+	redisStreamGroups        map[string]map[string]map[string]bool
+	redisStreamPools         map[string]string
+	redisStreamMaxDeliveries map[string]int64
+	redisScripts             map[string]*redisScript
+	enums                    map[string]Enum
+	logSinks                 map[string]LogSink
+	instanceID               string
+
+	localCacheBusPool  string
+	stampede           *StampedeProtectionOptions
+	singleFlight       *singleFlight
 	timeOffset         int64
 	defaultQueryLogger *defaultLogLogger
 }
@@ -9,14 +9,25 @@ import (
 func (r *Registry) InitByYaml(yaml map[string]interface{}) {
 	for key, data := range yaml {
 		dataAsMap := fixYamlMap(data, "orm")
+		redisOptions := RedisOptions{}
+		value, hasRedisOptions := dataAsMap["redisOptions"]
+		if hasRedisOptions {
+			redisOptions = validateRedisOptions(value, key)
+		}
+		mySQLOptions := MySQLPoolOptions{}
+		if value, has := dataAsMap["mysqlOptions"]; has {
+			mySQLOptions = validateMySQLOptions(value, key)
+		}
 		for dataKey, value := range dataAsMap {
 			switch dataKey {
 			case "mysql":
-				validateOrmMysqlURI(r, value, key)
+				validateOrmMysqlURI(r, value, key, mySQLOptions)
 			case "redis":
-				validateRedisURI(r, value, key)
+				validateRedisURI(r, value, key, redisOptions, hasRedisOptions)
 			case "sentinel":
-				validateSentinel(r, value, key)
+				validateSentinel(r, value, key, redisOptions)
+			case "cluster":
+				validateCluster(r, value, key, redisOptions)
 			case "streams":
 				validateStreams(r, value, key)
 			case "mysqlEncoding":
@@ -30,19 +41,34 @@ func (r *Registry) InitByYaml(yaml map[string]interface{}) {
 					DisableCacheHashCheck()
 				}
 			case "local_cache":
-				number := validateOrmInt(value, key)
-				r.RegisterLocalCache(number, key)
+				switch value.(type) {
+				case map[string]interface{}, map[interface{}]interface{}:
+					r.RegisterLocalCacheWithOptions(validateLocalCacheOptions(value, key), key)
+				default:
+					number := validateOrmInt(value, key)
+					r.RegisterLocalCache(number, key)
+				}
 			}
 		}
 	}
 }
 
-func validateOrmMysqlURI(registry *Registry, value interface{}, key string) {
+func validateOrmMysqlURI(registry *Registry, value interface{}, key string, options MySQLPoolOptions) {
 	asString, ok := value.(string)
 	if !ok {
 		panic(fmt.Errorf("mysql uri '%v' is not valid", value))
 	}
-	registry.RegisterMySQLPool(asString, key)
+	registry.RegisterMySQLPoolWithOptions(asString, options, key)
+}
+
+func validateMySQLOptions(value interface{}, key string) MySQLPoolOptions {
+	options := MySQLPoolOptions{}
+	for name, option := range fixYamlMap(value, key) {
+		if !options.set(name, option) {
+			panic(fmt.Errorf("mysql option '%s' in '%v' is not valid", name, value))
+		}
+	}
+	return options
 }
 
 func validateStreams(registry *Registry, value interface{}, key string) {
@@ -60,11 +86,18 @@ func validateStreams(registry *Registry, value interface{}, key string) {
 	}
 }
 
-func validateRedisURI(registry *Registry, value interface{}, key string) {
+func validateRedisURI(registry *Registry, value interface{}, key string, options RedisOptions, hasOptions bool) {
 	asString, ok := value.(string)
 	if !ok {
 		panic(fmt.Errorf("redis uri '%v' is not valid", value))
 	}
+	if strings.Contains(asString, "://") {
+		if hasOptions {
+			panic(fmt.Errorf("redisOptions in '%s' can't be used with redis url, set them in url query", key))
+		}
+		registry.RegisterRedisURL(asString, key)
+		return
+	}
 	elements := strings.Split(asString, ":")
 	dbNumber := ""
 	uri := ""
@@ -95,10 +128,10 @@ func validateRedisURI(registry *Registry, value interface{}, key string) {
 	if err != nil {
 		panic(fmt.Errorf("redis uri '%v' is not valid", value))
 	}
-	registry.RegisterRedis(uri, namespace, int(db), key)
+	registry.RegisterRedisWithOptions(uri, namespace, int(db), options, key)
 }
 
-func validateSentinel(registry *Registry, value interface{}, key string) {
+func validateSentinel(registry *Registry, value interface{}, key string, options RedisOptions) {
 	def := fixYamlMap(value, key)
 	for master, values := range def {
 		asSlice, ok := values.([]interface{})
@@ -124,10 +157,47 @@ func validateSentinel(registry *Registry, value interface{}, key string) {
 				namespace = elements[2]
 			}
 		}
-		registry.RegisterRedisSentinel(master, namespace, db, asStrings, key)
+		registry.RegisterRedisSentinelWithOptions(master, namespace, db, asStrings, options, key)
 	}
 }
 
+func validateCluster(registry *Registry, value interface{}, key string, options RedisOptions) {
+	asSlice, ok := value.([]interface{})
+	if ok {
+		registry.RegisterRedisClusterWithOptions(yamlClusterAddresses(asSlice, value), "", options, key)
+		return
+	}
+	def := fixYamlMap(value, key)
+	for namespace, values := range def {
+		asSlice, ok = values.([]interface{})
+		if !ok {
+			panic(fmt.Errorf("cluster '%v' is not valid", value))
+		}
+		registry.RegisterRedisClusterWithOptions(yamlClusterAddresses(asSlice, value), namespace, options, key)
+	}
+}
+
+func yamlClusterAddresses(asSlice []interface{}, value interface{}) []string {
+	if len(asSlice) == 0 {
+		panic(fmt.Errorf("cluster '%v' is not valid", value))
+	}
+	asStrings := make([]string, len(asSlice))
+	for i, v := range asSlice {
+		asStrings[i] = fmt.Sprintf("%v", v)
+	}
+	return asStrings
+}
+
+func validateRedisOptions(value interface{}, key string) RedisOptions {
+	options := RedisOptions{}
+	for name, option := range fixYamlMap(value, key) {
+		if !options.set(name, fmt.Sprintf("%v", option)) {
+			panic(fmt.Errorf("redis option '%s' in '%s' is not valid", name, key))
+		}
+	}
+	return options
+}
+
 func fixYamlMap(value interface{}, key string) map[string]interface{} {
 	def, ok := value.(map[string]interface{})
 	if !ok {
@@ -143,6 +213,26 @@ func fixYamlMap(value interface{}, key string) map[string]interface{} {
 	return def
 }
 
+func validateLocalCacheOptions(value interface{}, key string) LocalCacheOptions {
+	def := fixYamlMap(value, key)
+	options := LocalCacheOptions{}
+	for name, v := range def {
+		switch name {
+		case "entries":
+			options.MaxEntries = validateOrmInt(v, key)
+		case "bytes":
+			options.MaxBytes = int64(validateOrmInt(v, key))
+		case "shards":
+			options.Shards = validateOrmInt(v, key)
+		case "policy":
+			options.Policy = LocalCachePolicy(validateOrmString(v, key))
+		default:
+			panic(fmt.Errorf("orm value for %s: %v is not valid", key, name))
+		}
+	}
+	return options
+}
+
 func validateOrmInt(value interface{}, key string) int {
 	asInt, ok := value.(int)
 	if !ok {
@@ -3,6 +3,9 @@ package beeorm
 import (
 	"io/ioutil"
 	"testing"
+	"time"
+
+	"github.com/go-redis/redis/v8"
 
 	"github.com/stretchr/testify/assert"
 	"gopkg.in/yaml.v2"
@@ -24,6 +27,41 @@ func TestYamlLoader(t *testing.T) {
 	assert.Equal(t, "test_namespace", registry.redisPools["default_queue"].GetNamespace())
 
 	assert.Equal(t, "second_namespace", registry.redisPools["third"].GetNamespace())
+	assert.True(t, registry.redisPools["cluster"].IsCluster())
+	assert.False(t, registry.redisPools["third"].IsCluster())
+	assert.Equal(t, "", registry.redisPools["cluster"].GetNamespace())
+	assert.Equal(t, "cluster_namespace", registry.redisPools["cluster_namespace"].GetNamespace())
+	assert.Equal(t, "url_namespace", registry.redisPools["url"].GetNamespace())
+	assert.Equal(t, 3, registry.redisPools["url"].GetDatabase())
+	options := registry.redisPools["url"].getClient().(*redis.Client).Options()
+	assert.Equal(t, "user", options.Username)
+	assert.Equal(t, "secret", options.Password)
+	assert.Equal(t, 20, options.PoolSize)
+	assert.Equal(t, time.Millisecond*500, options.DialTimeout)
+	assert.Equal(t, time.Second*2, options.ReadTimeout)
+	assert.Nil(t, options.TLSConfig)
+	options = registry.redisPools["url_tls"].getClient().(*redis.Client).Options()
+	assert.Equal(t, "localhost:6380", options.Addr)
+	assert.Equal(t, "localhost", options.TLSConfig.ServerName)
+	options = registry.redisPools["secured_sentinel"].getClient().(*redis.Client).Options()
+	assert.Equal(t, 4, options.DB)
+	assert.Equal(t, "app", options.Username)
+	assert.Equal(t, "secret", options.Password)
+	assert.Equal(t, 15, options.PoolSize)
+	assert.NotNil(t, options.TLSConfig)
+	mySQLOptions := registry.mysqlPools["tuned_mysql"].GetOptions()
+	assert.Equal(t, 20, mySQLOptions.MaxOpenConnections)
+	assert.Equal(t, 5, mySQLOptions.MaxIdleConnections)
+	assert.Equal(t, time.Minute*5, mySQLOptions.ConnMaxLifetime)
+	assert.Equal(t, time.Second*30, mySQLOptions.ConnMaxIdleTime)
+	assert.True(t, mySQLOptions.LazyConnect)
+	assert.Equal(t, 5, mySQLOptions.ServerVersion)
+	assert.Equal(t, uint64(2), mySQLOptions.AutoIncrementIncrement)
+	assert.Equal(t, map[string]string{"time_zone": "+00:00", "sql_mode": "STRICT_ALL_TABLES"}, mySQLOptions.SessionVariables)
+	assert.Contains(t, registry.mysqlPools["tuned_mysql"].GetDataSourceURI(), "timeout=2s")
+	assert.Equal(t, 1000, registry.localCachePools["default"].GetLimit())
+	assert.Equal(t, int64(10485760), registry.localCachePools["another"].GetMaxBytes())
+	assert.Equal(t, LocalCachePolicyTinyLFU, registry.localCachePools["another"].GetPolicy())
 
 	assert.Len(t, registry.redisStreamGroups["default"], 2)
 	assert.Len(t, registry.redisStreamGroups["another"], 1)
@@ -56,6 +94,24 @@ func TestYamlLoader(t *testing.T) {
 		NewRegistry().InitByYaml(invalidYaml)
 	})
 
+	invalidYaml = make(map[string]interface{})
+	invalidYaml["default"] = map[string]interface{}{"redis": "redis://localhost:6379/invalid"}
+	assert.PanicsWithError(t, "redis url 'redis://localhost:6379/invalid' is not valid", func() {
+		NewRegistry().InitByYaml(invalidYaml)
+	})
+
+	invalidYaml = make(map[string]interface{})
+	invalidYaml["default"] = map[string]interface{}{"redis": "localhost:6379:0", "redisOptions": map[interface{}]interface{}{"poolSize": "many"}}
+	assert.PanicsWithError(t, "redis option 'poolSize' in 'default' is not valid", func() {
+		NewRegistry().InitByYaml(invalidYaml)
+	})
+
+	invalidYaml = make(map[string]interface{})
+	invalidYaml["default"] = map[string]interface{}{"redis": "redis://localhost:6379/0", "redisOptions": map[interface{}]interface{}{"poolSize": 10}}
+	assert.PanicsWithError(t, "redisOptions in 'default' can't be used with redis url, set them in url query", func() {
+		NewRegistry().InitByYaml(invalidYaml)
+	})
+
 	invalidYaml = make(map[string]interface{})
 	invalidYaml["default"] = map[string]interface{}{"redis": []int{1}}
 	assert.PanicsWithError(t, "redis uri '[1]' is not valid", func() {
@@ -68,6 +124,12 @@ func TestYamlLoader(t *testing.T) {
 		NewRegistry().InitByYaml(invalidYaml)
 	})
 
+	invalidYaml = make(map[string]interface{})
+	invalidYaml["default"] = map[string]interface{}{"local_cache": map[interface{}]interface{}{"size": 10}}
+	assert.PanicsWithError(t, "orm value for default: size is not valid", func() {
+		NewRegistry().InitByYaml(invalidYaml)
+	})
+
 	invalidYaml = make(map[string]interface{})
 	invalidYaml["default"] = map[string]interface{}{"streams": map[interface{}]interface{}{"test": "wrong"}}
 	assert.PanicsWithError(t, "streams 'wrong' is not valid", func() {
@@ -86,6 +148,24 @@ func TestYamlLoader(t *testing.T) {
 		NewRegistry().InitByYaml(invalidYaml)
 	})
 
+	invalidYaml = make(map[string]interface{})
+	invalidYaml["default"] = map[string]interface{}{"cluster": map[interface{}]interface{}{"test": "wrong"}}
+	assert.PanicsWithError(t, "cluster 'map[test:wrong]' is not valid", func() {
+		NewRegistry().InitByYaml(invalidYaml)
+	})
+
+	invalidYaml = make(map[string]interface{})
+	invalidYaml["default"] = map[string]interface{}{"cluster": []interface{}{}}
+	assert.PanicsWithError(t, "cluster '[]' is not valid", func() {
+		NewRegistry().InitByYaml(invalidYaml)
+	})
+
+	invalidYaml = make(map[string]interface{})
+	invalidYaml["default"] = map[string]interface{}{"mysql": "root:root@tcp(localhost:3308)/test", "mysqlOptions": map[interface{}]interface{}{"lazyConnect": "maybe"}}
+	assert.PanicsWithError(t, "mysql option 'lazyConnect' in 'map[lazyConnect:maybe]' is not valid", func() {
+		NewRegistry().InitByYaml(invalidYaml)
+	})
+
 	invalidYaml = make(map[string]interface{})
 	invalidYaml["default"] = map[string]interface{}{"mysqlEncoding": 23}
 	assert.PanicsWithError(t, "orm value for default: 23 is not valid", func() {